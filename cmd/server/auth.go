@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"pullrequests/internal/adapters/oidc"
+	"pullrequests/internal/config"
+	"pullrequests/internal/domain"
+	"time"
+)
+
+// newTokenVerifier returns nil when no JWKS source is configured.
+func newTokenVerifier(cfg *config.Config) (domain.TokenVerifier, error) {
+	var keys *oidc.JWKS
+	switch {
+	case cfg.JWT.JWKSFile != "":
+		loaded, err := oidc.LoadJWKSFile(cfg.JWT.JWKSFile)
+		if err != nil {
+			return nil, err
+		}
+		keys = loaded
+	case cfg.JWT.JWKSURL != "":
+		keys = oidc.NewRemoteJWKS(cfg.JWT.JWKSURL, &http.Client{Timeout: 10 * time.Second}, cfg.JWT.JWKSRefresh)
+	default:
+		return nil, nil
+	}
+
+	// Without them a token minted for any other application trusted by the
+	// same identity provider would be accepted.
+	if cfg.JWT.Issuer == "" || cfg.JWT.Audience == "" {
+		return nil, fmt.Errorf("JWT_ISSUER and JWT_AUDIENCE are required when JWT authentication is enabled")
+	}
+
+	groupRoles := make(map[string]domain.APIRole, len(cfg.JWT.GroupRoles))
+	for group, name := range cfg.JWT.GroupRoles {
+		role := domain.APIRole(name)
+		if !role.IsValid() {
+			return nil, fmt.Errorf("invalid role %q for group %q", name, group)
+		}
+		groupRoles[group] = role
+	}
+
+	defaultRole := domain.APIRole(cfg.JWT.DefaultRole)
+	if !defaultRole.IsValid() {
+		return nil, fmt.Errorf("invalid default role %q", cfg.JWT.DefaultRole)
+	}
+
+	return oidc.NewVerifier(keys, oidc.VerifierConfig{
+		Issuer:      cfg.JWT.Issuer,
+		Audience:    cfg.JWT.Audience,
+		UserClaim:   cfg.JWT.UserClaim,
+		GroupsClaim: cfg.JWT.GroupsClaim,
+		GroupRoles:  groupRoles,
+		DefaultRole: defaultRole,
+	}), nil
+}
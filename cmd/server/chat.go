@@ -0,0 +1,32 @@
+package main
+
+import (
+	"net/http"
+	"os"
+	"pullrequests/internal/adapters/chat"
+	"pullrequests/internal/config"
+	"pullrequests/internal/usecases"
+)
+
+// newChatNotifier returns nil when no chat webhook is configured.
+func newChatNotifier(cfg *config.Config, repos repositories, retry usecases.RetryPolicy) (*usecases.ChatNotifier, error) {
+	if cfg.Chat.WebhookURL == "" {
+		return nil, nil
+	}
+
+	var overrides string
+	if cfg.Chat.TemplatesFile != "" {
+		content, err := os.ReadFile(cfg.Chat.TemplatesFile)
+		if err != nil {
+			return nil, err
+		}
+		overrides = string(content)
+	}
+	templates, err := usecases.NewChatTemplates(overrides)
+	if err != nil {
+		return nil, err
+	}
+
+	sender := chat.NewIncomingWebhookSender(cfg.Chat.WebhookURL, cfg.Chat.Username, &http.Client{Timeout: cfg.Chat.Timeout})
+	return usecases.NewChatNotifier(repos.notification, repos.user, repos.pullrequest, repos.job, repos.trm, retry, sender, templates, cfg.Chat.TeamChannels), nil
+}
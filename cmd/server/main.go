@@ -2,65 +2,256 @@ package main
 
 import (
 	"context"
-	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
-	"pullrequests/internal/adapters/postgres"
+	"pullrequests/internal/adapters/email"
+	"pullrequests/internal/adapters/github"
+	"pullrequests/internal/adapters/gitlab"
+	"pullrequests/internal/adapters/webhook"
 	"pullrequests/internal/config"
+	"pullrequests/internal/domain"
 	"pullrequests/internal/handlers"
+	"pullrequests/internal/logging"
+	"pullrequests/internal/metrics"
 	"pullrequests/internal/usecases"
 	"syscall"
 	"time"
 
 	"github.com/go-chi/chi/v5"
-	"github.com/jmoiron/sqlx"
 	_ "github.com/lib/pq"
 )
 
 func main() {
 	cfg := config.LoadConfig()
+	slog.SetDefault(logging.New(os.Stdout, logging.ParseLevel(cfg.Log.Level)))
 
-	db, err := sqlx.Open("postgres", cfg.GetConnectionString())
+	store, err := openStorage(cfg)
 	if err != nil {
-		log.Fatalf("failed to connect db: %v", err)
+		fatal("failed to open storage", err)
 	}
-	defer db.Close()
-	if err := db.Ping(); err != nil {
-		log.Fatalf("db unreachable: %v", err)
+	defer store.Close()
+
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrateCommand(context.Background(), store.runner, os.Args[2:]); err != nil {
+			fatal("migrate failed", err)
+		}
+		return
+	}
+
+	strategy, err := usecases.NewReviewerStrategy(cfg.Assignment.Strategy, store.repos.history)
+	if err != nil {
+		fatal("invalid REVIEWER_STRATEGY", err)
+	}
+
+	if cfg.Migrations.OnStart && store.runner != nil {
+		applied, err := store.runner.Up(context.Background())
+		if err != nil {
+			fatal("failed to apply migrations", err)
+		}
+		for _, migration := range applied {
+			slog.Info("applied migration", "version", migration.Version, "name", migration.Name)
+		}
+	}
+	repos := store.repos
+
+	shutdownTracing, err := setupTracing(context.Background(), cfg)
+	if err != nil {
+		fatal("failed to configure tracing", err)
 	}
 
-	teamRepo := postgres.NewTeamRepo(db)
-	userRepo := postgres.NewUserRepo(db)
-	pullrequestRepo := postgres.NewPRRepo(db)
-	trm := postgres.NewSQLTransactionManager(db)
+	teamUsecase := usecases.NewTeamUsecase(repos.team, repos.user, repos.trm)
+	webhookSender := webhook.NewHTTPSender(&http.Client{Timeout: cfg.Webhooks.Timeout})
+	webhookUsecase := usecases.NewWebhookUsecase(repos.webhook, webhookSender, repos.trm, usecases.RetryPolicy{
+		MaxAttempts: cfg.Webhooks.MaxAttempts,
+		BaseDelay:   cfg.Webhooks.RetryBase,
+		MaxDelay:    cfg.Webhooks.RetryMax,
+	})
+
+	jobRetry := usecases.RetryPolicy{
+		MaxAttempts: cfg.Jobs.MaxAttempts,
+		BaseDelay:   cfg.Jobs.RetryBase,
+		MaxDelay:    cfg.Jobs.RetryMax,
+	}
+
+	eventBus := usecases.NewEventBus()
+	var reviewerSync *usecases.ReviewerSyncUsecase
+	if cfg.Integrations.GitHubToken != "" {
+		githubClient := github.NewClient(cfg.Integrations.GitHubAPIURL, cfg.Integrations.GitHubToken, &http.Client{Timeout: 10 * time.Second})
+		reviewerSync = usecases.NewReviewerSyncUsecase(repos.identity, repos.job, repos.trm, jobRetry, githubClient)
+		eventBus.Subscribe(reviewerSync.HandleEvent, domain.EventReviewerAssigned, domain.EventReviewerReassigned)
+	}
+	chatNotifier, err := newChatNotifier(cfg, repos, jobRetry)
+	if err != nil {
+		fatal("failed to configure chat notifications", err)
+	}
+	if chatNotifier != nil {
+		eventBus.Subscribe(chatNotifier.HandleEvent, usecases.ChatEvents...)
+	}
+	sinks := []domain.EventSink{webhookUsecase, eventBus}
+	if cfg.Outbox.LogEvents {
+		sinks = append(sinks, usecases.LogSink{})
+	}
+	publisher := usecases.NewOutboxPublisher(repos.outbox)
+	dispatcher := usecases.NewOutboxDispatcher(repos.outbox, repos.trm, usecases.RetryPolicy{
+		MaxAttempts: cfg.Outbox.MaxAttempts,
+		BaseDelay:   time.Second,
+		MaxDelay:    5 * time.Minute,
+	}, sinks...)
+
+	userUsecase := usecases.NewUserUsecase(repos.user, publisher, repos.trm)
+	pullrequestUsecase := usecases.NewPRUsecase(repos.pullrequest, repos.user, repos.history, strategy, publisher, metrics.Recorder{}, repos.trm)
+	notificationUsecase := usecases.NewNotificationUsecase(repos.notification, repos.user, repos.trm)
+	slaUsecase := usecases.NewReviewSLAUsecase(repos.sla, repos.team, repos.user, repos.pullrequest, pullrequestUsecase, publisher, metrics.Recorder{}, domain.ReviewSLA{
+		RemindAfter:   cfg.ReviewSLA.RemindAfter,
+		EscalateAfter: cfg.ReviewSLA.EscalateAfter,
+	}, repos.trm)
+	integrationUsecase := usecases.NewIntegrationUsecase(repos.identity, pullrequestUsecase, repos.trm)
+	statsUsecase := usecases.NewStatsUsecase(repos.team, repos.pullrequest, repos.trm)
+	idempotencyUsecase := usecases.NewIdempotencyUsecase(repos.idempotency, repos.trm, cfg.Idempotency.TTL)
+	healthUsecase := usecases.NewHealthUsecase(cfg.Server.ReadinessTimeout, store.readinessChecks()...)
+
+	verifier, err := newTokenVerifier(cfg)
+	if err != nil {
+		fatal("failed to configure jwt verifier", err)
+	}
+	authUsecase := usecases.NewAuthUsecase(repos.token, repos.user, verifier, repos.trm)
 
-	teamUsecase := usecases.NewTeamUsecase(teamRepo, userRepo, trm)
-	userUsecase := usecases.NewUserUsecase(userRepo, trm)
-	pullrequestUsecase := usecases.NewPRUsecase(pullrequestRepo, userRepo, trm)
+	if cfg.Auth.AdminToken != "" {
+		if err := authUsecase.EnsureToken(context.Background(), "bootstrap-admin", cfg.Auth.AdminToken, domain.APIRoleAdmin); err != nil {
+			fatal("failed to register admin token", err)
+		}
+	}
 
 	teamHandler := handlers.NewTeamHandler(teamUsecase)
 	userHandler := handlers.NewUserHandler(userUsecase)
 	pullRequestHandler := handlers.NewPRHandler(pullrequestUsecase)
+	statsHandler := handlers.NewStatsHandler(statsUsecase)
+	idempotencyHandler := handlers.NewIdempotencyHandler(idempotencyUsecase)
+	healthHandler := handlers.NewHealthHandler(healthUsecase)
+	authHandler := handlers.NewAuthHandler(authUsecase, cfg.Auth.Enabled)
+	webhookHandler := handlers.NewWebhookHandler(webhookUsecase)
+	notificationHandler := handlers.NewNotificationHandler(notificationUsecase)
+	slaHandler := handlers.NewReviewSLAHandler(slaUsecase)
+	integrationHandler := handlers.NewIntegrationHandler(integrationUsecase)
+	githubHandler := handlers.NewGitHubHandler(integrationUsecase, cfg.Integrations.GitHubSecret)
+	gitlabClient := gitlab.NewClient(cfg.Integrations.GitLabAPIURL, cfg.Integrations.GitLabAPIToken, &http.Client{Timeout: 10 * time.Second})
+	gitlabHandler := handlers.NewGitLabHandler(integrationUsecase, cfg.Integrations.GitLabToken, gitlabClient)
+
+	admins := authHandler.RequireRoles(domain.APIRoleAdmin)
+	writers := authHandler.RequireRoles(domain.APIRoleAdmin, domain.APIRoleTeamLead, domain.APIRoleUser)
+	readers := authHandler.RequireRoles(domain.APIRoleAdmin, domain.APIRoleTeamLead, domain.APIRoleUser, domain.APIRoleReadOnly)
+
+	metrics.RegisterOpenPullRequests(repos.pullrequest.CountOpenByTeam)
 
 	r := chi.NewRouter()
+	r.Use(handlers.RequestIDMiddleware)
+	r.Use(handlers.TracingMiddleware)
+	r.Use(handlers.MetricsMiddleware)
+	r.Use(handlers.AccessLogMiddleware)
+	r.Handle("/metrics", metrics.Handler())
+	r.Get("/healthz", healthHandler.Liveness)
+	r.Get("/readyz", healthHandler.Readiness)
 
-	r.Route("/team", func(r chi.Router) {
-		r.Post("/add", teamHandler.AddTeam)
-		r.Get("/get", teamHandler.GetTeam)
-	})
+	r.Group(func(r chi.Router) {
+		r.Use(authHandler.Middleware)
 
-	r.Route("/users", func(r chi.Router) {
-		r.Post("/setIsActive", userHandler.SetUserActive)
-		r.Get("/getReview", pullRequestHandler.GetUserReviewPRs)
-	})
+		// Responses of /auth and /webhooks carry secrets, so they are never
+		// stored for idempotent replay.
+		r.Route("/auth", func(r chi.Router) {
+			r.Use(admins)
+			r.Post("/tokens", authHandler.CreateToken)
+			r.Get("/tokens", authHandler.GetTokens)
+			r.Post("/tokens/revoke", authHandler.RevokeToken)
+		})
+
+		r.Route("/webhooks", func(r chi.Router) {
+			r.Use(admins)
+			r.Post("/subscriptions", webhookHandler.CreateSubscription)
+			r.Get("/subscriptions", webhookHandler.GetSubscriptions)
+			r.Post("/subscriptions/delete", webhookHandler.DeleteSubscription)
+			r.Get("/deliveries", webhookHandler.GetDeliveries)
+		})
+
+		r.Group(func(r chi.Router) {
+			r.Use(idempotencyHandler.Middleware)
+
+			r.Route("/team", func(r chi.Router) {
+				r.With(admins).Post("/add", teamHandler.AddTeam)
+				r.With(readers).Get("/get", teamHandler.GetTeam)
+				r.With(readers).Get("/sla", slaHandler.GetSLA)
+				r.With(writers).Post("/sla", slaHandler.SetSLA)
+			})
+
+			r.Route("/users", func(r chi.Router) {
+				r.With(writers).Post("/setIsActive", userHandler.SetUserActive)
+				r.With(readers).Get("/getReview", pullRequestHandler.GetUserReviewPRs)
+				r.With(readers).Get("/notifications", notificationHandler.GetPreferences)
+				r.With(writers).Post("/notifications", notificationHandler.SetPreferences)
+			})
+
+			r.Route("/pullRequest", func(r chi.Router) {
+				r.With(writers).Post("/create", pullRequestHandler.CreatePR)
+				r.With(readers).Post("/previewReviewers", pullRequestHandler.PreviewReviewers)
+				r.With(writers).Post("/merge", pullRequestHandler.MergePR)
+				r.With(writers).Post("/reassign", pullRequestHandler.ReassignReviewer)
+			})
 
-	r.Route("/pullRequest", func(r chi.Router) {
-		r.Post("/create", pullRequestHandler.CreatePR)
-		r.Post("/merge", pullRequestHandler.MergePR)
-		r.Post("/reassign", pullRequestHandler.ReassignReviewer)
+			r.Route("/stats", func(r chi.Router) {
+				r.With(readers).Get("/reviewers", statsHandler.GetReviewerStats)
+			})
+
+			r.Route("/integrations/identities", func(r chi.Router) {
+				r.Use(admins)
+				r.Post("/", integrationHandler.SetIdentity)
+				r.Get("/", integrationHandler.GetIdentities)
+				r.Post("/delete", integrationHandler.DeleteIdentity)
+			})
+		})
 	})
 
+	// Provider webhooks authenticate with a shared secret instead of API tokens.
+	if cfg.Integrations.GitHubSecret != "" {
+		r.With(
+			githubHandler.VerifySignature,
+			idempotencyHandler.KeyFromHeader(handlers.GitHubDeliveryHeader),
+			idempotencyHandler.Middleware,
+		).Post("/integrations/github/webhook", githubHandler.Webhook)
+	}
+	if cfg.Integrations.GitLabToken != "" {
+		r.With(
+			gitlabHandler.VerifyToken,
+			idempotencyHandler.KeyFromHeader(handlers.IdempotencyKeyHeader, handlers.GitLabEventUUIDHeader),
+			idempotencyHandler.Middleware,
+		).Post("/integrations/gitlab/webhook", gitlabHandler.Webhook)
+	}
+
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+	go runWorker(workerCtx, "outbox dispatcher", cfg.Outbox.PollInterval, dispatcher.Dispatch)
+	go runWorker(workerCtx, "webhook dispatcher", cfg.Webhooks.PollInterval, webhookUsecase.DeliverDue)
+	if reviewerSync != nil {
+		go runWorker(workerCtx, "reviewer sync", cfg.Jobs.PollInterval, reviewerSync.SyncDue)
+	}
+	if chatNotifier != nil {
+		go runWorker(workerCtx, "chat notifications", cfg.Jobs.PollInterval, chatNotifier.SendDue)
+	}
+	go runWorker(workerCtx, "review sla", cfg.ReviewSLA.PollInterval, leaderOnly(store.leader, slaUsecase.Run))
+	if cfg.SMTP.Host != "" {
+		digestSender := email.NewSMTPSender(email.SMTPConfig{
+			Host:     cfg.SMTP.Host,
+			Port:     cfg.SMTP.Port,
+			Username: cfg.SMTP.Username,
+			Password: cfg.SMTP.Password,
+			From:     cfg.SMTP.From,
+			StartTLS: cfg.SMTP.StartTLS,
+			Timeout:  cfg.SMTP.Timeout,
+		})
+		digestUsecase := usecases.NewDigestUsecase(repos.notification, repos.pullrequest, repos.user, digestSender, repos.trm)
+		go runWorker(workerCtx, "email digest", cfg.Digest.PollInterval, digestUsecase.SendDue)
+	}
+
 	addr := ":" + cfg.Server.Port
 	srv := &http.Server{
 		Addr:    addr,
@@ -68,9 +259,9 @@ func main() {
 	}
 
 	go func() {
-		log.Printf("server started on %s", addr)
+		slog.Info("server started", "addr", addr)
 		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("listen: %v", err)
+			fatal("listen failed", err)
 		}
 	}()
 
@@ -78,15 +269,31 @@ func main() {
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 
-	log.Println("Shutdown Server ...")
+	slog.Info("shutting down server")
+	healthUsecase.StartDraining()
+	slog.Info("draining before shutdown", "delay", cfg.Server.DrainDelay.String())
+	time.Sleep(cfg.Server.DrainDelay)
+	stopWorkers()
+	if err := store.leader.Release(context.Background()); err != nil {
+		slog.Error("failed to release leadership", "error", err)
+	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
 	if err := srv.Shutdown(ctx); err != nil {
-		log.Fatalf("Server Shutdown: %v", err)
+		fatal("server shutdown failed", err)
+	}
+	if err := shutdownTracing(ctx); err != nil {
+		slog.Error("failed to flush traces", "error", err)
 	}
 
 	<-ctx.Done()
-	log.Println("Shutdown timeout reached")
+	slog.Info("shutdown timeout reached")
+}
+
+// fatal logs err and exits, like log.Fatal.
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
 }
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"pullrequests/internal/adapters/memory"
+	"pullrequests/internal/adapters/postgres"
+	"pullrequests/internal/adapters/sqlite"
+	"pullrequests/internal/adapters/sqlstore"
+	"pullrequests/internal/config"
+	"pullrequests/internal/domain"
+	"pullrequests/internal/metrics"
+	"pullrequests/internal/migrate"
+	"pullrequests/internal/usecases"
+	"pullrequests/migrations"
+	"sync/atomic"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type repositories struct {
+	team         domain.TeamRepo
+	user         domain.UserRepo
+	pullrequest  domain.PullRequestRepo
+	history      domain.ReviewHistoryRepo
+	idempotency  domain.IdempotencyRepo
+	token        domain.APITokenRepo
+	webhook      domain.WebhookRepo
+	outbox       domain.OutboxRepo
+	job          domain.JobRepo
+	identity     domain.IdentityRepo
+	notification domain.NotificationRepo
+	sla          domain.ReviewSLARepo
+	trm          domain.TransactionManager
+}
+
+type storage struct {
+	repos  repositories
+	db     *sqlx.DB
+	runner *migrate.Runner
+	leader domain.LeaderElector
+}
+
+func openStorage(cfg *config.Config) (*storage, error) {
+	switch cfg.Storage {
+	case config.StorageMemory:
+		return &storage{repos: newMemoryRepositories(), leader: localLeader{}}, nil
+	case config.StorageSQLite:
+		db, err := sqlite.Open(cfg.SQLite.Path)
+		if err != nil {
+			return nil, err
+		}
+		runner, err := newMigrationRunner(db, migrate.SQLite, migrations.SQLite, "sqlite")
+		if err != nil {
+			db.Close()
+			return nil, err
+		}
+		return &storage{repos: newSQLRepositories(db, sqlite.Dialect, sqlite.NewSQLTransactionManager(db, metrics.Recorder{})), db: db, runner: runner, leader: localLeader{}}, nil
+	default:
+		db, err := sqlx.Open("postgres", cfg.GetConnectionString())
+		if err != nil {
+			return nil, err
+		}
+		if err := db.Ping(); err != nil {
+			db.Close()
+			return nil, err
+		}
+		runner, err := newMigrationRunner(db, migrate.Postgres, migrations.Postgres, "postgres")
+		if err != nil {
+			db.Close()
+			return nil, err
+		}
+		return &storage{
+			repos:  newSQLRepositories(db, postgres.Dialect, postgres.NewSQLTransactionManager(db, metrics.Recorder{})),
+			db:     db,
+			runner: runner,
+			leader: postgres.NewAdvisoryLeader(db, cfg.ReviewSLA.LockKey),
+		}, nil
+	}
+}
+
+func (s *storage) Close() error {
+	if s.db == nil {
+		return nil
+	}
+	return s.db.Close()
+}
+
+// readinessChecks returns the database checks; the memory storage has none.
+func (s *storage) readinessChecks() []usecases.HealthCheck {
+	var checks []usecases.HealthCheck
+	if s.db != nil {
+		checks = append(checks, usecases.HealthCheck{Name: "database", Check: s.db.PingContext})
+	}
+	if s.runner != nil {
+		checks = append(checks, usecases.HealthCheck{Name: "migrations", Check: migrationsApplied(s.runner)})
+	}
+	return checks
+}
+
+// migrationsApplied stops querying once every migration has been seen
+// applied, so probes do not keep reading schema_migrations.
+func migrationsApplied(runner *migrate.Runner) func(context.Context) error {
+	var applied atomic.Bool
+	return func(ctx context.Context) error {
+		if applied.Load() {
+			return nil
+		}
+		pending, err := runner.Pending(ctx)
+		if err != nil {
+			return err
+		}
+		if pending > 0 {
+			return fmt.Errorf("%d pending migrations", pending)
+		}
+		applied.Store(true)
+		return nil
+	}
+}
+
+// localLeader is used by storages meant for a single replica.
+type localLeader struct{}
+
+func (localLeader) IsLeader(ctx context.Context) (bool, error) {
+	return true, nil
+}
+
+func (localLeader) Release(ctx context.Context) error {
+	return nil
+}
+
+func newMigrationRunner(db *sqlx.DB, dialect migrate.Dialect, fsys fs.FS, dir string) (*migrate.Runner, error) {
+	sub, err := fs.Sub(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+	loaded, err := migrate.Load(sub)
+	if err != nil {
+		return nil, err
+	}
+	return migrate.NewRunner(db, dialect, loaded), nil
+}
+
+func newSQLRepositories(db *sqlx.DB, dialect sqlstore.Dialect, trm domain.TransactionManager) repositories {
+	return repositories{
+		team:         sqlstore.NewTeamRepo(db, dialect),
+		user:         sqlstore.NewUserRepo(db, dialect),
+		pullrequest:  sqlstore.NewPRRepo(db, dialect),
+		history:      sqlstore.NewReviewHistoryRepo(db, dialect),
+		idempotency:  sqlstore.NewIdempotencyRepo(db, dialect),
+		token:        sqlstore.NewAPITokenRepo(db, dialect),
+		webhook:      sqlstore.NewWebhookRepo(db, dialect),
+		outbox:       sqlstore.NewOutboxRepo(db, dialect),
+		job:          sqlstore.NewJobRepo(db, dialect),
+		identity:     sqlstore.NewIdentityRepo(db, dialect),
+		notification: sqlstore.NewNotificationRepo(db, dialect),
+		sla:          sqlstore.NewReviewSLARepo(db, dialect),
+		trm:          trm,
+	}
+}
+
+func newMemoryRepositories() repositories {
+	store := memory.NewStore()
+	return repositories{
+		team:         memory.NewTeamRepo(store),
+		user:         memory.NewUserRepo(store),
+		pullrequest:  memory.NewPRRepo(store),
+		history:      memory.NewReviewHistoryRepo(store),
+		idempotency:  memory.NewIdempotencyRepo(store),
+		token:        memory.NewAPITokenRepo(store),
+		webhook:      memory.NewWebhookRepo(store),
+		outbox:       memory.NewOutboxRepo(store),
+		job:          memory.NewJobRepo(store),
+		identity:     memory.NewIdentityRepo(store),
+		notification: memory.NewNotificationRepo(store),
+		sla:          memory.NewReviewSLARepo(store),
+		trm:          memory.NewTransactionManager(store),
+	}
+}
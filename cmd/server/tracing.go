@@ -0,0 +1,34 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"pullrequests/internal/config"
+	"pullrequests/internal/tracing"
+
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// setupTracing returns a no-op shutdown when tracing is disabled. The OTLP
+// exporter reads its endpoint and headers from the standard
+// OTEL_EXPORTER_OTLP_* variables.
+func setupTracing(ctx context.Context, cfg *config.Config) (func(context.Context) error, error) {
+	var exporter sdktrace.SpanExporter
+	var err error
+	switch cfg.Tracing.Exporter {
+	case "", "none":
+		return func(context.Context) error { return nil }, nil
+	case "otlp":
+		exporter, err = otlptracehttp.New(ctx)
+	case "stdout", "console":
+		exporter, err = stdouttrace.New()
+	default:
+		return nil, fmt.Errorf("unsupported OTEL_TRACES_EXPORTER %q", cfg.Tracing.Exporter)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return tracing.Setup(cfg.Tracing.ServiceName, exporter)
+}
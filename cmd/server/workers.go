@@ -0,0 +1,42 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"pullrequests/internal/domain"
+	"time"
+)
+
+// runWorker calls fn every interval until ctx is cancelled. When fn made
+// progress it is called again immediately to drain the backlog.
+func runWorker(ctx context.Context, name string, interval time.Duration, fn func(context.Context) (int, error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		processed, err := fn(ctx)
+		if err != nil && ctx.Err() == nil {
+			slog.ErrorContext(ctx, "worker failed", "worker", name, "error", err)
+		}
+		if processed > 0 && err == nil {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// leaderOnly wraps fn so that it only runs on the replica holding leadership.
+func leaderOnly(leader domain.LeaderElector, fn func(context.Context) (int, error)) func(context.Context) (int, error) {
+	return func(ctx context.Context) (int, error) {
+		isLeader, err := leader.IsLeader(ctx)
+		if err != nil || !isLeader {
+			return 0, err
+		}
+		return fn(ctx)
+	}
+}
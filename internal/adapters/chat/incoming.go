@@ -0,0 +1,60 @@
+package chat
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"pullrequests/internal/domain"
+	"strings"
+)
+
+// IncomingWebhookSender posts messages in the incoming-webhook format shared by
+// Slack and Mattermost. Mattermost honours the channel override for channels
+// and "@user" direct messages; Slack app webhooks post to their fixed channel.
+type IncomingWebhookSender struct {
+	url      string
+	username string
+	client   *http.Client
+}
+
+func NewIncomingWebhookSender(url, username string, client *http.Client) *IncomingWebhookSender {
+	return &IncomingWebhookSender{url: url, username: username, client: client}
+}
+
+type incomingWebhookPayload struct {
+	Text     string `json:"text"`
+	Channel  string `json:"channel,omitempty"`
+	Username string `json:"username,omitempty"`
+}
+
+func (s *IncomingWebhookSender) Send(ctx context.Context, message *domain.ChatMessage) error {
+	body, err := json.Marshal(incomingWebhookPayload{
+		Text:     message.Text,
+		Channel:  message.Channel,
+		Username: s.username,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	response, _ := io.ReadAll(io.LimitReader(resp.Body, 4<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("chat webhook responded with status %d: %s", resp.StatusCode, strings.TrimSpace(string(response)))
+	}
+	return nil
+}
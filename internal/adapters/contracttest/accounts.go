@@ -0,0 +1,248 @@
+package contracttest
+
+import (
+	"context"
+	"pullrequests/internal/domain"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+)
+
+func testIdempotency(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+	record := &domain.IdempotencyRecord{
+		ActorID:     "token:t1",
+		Key:         "key-1",
+		Route:       "POST /pullRequest/create",
+		RequestHash: "hash-1",
+		CreatedAt:   at(0),
+		ExpiresAt:   at(24 * time.Hour),
+	}
+
+	reserved, err := repos.Idempotency.Reserve(ctx, record)
+	mustNoError(t, err, "reserve")
+	if !reserved {
+		t.Fatal("first Reserve reported the key as taken")
+	}
+	again := *record
+	again.RequestHash = "hash-2"
+	reserved, err = repos.Idempotency.Reserve(ctx, &again)
+	mustNoError(t, err, "reserve again")
+	if reserved {
+		t.Fatal("second Reserve of the same key succeeded")
+	}
+	otherRoute := *record
+	otherRoute.Route = "POST /team/add"
+	reserved, err = repos.Idempotency.Reserve(ctx, &otherRoute)
+	mustNoError(t, err, "reserve on another route")
+	if !reserved {
+		t.Error("the key is taken on a route it was not used on")
+	}
+	otherActor := *record
+	otherActor.ActorID = "token:t2"
+	reserved, err = repos.Idempotency.Reserve(ctx, &otherActor)
+	mustNoError(t, err, "reserve for another actor")
+	if !reserved {
+		t.Error("the key is taken for an actor that did not use it")
+	}
+
+	pending, err := repos.Idempotency.Get(ctx, record.ActorID, record.Key, record.Route)
+	mustNoError(t, err, "get pending record")
+	if pending == nil || pending.RequestHash != "hash-1" || pending.IsCompleted() {
+		t.Fatalf("got record %+v, want the pending first reservation", pending)
+	}
+	assertTime(t, "expires_at", pending.ExpiresAt, record.ExpiresAt)
+
+	record.StatusCode = 201
+	record.Headers = map[string][]string{"Content-Type": {"application/json"}}
+	record.ResponseBody = []byte(`{"ok":true}`)
+	mustNoError(t, repos.Idempotency.Complete(ctx, record), "complete")
+	completed, err := repos.Idempotency.Get(ctx, record.ActorID, record.Key, record.Route)
+	mustNoError(t, err, "get completed record")
+	if completed == nil || completed.StatusCode != 201 || string(completed.ResponseBody) != `{"ok":true}` ||
+		!reflect.DeepEqual(completed.Headers, record.Headers) || completed.RequestHash != "hash-1" {
+		t.Errorf("got record %+v, want the completed response", completed)
+	}
+
+	mustNoError(t, repos.Idempotency.Delete(ctx, record.ActorID, record.Key, record.Route), "delete")
+	deleted, err := repos.Idempotency.Get(ctx, record.ActorID, record.Key, record.Route)
+	mustNoError(t, err, "get deleted record")
+	if deleted != nil {
+		t.Errorf("got record %+v after Delete, want nil", deleted)
+	}
+	kept, err := repos.Idempotency.Get(ctx, otherRoute.ActorID, otherRoute.Key, otherRoute.Route)
+	mustNoError(t, err, "get record on the other route")
+	if kept == nil {
+		t.Error("Delete removed the key on another route")
+	}
+	kept, err = repos.Idempotency.Get(ctx, otherActor.ActorID, otherActor.Key, otherActor.Route)
+	mustNoError(t, err, "get record of the other actor")
+	if kept == nil || kept.RequestHash != "hash-1" || kept.IsCompleted() {
+		t.Errorf("got record %+v, want the other actor's reservation kept", kept)
+	}
+}
+
+func testAPITokens(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+	seedTeam(t, repos, "backend", active("u1"))
+
+	service := &domain.APIToken{TokenID: "t2", Name: "ci", TokenHash: "hash-ci", Role: domain.APIRoleAdmin, CreatedAt: at(0)}
+	personal := &domain.APIToken{TokenID: "t1", Name: "alice", TokenHash: "hash-alice", Role: domain.APIRoleUser, UserID: "u1", CreatedAt: at(0)}
+	later := &domain.APIToken{TokenID: "t0", Name: "bot", TokenHash: "hash-bot", Role: domain.APIRoleReadOnly, CreatedAt: at(time.Hour)}
+	for _, token := range []*domain.APIToken{service, personal, later} {
+		mustNoError(t, repos.Token.Add(ctx, token), "add token "+token.TokenID)
+	}
+	assertCode(t, repos.Token.Add(ctx, &domain.APIToken{TokenID: "t1", Name: "dup", TokenHash: "hash-dup", Role: domain.APIRoleUser, CreatedAt: at(0)}), domain.ErrConflictCode)
+	assertCode(t, repos.Token.Add(ctx, &domain.APIToken{TokenID: "t9", Name: "ghost", TokenHash: "hash-ghost", Role: domain.APIRoleUser, UserID: "nobody", CreatedAt: at(0)}), domain.ErrNotFoundCode)
+
+	token, err := repos.Token.GetTokenByHash(ctx, "hash-alice")
+	mustNoError(t, err, "get token by hash")
+	if token == nil || token.TokenID != "t1" || token.UserID != "u1" || token.Role != domain.APIRoleUser || token.RevokedAt != nil {
+		t.Fatalf("got token %+v, want t1", token)
+	}
+	missing, err := repos.Token.GetTokenByHash(ctx, "hash-unknown")
+	mustNoError(t, err, "get unknown token")
+	if missing != nil {
+		t.Errorf("got token %+v for unknown hash, want nil", missing)
+	}
+
+	tokens, err := repos.Token.GetTokens(ctx)
+	mustNoError(t, err, "get tokens")
+	var ids []string
+	for _, token := range tokens {
+		ids = append(ids, token.TokenID)
+	}
+	if !slices.Equal(ids, []string{"t1", "t2", "t0"}) {
+		t.Errorf("got tokens %v, want ordered by creation then ID [t1 t2 t0]", ids)
+	}
+
+	mustNoError(t, repos.Token.RevokeToken(ctx, "t1", at(2*time.Hour)), "revoke")
+	mustNoError(t, repos.Token.RevokeToken(ctx, "t1", at(3*time.Hour)), "revoke again")
+	assertCode(t, repos.Token.RevokeToken(ctx, "t9", at(0)), domain.ErrNotFoundCode)
+	revoked, err := repos.Token.GetTokenByHash(ctx, "hash-alice")
+	mustNoError(t, err, "get revoked token")
+	assertTimePtr(t, "revoked_at", revoked.RevokedAt, ptr(at(2*time.Hour)))
+}
+
+func testIdentities(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+	seedTeam(t, repos, "backend", active("u1"), active("u2"))
+
+	identities := []domain.ExternalIdentity{
+		{Provider: domain.ProviderGitHub, Login: "zed", UserID: "u1", CreatedAt: at(0)},
+		{Provider: domain.ProviderGitHub, Login: "alice", UserID: "u1", CreatedAt: at(0)},
+		{Provider: domain.ProviderGitLab, Login: "alice", UserID: "u2", CreatedAt: at(0)},
+	}
+	for _, identity := range identities {
+		mustNoError(t, repos.Identity.SetIdentity(ctx, &identity), "set identity "+identity.Login)
+	}
+	assertCode(t, repos.Identity.SetIdentity(ctx, &domain.ExternalIdentity{Provider: domain.ProviderGitHub, Login: "ghost", UserID: "nobody", CreatedAt: at(0)}), domain.ErrNotFoundCode)
+
+	identity, err := repos.Identity.GetIdentity(ctx, domain.ProviderGitLab, "alice")
+	mustNoError(t, err, "get identity")
+	if identity == nil || identity.UserID != "u2" {
+		t.Fatalf("got identity %+v, want gitlab alice mapped to u2", identity)
+	}
+	missing, err := repos.Identity.GetIdentity(ctx, domain.ProviderGitLab, "zed")
+	mustNoError(t, err, "get missing identity")
+	if missing != nil {
+		t.Errorf("got identity %+v for unknown login, want nil", missing)
+	}
+
+	first, err := repos.Identity.GetIdentityByUserID(ctx, domain.ProviderGitHub, "u1")
+	mustNoError(t, err, "get identity by user")
+	if first == nil || first.Login != "alice" {
+		t.Errorf("got identity %+v, want the first login by name", first)
+	}
+	none, err := repos.Identity.GetIdentityByUserID(ctx, domain.ProviderGitHub, "u2")
+	mustNoError(t, err, "get identity of unmapped user")
+	if none != nil {
+		t.Errorf("got identity %+v for unmapped user, want nil", none)
+	}
+
+	mustNoError(t, repos.Identity.SetIdentity(ctx, &domain.ExternalIdentity{Provider: domain.ProviderGitHub, Login: "zed", UserID: "u2", CreatedAt: at(time.Hour)}), "remap identity")
+	remapped, err := repos.Identity.GetIdentity(ctx, domain.ProviderGitHub, "zed")
+	mustNoError(t, err, "get remapped identity")
+	if remapped == nil || remapped.UserID != "u2" {
+		t.Fatalf("got identity %+v, want zed remapped to u2", remapped)
+	}
+	assertTime(t, "created_at", remapped.CreatedAt, at(0))
+
+	github, err := repos.Identity.GetIdentities(ctx, domain.ProviderGitHub)
+	mustNoError(t, err, "get identities")
+	var logins []string
+	for _, identity := range github {
+		logins = append(logins, identity.Login)
+	}
+	if !slices.Equal(logins, []string{"alice", "zed"}) {
+		t.Errorf("got logins %v, want [alice zed]", logins)
+	}
+
+	mustNoError(t, repos.Identity.DeleteIdentity(ctx, domain.ProviderGitHub, "zed"), "delete identity")
+	assertCode(t, repos.Identity.DeleteIdentity(ctx, domain.ProviderGitHub, "zed"), domain.ErrNotFoundCode)
+	deleted, err := repos.Identity.GetIdentity(ctx, domain.ProviderGitHub, "zed")
+	mustNoError(t, err, "get deleted identity")
+	if deleted != nil {
+		t.Errorf("got identity %+v after Delete, want nil", deleted)
+	}
+}
+
+func testNotifications(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+	seedTeam(t, repos, "backend", active("u1"), active("u2"), active("u3"))
+
+	preferences := &domain.NotificationPreferences{
+		UserID:        "u2",
+		ChatEnabled:   true,
+		ChatChannel:   "@bob",
+		ChatEvents:    []domain.EventType{domain.EventReviewerAssigned, domain.EventPRMerged},
+		Email:         "bob@example.com",
+		DigestEnabled: true,
+		DigestHour:    8,
+		Timezone:      "Europe/Moscow",
+		UpdatedAt:     at(0),
+	}
+	mustNoError(t, repos.Notification.SetPreferences(ctx, preferences), "set preferences")
+	mustNoError(t, repos.Notification.SetPreferences(ctx, &domain.NotificationPreferences{UserID: "u1", Email: "alice@example.com", DigestEnabled: true, Timezone: "UTC", UpdatedAt: at(0)}), "set u1")
+	mustNoError(t, repos.Notification.SetPreferences(ctx, &domain.NotificationPreferences{UserID: "u3", DigestEnabled: true, Timezone: "UTC", UpdatedAt: at(0)}), "set u3")
+	assertCode(t, repos.Notification.SetPreferences(ctx, &domain.NotificationPreferences{UserID: "nobody", Timezone: "UTC"}), domain.ErrNotFoundCode)
+
+	stored, err := repos.Notification.GetPreferences(ctx, "u2")
+	mustNoError(t, err, "get preferences")
+	if stored == nil {
+		t.Fatal("got nil preferences, want u2")
+	}
+	if stored.ChatChannel != "@bob" || !slices.Equal(stored.ChatEvents, preferences.ChatEvents) ||
+		stored.Email != "bob@example.com" || !stored.DigestEnabled || stored.DigestHour != 8 ||
+		stored.Timezone != "Europe/Moscow" || stored.DigestSentAt != nil {
+		t.Errorf("got preferences %+v, want %+v", stored, preferences)
+	}
+	missing, err := repos.Notification.GetPreferences(ctx, "nobody")
+	mustNoError(t, err, "get missing preferences")
+	if missing != nil {
+		t.Errorf("got preferences %+v for user without them, want nil", missing)
+	}
+
+	mustNoError(t, repos.Notification.SetDigestSentAt(ctx, "u2", ptr(at(time.Hour))), "set digest sent")
+	assertCode(t, repos.Notification.SetDigestSentAt(ctx, "nobody", ptr(at(0))), domain.ErrNotFoundCode)
+	preferences.DigestHour = 10
+	preferences.DigestSentAt = nil
+	mustNoError(t, repos.Notification.SetPreferences(ctx, preferences), "update preferences")
+	stored, err = repos.Notification.GetPreferences(ctx, "u2")
+	mustNoError(t, err, "get updated preferences")
+	if stored.DigestHour != 10 {
+		t.Errorf("got digest hour %d, want 10", stored.DigestHour)
+	}
+	assertTimePtr(t, "digest_sent_at", stored.DigestSentAt, ptr(at(time.Hour)))
+
+	recipients, err := repos.Notification.GetDigestRecipients(ctx)
+	mustNoError(t, err, "get digest recipients")
+	var ids []string
+	for _, recipient := range recipients {
+		ids = append(ids, recipient.UserID)
+	}
+	if !slices.Equal(ids, []string{"u1", "u2"}) {
+		t.Errorf("got recipients %v, want users with an email and the digest on [u1 u2]", ids)
+	}
+}
@@ -0,0 +1,145 @@
+// Package contracttest is the behaviour every storage adapter must share.
+// Adapter packages run it from their tests against a fresh database.
+package contracttest
+
+import (
+	"context"
+	"errors"
+	"pullrequests/internal/domain"
+	"testing"
+	"time"
+)
+
+// Repositories are the repositories of one storage, all backed by the same
+// database.
+type Repositories struct {
+	Team         domain.TeamRepo
+	User         domain.UserRepo
+	PullRequest  domain.PullRequestRepo
+	History      domain.ReviewHistoryRepo
+	Idempotency  domain.IdempotencyRepo
+	Token        domain.APITokenRepo
+	Webhook      domain.WebhookRepo
+	Outbox       domain.OutboxRepo
+	Job          domain.JobRepo
+	Identity     domain.IdentityRepo
+	Notification domain.NotificationRepo
+	SLA          domain.ReviewSLARepo
+	TM           domain.TransactionManager
+}
+
+// Factory returns repositories over an empty database on every call.
+type Factory func(t *testing.T) Repositories
+
+// Run runs the whole suite; every subtest gets its own database.
+func Run(t *testing.T, newRepos Factory) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T, repos Repositories)
+	}{
+		{"Teams", testTeams},
+		{"Users", testUsers},
+		{"PullRequests", testPullRequests},
+		{"OptimisticLock", testOptimisticLock},
+		{"Contention", testContention},
+		{"ReviewerStats", testReviewerStats},
+		{"ReviewHistory", testReviewHistory},
+		{"Idempotency", testIdempotency},
+		{"APITokens", testAPITokens},
+		{"Webhooks", testWebhooks},
+		{"Outbox", testOutbox},
+		{"Jobs", testJobs},
+		{"Identities", testIdentities},
+		{"Notifications", testNotifications},
+		{"ReviewSLA", testReviewSLA},
+		{"Transactions", testTransactions},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.run(t, newRepos(t))
+		})
+	}
+}
+
+// baseTime has whole seconds so that every storage keeps it exactly.
+var baseTime = time.Date(2025, time.March, 3, 10, 0, 0, 0, time.UTC)
+
+func at(offset time.Duration) time.Time {
+	return baseTime.Add(offset)
+}
+
+// seedTeam creates a team with members named after their user IDs.
+func seedTeam(t *testing.T, repos Repositories, teamName string, members ...domain.TeamMember) {
+	t.Helper()
+	ctx := context.Background()
+	if err := repos.Team.Add(ctx, &domain.Team{Name: teamName}); err != nil {
+		t.Fatalf("add team %s: %v", teamName, err)
+	}
+	for _, member := range members {
+		if member.Username == "" {
+			member.Username = member.UserID
+		}
+		if member.Role == "" {
+			member.Role = domain.TeamRoleMember
+		}
+		if err := repos.Team.AddTeamMember(ctx, teamName, &member); err != nil {
+			t.Fatalf("add member %s: %v", member.UserID, err)
+		}
+	}
+}
+
+func active(userID string) domain.TeamMember {
+	return domain.TeamMember{UserID: userID, Username: userID, IsActive: true, Role: domain.TeamRoleMember}
+}
+
+func seedPR(t *testing.T, repos Repositories, pullRequestID, authorID string, createdAt time.Time) *domain.PullRequest {
+	t.Helper()
+	pullrequest := &domain.PullRequest{
+		PullRequestID:   pullRequestID,
+		PullRequestName: "Change " + pullRequestID,
+		AuthorID:        authorID,
+		Status:          domain.PRStatusOpen,
+		CreatedAt:       createdAt,
+	}
+	if err := repos.PullRequest.Add(context.Background(), pullrequest); err != nil {
+		t.Fatalf("add pull request %s: %v", pullRequestID, err)
+	}
+	return pullrequest
+}
+
+func mustNoError(t *testing.T, err error, action string) {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("%s: %v", action, err)
+	}
+}
+
+func assertCode(t *testing.T, err error, code domain.ErrCode) {
+	t.Helper()
+	var domainErr domain.DomainError
+	if !errors.As(err, &domainErr) || domainErr.Code() != string(code) {
+		t.Fatalf("got error %v, want %s", err, code)
+	}
+}
+
+func assertTime(t *testing.T, field string, got, want time.Time) {
+	t.Helper()
+	if !got.Equal(want) {
+		t.Errorf("%s = %v, want %v", field, got, want)
+	}
+}
+
+func assertTimePtr(t *testing.T, field string, got *time.Time, want *time.Time) {
+	t.Helper()
+	switch {
+	case got == nil && want == nil:
+	case got == nil || want == nil:
+		t.Errorf("%s = %v, want %v", field, got, want)
+	default:
+		assertTime(t, field, *got, *want)
+	}
+}
+
+func ptr[T any](value T) *T {
+	return &value
+}
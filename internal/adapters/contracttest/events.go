@@ -0,0 +1,373 @@
+package contracttest
+
+import (
+	"context"
+	"errors"
+	"pullrequests/internal/domain"
+	"slices"
+	"testing"
+	"time"
+)
+
+func testWebhooks(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+	subscriptions := []domain.WebhookSubscription{
+		{SubscriptionID: "s2", URL: "https://b.example.com", Secret: "secret-b", CreatedAt: at(0)},
+		{SubscriptionID: "s1", URL: "https://a.example.com", Secret: "secret-a", Events: []domain.EventType{domain.EventPRCreated, domain.EventPRMerged}, CreatedAt: at(0)},
+	}
+	for _, subscription := range subscriptions {
+		mustNoError(t, repos.Webhook.AddSubscription(ctx, &subscription), "add subscription "+subscription.SubscriptionID)
+	}
+	assertCode(t, repos.Webhook.AddSubscription(ctx, &subscriptions[0]), domain.ErrConflictCode)
+
+	subscription, err := repos.Webhook.GetSubscription(ctx, "s1")
+	mustNoError(t, err, "get subscription")
+	if subscription == nil || subscription.URL != "https://a.example.com" || subscription.Secret != "secret-a" ||
+		!slices.Equal(subscription.Events, subscriptions[1].Events) {
+		t.Fatalf("got subscription %+v, want %+v", subscription, subscriptions[1])
+	}
+	missing, err := repos.Webhook.GetSubscription(ctx, "s9")
+	mustNoError(t, err, "get missing subscription")
+	if missing != nil {
+		t.Errorf("got subscription %+v for unknown ID, want nil", missing)
+	}
+	all, err := repos.Webhook.GetSubscriptions(ctx)
+	mustNoError(t, err, "get subscriptions")
+	if len(all) != 2 || all[0].SubscriptionID != "s1" || all[1].SubscriptionID != "s2" || len(all[1].Events) != 0 {
+		t.Errorf("got subscriptions %+v, want s1 then s2 receiving every event", all)
+	}
+
+	deliveries := []domain.WebhookDelivery{
+		newDelivery("d1", "s1", at(time.Minute), at(0)),
+		newDelivery("d2", "s1", at(0), at(time.Minute)),
+		newDelivery("d3", "s2", at(0), at(2*time.Minute)),
+		newDelivery("d4", "s2", at(time.Hour), at(3*time.Minute)),
+	}
+	delivered := newDelivery("d5", "s1", at(0), at(4*time.Minute))
+	delivered.Status = domain.DeliveryStatusDelivered
+	delivered.DeliveredAt = ptr(at(time.Second))
+	deliveries = append(deliveries, delivered)
+	for _, delivery := range deliveries {
+		mustNoError(t, repos.Webhook.AddDelivery(ctx, &delivery), "add delivery "+delivery.DeliveryID)
+	}
+	assertCode(t, repos.Webhook.AddDelivery(ctx, &deliveries[0]), domain.ErrConflictCode)
+	orphan := newDelivery("d9", "s9", at(0), at(0))
+	assertCode(t, repos.Webhook.AddDelivery(ctx, &orphan), domain.ErrNotFoundCode)
+
+	due, err := repos.Webhook.GetDueDeliveries(ctx, at(time.Minute), 10)
+	mustNoError(t, err, "get due deliveries")
+	if ids := deliveryIDs(due); !slices.Equal(ids, []string{"d2", "d3", "d1"}) {
+		t.Errorf("got due deliveries %v, want pending ones by next attempt then ID [d2 d3 d1]", ids)
+	}
+	limited, err := repos.Webhook.GetDueDeliveries(ctx, at(time.Minute), 2)
+	mustNoError(t, err, "get limited due deliveries")
+	if ids := deliveryIDs(limited); !slices.Equal(ids, []string{"d2", "d3"}) {
+		t.Errorf("got due deliveries %v with limit 2, want [d2 d3]", ids)
+	}
+	first := due[0]
+	if string(first.Payload) != `{"id":"d2"}` || first.EventType != domain.EventPRCreated || first.SubscriptionID != "s1" {
+		t.Errorf("got delivery %+v, want d2 as stored", first)
+	}
+	assertTime(t, "created_at", first.CreatedAt, at(time.Minute))
+
+	first.Status = domain.DeliveryStatusFailed
+	first.Attempts = 3
+	first.LastError = "receiver returned 500"
+	first.ResponseStatus = 500
+	mustNoError(t, repos.Webhook.UpdateDelivery(ctx, &first), "update delivery")
+	due, err = repos.Webhook.GetDueDeliveries(ctx, at(time.Minute), 10)
+	mustNoError(t, err, "get due deliveries after update")
+	if ids := deliveryIDs(due); !slices.Equal(ids, []string{"d3", "d1"}) {
+		t.Errorf("got due deliveries %v, want the failed one left out [d3 d1]", ids)
+	}
+
+	history, err := repos.Webhook.GetDeliveries(ctx, "s1", 10)
+	mustNoError(t, err, "get deliveries of s1")
+	if ids := deliveryIDs(history); !slices.Equal(ids, []string{"d5", "d2", "d1"}) {
+		t.Errorf("got deliveries %v, want newest first [d5 d2 d1]", ids)
+	}
+	if history[1].Status != domain.DeliveryStatusFailed || history[1].Attempts != 3 ||
+		history[1].LastError != "receiver returned 500" || history[1].ResponseStatus != 500 {
+		t.Errorf("got delivery %+v, want the update stored", history[1])
+	}
+	assertTimePtr(t, "delivered_at", history[0].DeliveredAt, ptr(at(time.Second)))
+	recent, err := repos.Webhook.GetDeliveries(ctx, "", 2)
+	mustNoError(t, err, "get recent deliveries")
+	if ids := deliveryIDs(recent); !slices.Equal(ids, []string{"d5", "d4"}) {
+		t.Errorf("got deliveries %v, want the newest two of every subscription [d5 d4]", ids)
+	}
+
+	mustNoError(t, repos.Webhook.DeleteSubscription(ctx, "s2"), "delete subscription")
+	assertCode(t, repos.Webhook.DeleteSubscription(ctx, "s2"), domain.ErrNotFoundCode)
+	remaining, err := repos.Webhook.GetDeliveries(ctx, "", 10)
+	mustNoError(t, err, "get deliveries after delete")
+	if ids := deliveryIDs(remaining); !slices.Equal(ids, []string{"d5", "d2", "d1"}) {
+		t.Errorf("got deliveries %v, want those of the deleted subscription gone", ids)
+	}
+}
+
+func testOutbox(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+	messages := []domain.OutboxMessage{
+		newMessage("e2", at(0)),
+		newMessage("e1", at(0)),
+		newMessage("e3", at(-time.Minute)),
+		newMessage("e4", at(time.Hour)),
+	}
+	for _, message := range messages {
+		mustNoError(t, repos.Outbox.Add(ctx, &message), "add message "+message.EventID)
+	}
+	assertCode(t, repos.Outbox.Add(ctx, &messages[0]), domain.ErrConflictCode)
+
+	// Messages come out in insertion order, whatever their timestamps.
+	pending, err := repos.Outbox.GetPending(ctx, at(0), 10)
+	mustNoError(t, err, "get pending")
+	if ids := eventIDs(pending); !slices.Equal(ids, []string{"e2", "e1", "e3"}) {
+		t.Errorf("got pending messages %v, want due ones in insertion order [e2 e1 e3]", ids)
+	}
+	for i := 1; i < len(pending); i++ {
+		if pending[i].Sequence <= pending[i-1].Sequence {
+			t.Errorf("got sequences %d then %d, want them increasing", pending[i-1].Sequence, pending[i].Sequence)
+		}
+	}
+	limited, err := repos.Outbox.GetPending(ctx, at(0), 1)
+	mustNoError(t, err, "get limited pending")
+	if ids := eventIDs(limited); !slices.Equal(ids, []string{"e2"}) {
+		t.Errorf("got pending messages %v with limit 1, want [e2]", ids)
+	}
+	message := pending[2]
+	if message.EventType != domain.EventPRCreated || string(message.Payload) != `{"id":"e3"}` ||
+		message.Status != domain.OutboxStatusPending || len(message.DeliveredSinks) != 0 {
+		t.Errorf("got message %+v, want e3 as stored", message)
+	}
+	assertTime(t, "occurred_at", message.OccurredAt, at(-time.Minute))
+
+	message.Status = domain.OutboxStatusProcessed
+	message.Attempts = 1
+	message.ProcessedAt = ptr(at(time.Second))
+	mustNoError(t, repos.Outbox.Update(ctx, &message), "mark processed")
+	retried := pending[0]
+	retried.Attempts = 1
+	retried.LastError = "sink failed"
+	retried.NextAttemptAt = at(30 * time.Minute)
+	retried.DeliveredSinks = []string{"webhooks", "log"}
+	mustNoError(t, repos.Outbox.Update(ctx, &retried), "schedule retry")
+
+	pending, err = repos.Outbox.GetPending(ctx, at(0), 10)
+	mustNoError(t, err, "get pending after update")
+	if ids := eventIDs(pending); !slices.Equal(ids, []string{"e1"}) {
+		t.Errorf("got pending messages %v, want processed and rescheduled ones left out [e1]", ids)
+	}
+	// A retried message keeps its place.
+	pending, err = repos.Outbox.GetPending(ctx, at(time.Hour), 10)
+	mustNoError(t, err, "get pending later")
+	if ids := eventIDs(pending); !slices.Equal(ids, []string{"e2", "e1", "e4"}) {
+		t.Fatalf("got pending messages %v, want [e2 e1 e4]", ids)
+	}
+	if pending[0].Attempts != 1 || pending[0].LastError != "sink failed" || !slices.Equal(pending[0].DeliveredSinks, retried.DeliveredSinks) {
+		t.Errorf("got message %+v, want the retry stored", pending[0])
+	}
+}
+
+func testJobs(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+	jobs := []domain.Job{
+		newJob("j2", domain.JobReviewerSync, at(0)),
+		newJob("j1", domain.JobReviewerSync, at(0)),
+		newJob("j3", domain.JobReviewerSync, at(-time.Minute)),
+		newJob("j4", domain.JobReviewerSync, at(time.Hour)),
+		newJob("j5", "other.kind", at(-time.Hour)),
+	}
+	for _, job := range jobs {
+		mustNoError(t, repos.Job.AddJob(ctx, &job), "add job "+job.JobID)
+	}
+	assertCode(t, repos.Job.AddJob(ctx, &jobs[0]), domain.ErrConflictCode)
+
+	due, err := repos.Job.GetDueJobs(ctx, domain.JobReviewerSync, at(0), 10)
+	mustNoError(t, err, "get due jobs")
+	if ids := jobIDs(due); !slices.Equal(ids, []string{"j3", "j1", "j2"}) {
+		t.Errorf("got due jobs %v, want due ones of the kind by next attempt then ID [j3 j1 j2]", ids)
+	}
+	limited, err := repos.Job.GetDueJobs(ctx, domain.JobReviewerSync, at(0), 1)
+	mustNoError(t, err, "get limited due jobs")
+	if ids := jobIDs(limited); !slices.Equal(ids, []string{"j3"}) {
+		t.Errorf("got due jobs %v with limit 1, want [j3]", ids)
+	}
+	job := due[0]
+	if job.Kind != domain.JobReviewerSync || string(job.Payload) != `{"id":"j3"}` || job.Status != domain.JobStatusPending {
+		t.Errorf("got job %+v, want j3 as stored", job)
+	}
+	assertTime(t, "created_at", job.CreatedAt, at(-time.Minute))
+	assertTimePtr(t, "finished_at", job.FinishedAt, nil)
+
+	job.Status = domain.JobStatusDone
+	job.Attempts = 1
+	job.FinishedAt = ptr(at(time.Second))
+	mustNoError(t, repos.Job.UpdateJob(ctx, &job), "mark done")
+	retried := due[1]
+	retried.Attempts = 1
+	retried.LastError = "host unavailable"
+	retried.NextAttemptAt = at(30 * time.Minute)
+	mustNoError(t, repos.Job.UpdateJob(ctx, &retried), "schedule retry")
+
+	due, err = repos.Job.GetDueJobs(ctx, domain.JobReviewerSync, at(time.Hour), 10)
+	mustNoError(t, err, "get due jobs later")
+	if ids := jobIDs(due); !slices.Equal(ids, []string{"j2", "j1", "j4"}) {
+		t.Fatalf("got due jobs %v, want [j2 j1 j4]", ids)
+	}
+	if due[1].Attempts != 1 || due[1].LastError != "host unavailable" {
+		t.Errorf("got job %+v, want the retry stored", due[1])
+	}
+}
+
+var errRollback = errors.New("rollback")
+
+func testTransactions(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+
+	err := repos.TM.Do(ctx, func(ctx context.Context) error {
+		if err := repos.Team.Add(ctx, &domain.Team{Name: "committed"}); err != nil {
+			return err
+		}
+		team, err := repos.Team.GetTeamByTeamName(ctx, "committed")
+		if err != nil {
+			return err
+		}
+		if team == nil {
+			t.Error("a write is not visible later in the same transaction")
+		}
+		return nil
+	})
+	mustNoError(t, err, "commit")
+	assertTeamExists(t, repos, "committed", true)
+
+	err = repos.TM.Do(ctx, func(ctx context.Context) error {
+		if err := repos.Team.Add(ctx, &domain.Team{Name: "rolled-back"}); err != nil {
+			return err
+		}
+		return errRollback
+	})
+	if !errors.Is(err, errRollback) {
+		t.Fatalf("got error %v, want the error returned by fn", err)
+	}
+	assertTeamExists(t, repos, "rolled-back", false)
+
+	err = repos.TM.Do(ctx, func(ctx context.Context) error {
+		err := repos.TM.Do(ctx, func(ctx context.Context) error {
+			return repos.Team.Add(ctx, &domain.Team{Name: "nested"})
+		})
+		if err != nil {
+			return err
+		}
+		return errRollback
+	})
+	if !errors.Is(err, errRollback) {
+		t.Fatalf("got error %v, want the error returned by the outer fn", err)
+	}
+	assertTeamExists(t, repos, "nested", false)
+
+	// A failed savepoint undoes only its own writes, even after a failed
+	// statement, and the transaction goes on.
+	err = repos.TM.Do(ctx, func(ctx context.Context) error {
+		if err := repos.Team.Add(ctx, &domain.Team{Name: "before-savepoint"}); err != nil {
+			return err
+		}
+		err := repos.TM.Do(ctx, func(ctx context.Context) error {
+			if err := repos.Team.Add(ctx, &domain.Team{Name: "in-savepoint"}); err != nil {
+				return err
+			}
+			return repos.Team.Add(ctx, &domain.Team{Name: "committed"})
+		}, domain.WithSavepoint())
+		assertCode(t, err, domain.ErrTeamExistsCode)
+		err = repos.TM.Do(ctx, func(ctx context.Context) error {
+			return repos.Team.Add(ctx, &domain.Team{Name: "released"})
+		}, domain.WithSavepoint())
+		if err != nil {
+			return err
+		}
+		return repos.Team.Add(ctx, &domain.Team{Name: "after-savepoint"})
+	})
+	mustNoError(t, err, "commit after a failed savepoint")
+	for teamName, want := range map[string]bool{"before-savepoint": true, "in-savepoint": false, "released": true, "after-savepoint": true} {
+		assertTeamExists(t, repos, teamName, want)
+	}
+
+	err = repos.TM.Do(ctx, func(ctx context.Context) error {
+		_, err := repos.Team.GetTeamByTeamName(ctx, "committed")
+		return err
+	}, domain.WithReadOnly(), domain.WithIsolation(domain.IsolationSerializable))
+	mustNoError(t, err, "read-only serializable transaction")
+
+	err = repos.TM.Do(ctx, func(ctx context.Context) error {
+		return repos.Team.Add(ctx, &domain.Team{Name: "committed"})
+	})
+	assertCode(t, err, domain.ErrTeamExistsCode)
+}
+
+func assertTeamExists(t *testing.T, repos Repositories, teamName string, want bool) {
+	t.Helper()
+	team, err := repos.Team.GetTeamByTeamName(context.Background(), teamName)
+	mustNoError(t, err, "get team "+teamName)
+	if (team != nil) != want {
+		t.Errorf("team %s exists = %v, want %v", teamName, team != nil, want)
+	}
+}
+
+func newDelivery(deliveryID, subscriptionID string, nextAttemptAt, createdAt time.Time) domain.WebhookDelivery {
+	return domain.WebhookDelivery{
+		DeliveryID:     deliveryID,
+		SubscriptionID: subscriptionID,
+		EventID:        "event-" + deliveryID,
+		EventType:      domain.EventPRCreated,
+		Payload:        []byte(`{"id":"` + deliveryID + `"}`),
+		Status:         domain.DeliveryStatusPending,
+		NextAttemptAt:  nextAttemptAt,
+		CreatedAt:      createdAt,
+	}
+}
+
+func newMessage(eventID string, nextAttemptAt time.Time) domain.OutboxMessage {
+	return domain.OutboxMessage{
+		EventID:       eventID,
+		EventType:     domain.EventPRCreated,
+		Payload:       []byte(`{"id":"` + eventID + `"}`),
+		OccurredAt:    nextAttemptAt,
+		Status:        domain.OutboxStatusPending,
+		NextAttemptAt: nextAttemptAt,
+	}
+}
+
+func newJob(jobID string, kind domain.JobKind, nextAttemptAt time.Time) domain.Job {
+	return domain.Job{
+		JobID:         jobID,
+		Kind:          kind,
+		Payload:       []byte(`{"id":"` + jobID + `"}`),
+		Status:        domain.JobStatusPending,
+		NextAttemptAt: nextAttemptAt,
+		CreatedAt:     nextAttemptAt,
+	}
+}
+
+func deliveryIDs(deliveries []domain.WebhookDelivery) []string {
+	ids := make([]string, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		ids = append(ids, delivery.DeliveryID)
+	}
+	return ids
+}
+
+func eventIDs(messages []domain.OutboxMessage) []string {
+	ids := make([]string, 0, len(messages))
+	for _, message := range messages {
+		ids = append(ids, message.EventID)
+	}
+	return ids
+}
+
+func jobIDs(jobs []domain.Job) []string {
+	ids := make([]string, 0, len(jobs))
+	for _, job := range jobs {
+		ids = append(ids, job.JobID)
+	}
+	return ids
+}
@@ -0,0 +1,420 @@
+package contracttest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"pullrequests/internal/domain"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+func testTeams(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+	seedTeam(t, repos, "backend", active("u2"), domain.TeamMember{UserID: "u1", Username: "alice", Role: domain.TeamRoleLead})
+
+	team, err := repos.Team.GetTeamByTeamName(ctx, "backend")
+	mustNoError(t, err, "get team")
+	if team == nil || team.Name != "backend" {
+		t.Fatalf("got team %+v, want backend", team)
+	}
+	missing, err := repos.Team.GetTeamByTeamName(ctx, "frontend")
+	mustNoError(t, err, "get missing team")
+	if missing != nil {
+		t.Errorf("got team %+v for unknown name, want nil", missing)
+	}
+
+	assertCode(t, repos.Team.Add(ctx, &domain.Team{Name: "backend"}), domain.ErrTeamExistsCode)
+	assertCode(t, repos.Team.AddTeamMember(ctx, "backend", ptr(active("u1"))), domain.ErrUserExistsCode)
+	assertCode(t, repos.Team.AddTeamMember(ctx, "frontend", ptr(active("u3"))), domain.ErrNotFoundCode)
+
+	members, err := repos.Team.GetTeamMembersByTeamName(ctx, "backend")
+	mustNoError(t, err, "get members")
+	want := []domain.TeamMember{
+		{UserID: "u1", Username: "alice", IsActive: false, Role: domain.TeamRoleLead},
+		{UserID: "u2", Username: "u2", IsActive: true, Role: domain.TeamRoleMember},
+	}
+	if !slices.Equal(members, want) {
+		t.Errorf("got members %+v, want %+v", members, want)
+	}
+
+	empty, err := repos.Team.GetTeamMembersByTeamName(ctx, "frontend")
+	mustNoError(t, err, "get members of unknown team")
+	if empty == nil || len(empty) != 0 {
+		t.Errorf("got members %#v for unknown team, want an empty slice", empty)
+	}
+}
+
+func testUsers(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+	seedTeam(t, repos, "backend", active("u3"), active("u1"), domain.TeamMember{UserID: "u2"})
+	seedTeam(t, repos, "frontend")
+
+	user, err := repos.User.GetUserByID(ctx, "u1")
+	mustNoError(t, err, "get user")
+	want := domain.User{UserID: "u1", Username: "u1", TeamName: "backend", IsActive: true, Role: domain.TeamRoleMember}
+	if user == nil || *user != want {
+		t.Fatalf("got user %+v, want %+v", user, want)
+	}
+	missing, err := repos.User.GetUserByID(ctx, "nobody")
+	mustNoError(t, err, "get missing user")
+	if missing != nil {
+		t.Errorf("got user %+v for unknown ID, want nil", missing)
+	}
+
+	activeUsers, err := repos.User.GetActiveUsersByTeamName(ctx, "backend")
+	mustNoError(t, err, "get active users")
+	if ids := userIDs(activeUsers); !slices.Equal(ids, []string{"u1", "u3"}) {
+		t.Errorf("got active users %v, want [u1 u3]", ids)
+	}
+
+	updated := domain.User{UserID: "u1", Username: "alice", TeamName: "frontend", IsActive: false, Role: domain.TeamRoleLead}
+	mustNoError(t, repos.User.UpdateUser(ctx, &updated), "update user")
+	user, err = repos.User.GetUserByID(ctx, "u1")
+	mustNoError(t, err, "get updated user")
+	if user == nil || *user != updated {
+		t.Errorf("got user %+v, want %+v", user, updated)
+	}
+
+	moved := updated
+	moved.TeamName = "mobile"
+	assertCode(t, repos.User.UpdateUser(ctx, &moved), domain.ErrNotFoundCode)
+}
+
+func testPullRequests(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+	seedTeam(t, repos, "backend", active("author"), active("r1"), active("r2"))
+
+	created := seedPR(t, repos, "pr-1", "author", at(0))
+	if created.Version != 1 {
+		t.Errorf("got version %d after Add, want 1", created.Version)
+	}
+	seedPR(t, repos, "pr-2", "author", at(time.Hour))
+
+	duplicate := &domain.PullRequest{PullRequestID: "pr-1", PullRequestName: "again", AuthorID: "author", Status: domain.PRStatusOpen, CreatedAt: at(0)}
+	assertCode(t, repos.PullRequest.Add(ctx, duplicate), domain.ErrPRExistsCode)
+	orphan := &domain.PullRequest{PullRequestID: "pr-3", PullRequestName: "orphan", AuthorID: "nobody", Status: domain.PRStatusOpen, CreatedAt: at(0)}
+	assertCode(t, repos.PullRequest.Add(ctx, orphan), domain.ErrNotFoundCode)
+
+	pullrequest, err := repos.PullRequest.GetPullRequestByID(ctx, "pr-1")
+	mustNoError(t, err, "get pull request")
+	if pullrequest == nil {
+		t.Fatal("got nil pull request, want pr-1")
+	}
+	if pullrequest.PullRequestName != "Change pr-1" || pullrequest.AuthorID != "author" ||
+		pullrequest.Status != domain.PRStatusOpen || pullrequest.MergedAt != nil || pullrequest.Version != 1 {
+		t.Errorf("got pull request %+v", pullrequest)
+	}
+	assertTime(t, "created_at", pullrequest.CreatedAt, at(0))
+	missing, err := repos.PullRequest.GetPullRequestByID(ctx, "pr-9")
+	mustNoError(t, err, "get missing pull request")
+	if missing != nil {
+		t.Errorf("got pull request %+v for unknown ID, want nil", missing)
+	}
+
+	mustNoError(t, repos.PullRequest.AddReviewer(ctx, pullrequest, "r2"), "add r2")
+	mustNoError(t, repos.PullRequest.AddReviewer(ctx, pullrequest, "r1"), "add r1")
+	if pullrequest.Version != 3 {
+		t.Errorf("got version %d after two reviewers, want 3", pullrequest.Version)
+	}
+	assertCode(t, repos.PullRequest.AddReviewer(ctx, pullrequest, "r1"), domain.ErrConflictCode)
+
+	pullrequest, err = repos.PullRequest.GetPullRequestByID(ctx, "pr-1")
+	mustNoError(t, err, "reload pull request")
+	assertCode(t, repos.PullRequest.AddReviewer(ctx, pullrequest, "nobody"), domain.ErrNotFoundCode)
+
+	reviewers, err := repos.PullRequest.GetReviewers(ctx, "pr-1")
+	mustNoError(t, err, "get reviewers")
+	if ids := reviewerIDs(reviewers); !slices.Equal(ids, []string{"r1", "r2"}) {
+		t.Errorf("got reviewers %v, want [r1 r2]", ids)
+	}
+
+	pullrequest, err = repos.PullRequest.GetPullRequestByID(ctx, "pr-1")
+	mustNoError(t, err, "reload pull request")
+	mustNoError(t, repos.PullRequest.RemoveReviewer(ctx, pullrequest, "r2"), "remove r2")
+	reviewers, err = repos.PullRequest.GetReviewers(ctx, "pr-1")
+	mustNoError(t, err, "get reviewers after removal")
+	if ids := reviewerIDs(reviewers); !slices.Equal(ids, []string{"r1"}) {
+		t.Errorf("got reviewers %v after removal, want [r1]", ids)
+	}
+
+	second, err := repos.PullRequest.GetPullRequestByID(ctx, "pr-2")
+	mustNoError(t, err, "get pr-2")
+	mustNoError(t, repos.PullRequest.AddReviewer(ctx, second, "r1"), "add r1 to pr-2")
+	assigned, err := repos.PullRequest.GetUserAssignedPRs(ctx, "r1")
+	mustNoError(t, err, "get assigned pull requests")
+	if ids := pullRequestIDs(assigned); !slices.Equal(ids, []string{"pr-2", "pr-1"}) {
+		t.Errorf("got assigned pull requests %v, want newest first [pr-2 pr-1]", ids)
+	}
+
+	mergedAt := at(2 * time.Hour)
+	pullrequest.Status = domain.PRStatusMerged
+	pullrequest.MergedAt = &mergedAt
+	version := pullrequest.Version
+	mustNoError(t, repos.PullRequest.UpdatePullRequest(ctx, pullrequest), "merge pull request")
+	if pullrequest.Version != version+1 {
+		t.Errorf("got version %d after update, want %d", pullrequest.Version, version+1)
+	}
+	merged, err := repos.PullRequest.GetPullRequestByID(ctx, "pr-1")
+	mustNoError(t, err, "get merged pull request")
+	if merged.Status != domain.PRStatusMerged || merged.Version != pullrequest.Version {
+		t.Errorf("got pull request %+v after merge", merged)
+	}
+	assertTimePtr(t, "merged_at", merged.MergedAt, &mergedAt)
+}
+
+func testOptimisticLock(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+	seedTeam(t, repos, "backend", active("author"), active("r1"), active("r2"))
+	seedPR(t, repos, "pr-1", "author", at(0))
+
+	first, err := repos.PullRequest.GetPullRequestByID(ctx, "pr-1")
+	mustNoError(t, err, "load first copy")
+	second, err := repos.PullRequest.GetPullRequestByID(ctx, "pr-1")
+	mustNoError(t, err, "load second copy")
+
+	mustNoError(t, repos.PullRequest.AddReviewer(ctx, first, "r1"), "add reviewer with fresh version")
+	assertCode(t, repos.PullRequest.AddReviewer(ctx, second, "r2"), domain.ErrConflictCode)
+	assertCode(t, repos.PullRequest.RemoveReviewer(ctx, second, "r1"), domain.ErrConflictCode)
+	second.Status = domain.PRStatusMerged
+	assertCode(t, repos.PullRequest.UpdatePullRequest(ctx, second), domain.ErrConflictCode)
+
+	stored, err := repos.PullRequest.GetPullRequestByID(ctx, "pr-1")
+	mustNoError(t, err, "reload pull request")
+	if stored.Version != first.Version || stored.Status != domain.PRStatusOpen {
+		t.Errorf("got pull request %+v, want version %d and still OPEN", stored, first.Version)
+	}
+	reviewers, err := repos.PullRequest.GetReviewers(ctx, "pr-1")
+	mustNoError(t, err, "get reviewers")
+	if ids := reviewerIDs(reviewers); !slices.Equal(ids, []string{"r1"}) {
+		t.Errorf("got reviewers %v, want only the reviewer added with the fresh version", ids)
+	}
+
+	unknown := &domain.PullRequest{PullRequestID: "pr-9", Version: 1}
+	assertCode(t, repos.PullRequest.UpdatePullRequest(ctx, unknown), domain.ErrConflictCode)
+}
+
+// testContention runs concurrent serializable transactions that each add a
+// reviewer only while fewer than two are assigned, the check that used to
+// double-book under READ COMMITTED. Storages may retry or reject the losers
+// with CONFLICT, but must never exceed the limit or lose an update.
+func testContention(t *testing.T, repos Repositories) {
+	const workers = 8
+	ctx := context.Background()
+	members := []domain.TeamMember{active("author")}
+	for i := range workers {
+		members = append(members, active(fmt.Sprintf("r%d", i)))
+	}
+	seedTeam(t, repos, "backend", members...)
+	initial := seedPR(t, repos, "pr-1", "author", at(0))
+
+	var wg sync.WaitGroup
+	errs := make([]error, workers)
+	for i := range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = repos.TM.Do(ctx, func(ctx context.Context) error {
+				pullrequest, err := repos.PullRequest.GetPullRequestByID(ctx, "pr-1")
+				if err != nil {
+					return err
+				}
+				reviewers, err := repos.PullRequest.GetReviewers(ctx, "pr-1")
+				if err != nil || len(reviewers) >= 2 {
+					return err
+				}
+				return repos.PullRequest.AddReviewer(ctx, pullrequest, fmt.Sprintf("r%d", i))
+			}, domain.WithName("contracttest.contention"), domain.WithIsolation(domain.IsolationSerializable))
+		}()
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		var domainErr domain.DomainError
+		if err != nil && !(errors.As(err, &domainErr) && domainErr.Code() == string(domain.ErrConflictCode)) {
+			t.Errorf("worker %d: got error %v, want none or CONFLICT", i, err)
+		}
+	}
+
+	reviewers, err := repos.PullRequest.GetReviewers(ctx, "pr-1")
+	mustNoError(t, err, "get reviewers")
+	if len(reviewers) == 0 || len(reviewers) > 2 {
+		t.Fatalf("got reviewers %v, want one or two", reviewerIDs(reviewers))
+	}
+	stored, err := repos.PullRequest.GetPullRequestByID(ctx, "pr-1")
+	mustNoError(t, err, "reload pull request")
+	if want := initial.Version + len(reviewers); stored.Version != want {
+		t.Errorf("got version %d, want %d: one bump per stored reviewer", stored.Version, want)
+	}
+}
+
+func testReviewerStats(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+	seedTeam(t, repos, "backend", active("author"), active("r1"), domain.TeamMember{UserID: "r2", Role: domain.TeamRoleLead})
+	seedTeam(t, repos, "frontend", active("f1"))
+
+	open := seedPR(t, repos, "pr-1", "author", at(0))
+	mustNoError(t, repos.PullRequest.AddReviewer(ctx, open, "r1"), "add r1")
+	mustNoError(t, repos.PullRequest.AddReviewer(ctx, open, "r2"), "add r2")
+	merged := seedPR(t, repos, "pr-2", "author", at(time.Minute))
+	mustNoError(t, repos.PullRequest.AddReviewer(ctx, merged, "r1"), "add r1 to pr-2")
+	merged.Status = domain.PRStatusMerged
+	merged.MergedAt = ptr(at(time.Hour))
+	mustNoError(t, repos.PullRequest.UpdatePullRequest(ctx, merged), "merge pr-2")
+	for _, assignment := range []domain.ReviewAssignment{
+		{PullRequestID: "pr-1", AuthorID: "author", ReviewerID: "r1", AssignedAt: at(0)},
+		{PullRequestID: "pr-1", AuthorID: "author", ReviewerID: "r2", AssignedAt: at(0), Escalated: true},
+		{PullRequestID: "pr-2", AuthorID: "author", ReviewerID: "r2", AssignedAt: at(time.Minute), Escalated: true},
+	} {
+		mustNoError(t, repos.History.Add(ctx, &assignment), "add assignment")
+	}
+
+	stats, err := repos.PullRequest.GetReviewerStatsByTeamName(ctx, "backend")
+	mustNoError(t, err, "get stats")
+	want := []domain.ReviewerStat{
+		{UserID: "author", Username: "author", Role: domain.TeamRoleMember},
+		{UserID: "r1", Username: "r1", Role: domain.TeamRoleMember, Assignments: 2, OpenAssignments: 1},
+		{UserID: "r2", Username: "r2", Role: domain.TeamRoleLead, Assignments: 1, OpenAssignments: 1, Escalations: 2},
+	}
+	if !slices.Equal(stats, want) {
+		t.Errorf("got stats %+v, want %+v", stats, want)
+	}
+
+	empty, err := repos.PullRequest.GetReviewerStatsByTeamName(ctx, "mobile")
+	mustNoError(t, err, "get stats of unknown team")
+	if empty == nil || len(empty) != 0 {
+		t.Errorf("got stats %#v for unknown team, want an empty slice", empty)
+	}
+
+	seedPR(t, repos, "pr-3", "author", at(2*time.Minute))
+	seedPR(t, repos, "pr-4", "f1", at(3*time.Minute))
+	counts, err := repos.PullRequest.CountOpenByTeam(ctx)
+	mustNoError(t, err, "count open pull requests")
+	if want := map[string]int{"backend": 2, "frontend": 1}; !maps.Equal(counts, want) {
+		t.Errorf("got open counts %v, want %v", counts, want)
+	}
+}
+
+func testReviewHistory(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+	seedTeam(t, repos, "backend", active("a1"), active("a2"), active("r1"), active("r2"))
+	seedPR(t, repos, "pr-1", "a1", at(0))
+	seedPR(t, repos, "pr-2", "a2", at(0))
+
+	assignments := []domain.ReviewAssignment{
+		{PullRequestID: "pr-1", AuthorID: "a1", ReviewerID: "r1", AssignedAt: at(time.Hour)},
+		{PullRequestID: "pr-1", AuthorID: "a1", ReviewerID: "r1", AssignedAt: at(3 * time.Hour)},
+		{PullRequestID: "pr-1", AuthorID: "a1", ReviewerID: "r1", AssignedAt: at(2 * time.Hour)},
+		{PullRequestID: "pr-1", AuthorID: "a1", ReviewerID: "r2", AssignedAt: at(time.Minute)},
+		{PullRequestID: "pr-2", AuthorID: "a2", ReviewerID: "r2", AssignedAt: at(5 * time.Hour)},
+	}
+	for _, assignment := range assignments {
+		mustNoError(t, repos.History.Add(ctx, &assignment), "add assignment")
+	}
+
+	last, err := repos.History.GetLastAssignedAtByAuthor(ctx, "a1")
+	mustNoError(t, err, "get last assignments")
+	if len(last) != 2 {
+		t.Fatalf("got %d reviewers, want 2: %v", len(last), last)
+	}
+	assertTime(t, "r1", last["r1"], at(3*time.Hour))
+	assertTime(t, "r2", last["r2"], at(time.Minute))
+
+	none, err := repos.History.GetLastAssignedAtByAuthor(ctx, "r1")
+	mustNoError(t, err, "get last assignments of author without history")
+	if len(none) != 0 {
+		t.Errorf("got %v, want no assignments", none)
+	}
+}
+
+func testReviewSLA(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+	seedTeam(t, repos, "backend", active("b1"))
+	seedTeam(t, repos, "frontend", active("f1"))
+
+	sla := &domain.ReviewSLA{TeamName: "frontend", RemindAfter: 4 * time.Hour, EscalateAfter: 24 * time.Hour, UpdatedAt: at(0)}
+	mustNoError(t, repos.SLA.SetSLA(ctx, sla), "set sla")
+	mustNoError(t, repos.SLA.SetSLA(ctx, &domain.ReviewSLA{TeamName: "backend", RemindAfter: time.Hour, UpdatedAt: at(0)}), "set backend sla")
+	sla.EscalateAfter = 0
+	sla.UpdatedAt = at(time.Hour)
+	mustNoError(t, repos.SLA.SetSLA(ctx, sla), "replace sla")
+	assertCode(t, repos.SLA.SetSLA(ctx, &domain.ReviewSLA{TeamName: "mobile", RemindAfter: time.Hour}), domain.ErrNotFoundCode)
+
+	stored, err := repos.SLA.GetSLA(ctx, "frontend")
+	mustNoError(t, err, "get sla")
+	if stored == nil || stored.RemindAfter != 4*time.Hour || stored.EscalateAfter != 0 {
+		t.Fatalf("got sla %+v, want the replaced one", stored)
+	}
+	assertTime(t, "updated_at", stored.UpdatedAt, at(time.Hour))
+	missing, err := repos.SLA.GetSLA(ctx, "mobile")
+	mustNoError(t, err, "get missing sla")
+	if missing != nil {
+		t.Errorf("got sla %+v for team without one, want nil", missing)
+	}
+
+	slas, err := repos.SLA.GetSLAs(ctx)
+	mustNoError(t, err, "get slas")
+	if len(slas) != 2 || slas[0].TeamName != "backend" || slas[1].TeamName != "frontend" {
+		t.Errorf("got slas %+v, want backend and frontend ordered by team", slas)
+	}
+
+	seedPR(t, repos, "pr-b", "b1", at(2*time.Hour))
+	seedPR(t, repos, "pr-a", "f1", at(2*time.Hour))
+	seedPR(t, repos, "pr-old", "f1", at(time.Hour))
+	seedPR(t, repos, "pr-new", "b1", at(3*time.Hour))
+	merged := seedPR(t, repos, "pr-merged", "b1", at(0))
+	merged.Status = domain.PRStatusMerged
+	merged.MergedAt = ptr(at(time.Hour))
+	mustNoError(t, repos.PullRequest.UpdatePullRequest(ctx, merged), "merge pull request")
+
+	mustNoError(t, repos.SLA.MarkReminded(ctx, "pr-old", at(5*time.Hour)), "mark reminded")
+	mustNoError(t, repos.SLA.MarkEscalated(ctx, "pr-old", at(6*time.Hour)), "mark escalated")
+	assertCode(t, repos.SLA.MarkReminded(ctx, "pr-9", at(0)), domain.ErrNotFoundCode)
+	assertCode(t, repos.SLA.MarkEscalated(ctx, "pr-9", at(0)), domain.ErrNotFoundCode)
+
+	reviews, err := repos.SLA.GetOpenReviews(ctx, at(3*time.Hour))
+	mustNoError(t, err, "get open reviews")
+	want := []domain.OpenReview{
+		{PullRequestID: "pr-old", AuthorID: "f1", TeamName: "frontend", CreatedAt: at(time.Hour), RemindedAt: ptr(at(5 * time.Hour)), EscalatedAt: ptr(at(6 * time.Hour))},
+		{PullRequestID: "pr-a", AuthorID: "f1", TeamName: "frontend", CreatedAt: at(2 * time.Hour)},
+		{PullRequestID: "pr-b", AuthorID: "b1", TeamName: "backend", CreatedAt: at(2 * time.Hour)},
+	}
+	if len(reviews) != len(want) {
+		t.Fatalf("got %d open reviews %+v, want %d", len(reviews), reviews, len(want))
+	}
+	for i, review := range reviews {
+		if review.PullRequestID != want[i].PullRequestID || review.AuthorID != want[i].AuthorID || review.TeamName != want[i].TeamName {
+			t.Errorf("review %d = %+v, want %+v", i, review, want[i])
+		}
+		assertTime(t, "created_at", review.CreatedAt, want[i].CreatedAt)
+		assertTimePtr(t, "reminded_at", review.RemindedAt, want[i].RemindedAt)
+		assertTimePtr(t, "escalated_at", review.EscalatedAt, want[i].EscalatedAt)
+	}
+}
+
+func userIDs(users []domain.User) []string {
+	ids := make([]string, 0, len(users))
+	for _, user := range users {
+		ids = append(ids, user.UserID)
+	}
+	return ids
+}
+
+func reviewerIDs(reviewers []domain.PullRequestReviewer) []string {
+	ids := make([]string, 0, len(reviewers))
+	for _, reviewer := range reviewers {
+		ids = append(ids, reviewer.UserID)
+	}
+	return ids
+}
+
+func pullRequestIDs(pullrequests []domain.PullRequest) []string {
+	ids := make([]string, 0, len(pullrequests))
+	for _, pullrequest := range pullrequests {
+		ids = append(ids, pullrequest.PullRequestID)
+	}
+	return ids
+}
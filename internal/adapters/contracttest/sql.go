@@ -0,0 +1,47 @@
+package contracttest
+
+import (
+	"context"
+	"io/fs"
+	"pullrequests/internal/adapters/sqlstore"
+	"pullrequests/internal/domain"
+	"pullrequests/internal/migrate"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// SQLRepositories returns the sqlstore repositories for dialect.
+func SQLRepositories(db *sqlx.DB, dialect sqlstore.Dialect, trm domain.TransactionManager) Repositories {
+	return Repositories{
+		Team:         sqlstore.NewTeamRepo(db, dialect),
+		User:         sqlstore.NewUserRepo(db, dialect),
+		PullRequest:  sqlstore.NewPRRepo(db, dialect),
+		History:      sqlstore.NewReviewHistoryRepo(db, dialect),
+		Idempotency:  sqlstore.NewIdempotencyRepo(db, dialect),
+		Token:        sqlstore.NewAPITokenRepo(db, dialect),
+		Webhook:      sqlstore.NewWebhookRepo(db, dialect),
+		Outbox:       sqlstore.NewOutboxRepo(db, dialect),
+		Job:          sqlstore.NewJobRepo(db, dialect),
+		Identity:     sqlstore.NewIdentityRepo(db, dialect),
+		Notification: sqlstore.NewNotificationRepo(db, dialect),
+		SLA:          sqlstore.NewReviewSLARepo(db, dialect),
+		TM:           trm,
+	}
+}
+
+// Migrate applies the migrations found in dir of fsys.
+func Migrate(t *testing.T, db *sqlx.DB, dialect migrate.Dialect, fsys fs.FS, dir string) {
+	t.Helper()
+	sub, err := fs.Sub(fsys, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := migrate.Load(sub)
+	if err != nil {
+		t.Fatalf("load migrations: %v", err)
+	}
+	if _, err := migrate.NewRunner(db, dialect, loaded).Up(context.Background()); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+}
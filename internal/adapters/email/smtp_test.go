@@ -0,0 +1,135 @@
+package email_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"mime"
+	"net"
+	"net/mail"
+	"pullrequests/internal/adapters/email"
+	"pullrequests/internal/adapters/email/smtptest"
+	"pullrequests/internal/domain"
+	"slices"
+	"testing"
+	"time"
+)
+
+func newSender(server *smtptest.Server, username, password string) *email.SMTPSender {
+	return email.NewSMTPSender(email.SMTPConfig{
+		Host:     server.Host,
+		Port:     server.Port,
+		Username: username,
+		Password: password,
+		From:     "reviews@example.com",
+		StartTLS: true,
+		Timeout:  5 * time.Second,
+	})
+}
+
+func TestSMTPSenderSend(t *testing.T) {
+	server := smtptest.NewServer(t)
+	server.SetUser("digest", "s3cret")
+
+	err := newSender(server, "digest", "s3cret").Send(context.Background(), &domain.EmailMessage{
+		To:      "alice@example.com",
+		Subject: "2 PR ждут ревью",
+		// The line starting with a dot must survive dot-stuffing.
+		Body: "Hi alice,\n\n.gitignore cleanup (pr-1)\r\nBilling export (pr-2)\n",
+	})
+	if err != nil {
+		t.Fatalf("send: %v", err)
+	}
+
+	messages := server.Messages()
+	if len(messages) != 1 {
+		t.Fatalf("server got %d messages, want 1", len(messages))
+	}
+	got := messages[0]
+	if got.From != "reviews@example.com" || !slices.Equal(got.To, []string{"alice@example.com"}) || got.Username != "digest" {
+		t.Errorf("got envelope from %q to %v as %q", got.From, got.To, got.Username)
+	}
+
+	message, err := mail.ReadMessage(bytes.NewReader(got.Data))
+	if err != nil {
+		t.Fatalf("parse message: %v", err)
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(message.Header.Get("Subject"))
+	if err != nil || subject != "2 PR ждут ревью" {
+		t.Errorf("got subject %q (%v)", subject, err)
+	}
+	headers := map[string]string{
+		"From":         "reviews@example.com",
+		"To":           "alice@example.com",
+		"Content-Type": "text/plain; charset=utf-8",
+	}
+	for name, want := range headers {
+		if got := message.Header.Get(name); got != want {
+			t.Errorf("header %s = %q, want %q", name, got, want)
+		}
+	}
+	if _, err := message.Header.Date(); err != nil {
+		t.Errorf("date header: %v", err)
+	}
+	body, _ := io.ReadAll(message.Body)
+	if want := "Hi alice,\n\n.gitignore cleanup (pr-1)\nBilling export (pr-2)\n"; string(body) != want {
+		t.Errorf("got body %q, want %q", body, want)
+	}
+}
+
+func TestSMTPSenderErrors(t *testing.T) {
+	message := &domain.EmailMessage{To: "alice@example.com", Subject: "Digest", Body: "Hi"}
+
+	tests := []struct {
+		name     string
+		username string
+		password string
+		reject   bool
+	}{
+		{name: "wrong password", username: "digest", password: "wrong"},
+		{name: "no credentials"},
+		{name: "recipient rejected", username: "digest", password: "s3cret", reject: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := smtptest.NewServer(t)
+			server.SetUser("digest", "s3cret")
+			if test.reject {
+				server.RejectRecipient(message.To)
+			}
+			if err := newSender(server, test.username, test.password).Send(context.Background(), message); err == nil {
+				t.Fatal("send succeeded")
+			}
+			if got := len(server.Messages()); got != 0 {
+				t.Errorf("server accepted %d messages", got)
+			}
+		})
+	}
+}
+
+func TestSMTPSenderTimeout(t *testing.T) {
+	// A server that accepts the connection but never greets.
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer listener.Close()
+	go func() {
+		conn, err := listener.Accept()
+		if err == nil {
+			defer conn.Close()
+			io.Copy(io.Discard, conn)
+		}
+	}()
+
+	addr := listener.Addr().(*net.TCPAddr)
+	sender := email.NewSMTPSender(email.SMTPConfig{Host: addr.IP.String(), Port: addr.Port, From: "reviews@example.com", Timeout: 200 * time.Millisecond})
+	start := time.Now()
+	err = sender.Send(context.Background(), &domain.EmailMessage{To: "alice@example.com", Subject: "Digest", Body: "Hi"})
+	if err == nil {
+		t.Fatal("send to a silent server succeeded")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("send gave up after %v, want about the 200ms timeout", elapsed)
+	}
+}
@@ -0,0 +1,246 @@
+// Package smtptest is a minimal SMTP server for tests. Like httptest, it
+// listens on a loopback port and records what clients send to it.
+package smtptest
+
+import (
+	"bytes"
+	"encoding/base64"
+	"net"
+	"net/textproto"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// Message is one accepted mail transaction.
+type Message struct {
+	From string
+	To   []string
+	// Data is the message as sent after DATA, dot-stuffing removed and with
+	// "\n" line endings.
+	Data []byte
+	// Username is the identity of AUTH PLAIN, empty without authentication.
+	Username string
+}
+
+type Server struct {
+	Host string
+	Port int
+
+	listener net.Listener
+	wg       sync.WaitGroup
+
+	mu       sync.Mutex
+	closed   bool
+	conns    map[net.Conn]struct{}
+	messages []Message
+	users    map[string]string
+	rejected map[string]bool
+}
+
+// NewServer starts a server that accepts any message; it is closed when the
+// test ends.
+func NewServer(t *testing.T) *Server {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("smtptest: listen: %v", err)
+	}
+	addr := listener.Addr().(*net.TCPAddr)
+	s := &Server{
+		Host:     addr.IP.String(),
+		Port:     addr.Port,
+		listener: listener,
+		conns:    make(map[net.Conn]struct{}),
+		users:    make(map[string]string),
+		rejected: make(map[string]bool),
+	}
+	s.wg.Add(1)
+	go s.serve()
+	t.Cleanup(s.Close)
+	return s
+}
+
+// SetUser adds an AUTH PLAIN account. Once a user is set, MAIL is refused
+// until the client has authenticated.
+func (s *Server) SetUser(username, password string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.users[username] = password
+}
+
+// RejectRecipient makes RCPT TO fail with 550 for address.
+func (s *Server) RejectRecipient(address string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rejected[strings.ToLower(address)] = true
+}
+
+func (s *Server) Messages() []Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.messages)
+}
+
+func (s *Server) Close() {
+	s.listener.Close()
+	s.mu.Lock()
+	s.closed = true
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.mu.Unlock()
+	s.wg.Wait()
+}
+
+func (s *Server) serve() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		if s.closed {
+			s.mu.Unlock()
+			conn.Close()
+			return
+		}
+		s.conns[conn] = struct{}{}
+		s.mu.Unlock()
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.handle(conn)
+			s.mu.Lock()
+			delete(s.conns, conn)
+			s.mu.Unlock()
+			conn.Close()
+		}()
+	}
+}
+
+func (s *Server) handle(conn net.Conn) {
+	text := textproto.NewConn(conn)
+	if err := text.PrintfLine("220 smtptest ESMTP"); err != nil {
+		return
+	}
+
+	var message *Message
+	username := ""
+	for {
+		line, err := text.ReadLine()
+		if err != nil {
+			return
+		}
+		verb, arg, _ := strings.Cut(line, " ")
+		var reply string
+		switch strings.ToUpper(verb) {
+		case "EHLO":
+			if s.authRequired() {
+				text.PrintfLine("250-smtptest")
+				reply = "250 AUTH PLAIN"
+			} else {
+				reply = "250 smtptest"
+			}
+		case "HELO", "NOOP":
+			reply = "250 OK"
+		case "AUTH":
+			reply = s.auth(arg, &username)
+		case "MAIL":
+			if s.authRequired() && username == "" {
+				reply = "530 Authentication required"
+				break
+			}
+			message = &Message{From: address(arg, "FROM:"), Username: username}
+			reply = "250 OK"
+		case "RCPT":
+			if message == nil {
+				reply = "503 MAIL first"
+				break
+			}
+			to := address(arg, "TO:")
+			if s.isRejected(to) {
+				reply = "550 No such user"
+				break
+			}
+			message.To = append(message.To, to)
+			reply = "250 OK"
+		case "DATA":
+			if message == nil || len(message.To) == 0 {
+				reply = "503 RCPT first"
+				break
+			}
+			if err := text.PrintfLine("354 End data with <CR><LF>.<CR><LF>"); err != nil {
+				return
+			}
+			data, err := text.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			message.Data = data
+			s.mu.Lock()
+			s.messages = append(s.messages, *message)
+			s.mu.Unlock()
+			message = nil
+			reply = "250 OK"
+		case "RSET":
+			message = nil
+			reply = "250 OK"
+		case "QUIT":
+			text.PrintfLine("221 Bye")
+			return
+		default:
+			reply = "502 Command not implemented"
+		}
+		if err := text.PrintfLine("%s", reply); err != nil {
+			return
+		}
+	}
+}
+
+// auth checks an AUTH PLAIN initial response and records the username.
+func (s *Server) auth(arg string, username *string) string {
+	mechanism, response, _ := strings.Cut(arg, " ")
+	if !strings.EqualFold(mechanism, "PLAIN") {
+		return "504 Unrecognized authentication type"
+	}
+	decoded, err := base64.StdEncoding.DecodeString(response)
+	if err != nil {
+		return "501 Malformed response"
+	}
+	parts := bytes.Split(decoded, []byte{0})
+	if len(parts) != 3 {
+		return "501 Malformed response"
+	}
+	s.mu.Lock()
+	password, ok := s.users[string(parts[1])]
+	s.mu.Unlock()
+	if !ok || password != string(parts[2]) {
+		return "535 Authentication credentials invalid"
+	}
+	*username = string(parts[1])
+	return "235 Authentication successful"
+}
+
+func (s *Server) authRequired() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.users) > 0
+}
+
+func (s *Server) isRejected(address string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.rejected[strings.ToLower(address)]
+}
+
+// address extracts the path of "FROM:<a@b> BODY=8BITMIME".
+func address(arg, prefix string) string {
+	if len(arg) < len(prefix) || !strings.EqualFold(arg[:len(prefix)], prefix) {
+		return ""
+	}
+	path, _, _ := strings.Cut(strings.TrimSpace(arg[len(prefix):]), " ")
+	return strings.Trim(path, "<>")
+}
@@ -0,0 +1,102 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"pullrequests/internal/domain"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// pullRequestIDPattern matches IDs created by the webhook integration: owner/repo#number.
+var pullRequestIDPattern = regexp.MustCompile(`^([\w.-]+)/([\w.-]+)#(\d+)$`)
+
+// Client makes a single request per call; failed calls are retried by the
+// job queue that runs them.
+type Client struct {
+	baseURL string
+	token   string
+	client  *http.Client
+}
+
+func NewClient(baseURL, token string, client *http.Client) *Client {
+	return &Client{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		token:   token,
+		client:  client,
+	}
+}
+
+func (c *Client) Provider() string {
+	return domain.ProviderGitHub
+}
+
+func (c *Client) Supports(pullRequestID string) bool {
+	return pullRequestIDPattern.MatchString(pullRequestID)
+}
+
+func (c *Client) RequestReviewers(ctx context.Context, pullRequestID string, logins []string) error {
+	return c.changeReviewers(ctx, http.MethodPost, pullRequestID, logins)
+}
+
+func (c *Client) RemoveReviewers(ctx context.Context, pullRequestID string, logins []string) error {
+	return c.changeReviewers(ctx, http.MethodDelete, pullRequestID, logins)
+}
+
+func (c *Client) changeReviewers(ctx context.Context, method, pullRequestID string, logins []string) error {
+	match := pullRequestIDPattern.FindStringSubmatch(pullRequestID)
+	if match == nil {
+		return fmt.Errorf("not a github pull request id: %q", pullRequestID)
+	}
+	url := fmt.Sprintf("%s/repos/%s/%s/pulls/%s/requested_reviewers", c.baseURL, match[1], match[2], match[3])
+
+	body, err := json.Marshal(map[string][]string{"reviewers": logins})
+	if err != nil {
+		return err
+	}
+	return c.do(ctx, method, url, body)
+}
+
+// do sends the request. 5xx and 429 responses are reported as
+// VCSUnavailableError with the Retry-After delay, other failures as
+// VCSRejectedError.
+func (c *Client) do(ctx context.Context, method, url string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	message, _ := io.ReadAll(io.LimitReader(resp.Body, 4<<10))
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return &domain.VCSUnavailableError{StatusCode: resp.StatusCode, RetryAfter: retryAfter(resp)}
+	default:
+		return &domain.VCSRejectedError{StatusCode: resp.StatusCode, Message: strings.TrimSpace(string(message))}
+	}
+}
+
+func retryAfter(resp *http.Response) time.Duration {
+	seconds, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+	if err != nil || seconds <= 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
@@ -0,0 +1,95 @@
+package github_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"pullrequests/internal/adapters/github"
+	"pullrequests/internal/adapters/github/githubtest"
+	"pullrequests/internal/domain"
+	"slices"
+	"testing"
+	"time"
+)
+
+func newClient(server *githubtest.Server) *github.Client {
+	return github.NewClient(server.URL+"/", "ghp_test", &http.Client{Timeout: 5 * time.Second})
+}
+
+func TestClientChangesReviewers(t *testing.T) {
+	ctx := context.Background()
+	server := githubtest.NewServer(t)
+	client := newClient(server)
+
+	if err := client.RequestReviewers(ctx, "acme/billing#42", []string{"octo-bob"}); err != nil {
+		t.Fatalf("request reviewers: %v", err)
+	}
+	if err := client.RemoveReviewers(ctx, "acme/billing#42", []string{"octo-carol"}); err != nil {
+		t.Fatalf("remove reviewers: %v", err)
+	}
+
+	requests := server.Requests()
+	want := []githubtest.Request{
+		{Method: http.MethodPost, Path: "/repos/acme/billing/pulls/42/requested_reviewers", Reviewers: []string{"octo-bob"}},
+		{Method: http.MethodDelete, Path: "/repos/acme/billing/pulls/42/requested_reviewers", Reviewers: []string{"octo-carol"}},
+	}
+	if len(requests) != len(want) {
+		t.Fatalf("server got %d requests, want %d", len(requests), len(want))
+	}
+	for i, request := range requests {
+		if request.Method != want[i].Method || request.Path != want[i].Path || !slices.Equal(request.Reviewers, want[i].Reviewers) {
+			t.Errorf("request %d: got %s %s %v, want %s %s %v", i, request.Method, request.Path, request.Reviewers,
+				want[i].Method, want[i].Path, want[i].Reviewers)
+		}
+		if got := request.Header.Get("Authorization"); got != "Bearer ghp_test" {
+			t.Errorf("request %d: authorization = %q", i, got)
+		}
+		if got := request.Header.Get("X-GitHub-Api-Version"); got == "" {
+			t.Errorf("request %d: no API version header", i)
+		}
+	}
+
+	if client.Supports("group/project!7") {
+		t.Error("client supports a GitLab merge request ID")
+	}
+	if err := client.RequestReviewers(ctx, "pr-1", []string{"octo-bob"}); err == nil {
+		t.Error("request for a pull request ID not from GitHub succeeded")
+	}
+}
+
+func TestClientErrors(t *testing.T) {
+	tests := []struct {
+		name           string
+		response       githubtest.Response
+		wantRejected   bool
+		wantRetryAfter time.Duration
+	}{
+		{name: "server error", response: githubtest.Response{Status: http.StatusBadGateway, Message: "Server Error"}},
+		{name: "rate limited", response: githubtest.Response{Status: http.StatusTooManyRequests, RetryAfter: "30", Message: "API rate limit exceeded"}, wantRetryAfter: 30 * time.Second},
+		{name: "unparsable retry after", response: githubtest.Response{Status: http.StatusServiceUnavailable, RetryAfter: "soon"}},
+		{name: "not a collaborator", response: githubtest.Response{Status: http.StatusUnprocessableEntity, Message: "Reviews may only be requested from collaborators."}, wantRejected: true},
+		{name: "not found", response: githubtest.Response{Status: http.StatusNotFound, Message: "Not Found"}, wantRejected: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := githubtest.NewServer(t)
+			server.Respond(test.response)
+
+			err := newClient(server).RequestReviewers(context.Background(), "acme/billing#42", []string{"octo-bob"})
+			var rejected *domain.VCSRejectedError
+			var unavailable *domain.VCSUnavailableError
+			switch {
+			case test.wantRejected:
+				if !errors.As(err, &rejected) || rejected.StatusCode != test.response.Status || rejected.Message == "" {
+					t.Fatalf("got %v, want VCSRejectedError with status %d and the message", err, test.response.Status)
+				}
+			case !errors.As(err, &unavailable) || unavailable.StatusCode != test.response.Status || unavailable.RetryAfter != test.wantRetryAfter:
+				t.Fatalf("got %#v, want VCSUnavailableError with status %d and Retry-After %v", err, test.response.Status, test.wantRetryAfter)
+			}
+			// Retrying is left to the job queue.
+			if got := len(server.Requests()); got != 1 {
+				t.Errorf("server got %d requests, want 1", got)
+			}
+		})
+	}
+}
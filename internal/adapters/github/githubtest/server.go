@@ -0,0 +1,118 @@
+// Package githubtest is a fake of the GitHub REST endpoints the reviewer sync
+// calls. It records requests and answers with queued responses.
+package githubtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+var requestedReviewersPath = regexp.MustCompile(`^/repos/[\w.-]+/[\w.-]+/pulls/\d+/requested_reviewers$`)
+
+// Request is one call to requested_reviewers.
+type Request struct {
+	Method    string
+	Path      string
+	Header    http.Header
+	Reviewers []string
+}
+
+// Response replaces the success response of one request.
+type Response struct {
+	Status int
+	// RetryAfter is sent as the Retry-After header when set.
+	RetryAfter string
+	Message    string
+}
+
+type Server struct {
+	*httptest.Server
+
+	mu        sync.Mutex
+	requests  []Request
+	responses []Response
+}
+
+// NewServer starts a server that accepts every reviewer change; it is closed
+// when the test ends. Use Server.URL as the API base URL.
+func NewServer(t *testing.T) *Server {
+	t.Helper()
+	s := &Server{}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// Respond queues responses for the next requests; once they are used up the
+// server succeeds again.
+func (s *Server) Respond(responses ...Response) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.responses = append(s.responses, responses...)
+}
+
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.requests)
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	if !requestedReviewersPath.MatchString(r.URL.Path) || (r.Method != http.MethodPost && r.Method != http.MethodDelete) {
+		writeMessage(w, http.StatusNotFound, "Not Found")
+		return
+	}
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "Bearer ") {
+		writeMessage(w, http.StatusUnauthorized, "Requires authentication")
+		return
+	}
+	var body struct {
+		Reviewers []string `json:"reviewers"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeMessage(w, http.StatusBadRequest, "Problems parsing JSON")
+		return
+	}
+
+	s.mu.Lock()
+	s.requests = append(s.requests, Request{Method: r.Method, Path: r.URL.Path, Header: r.Header.Clone(), Reviewers: body.Reviewers})
+	var response *Response
+	if len(s.responses) > 0 {
+		response = &s.responses[0]
+		s.responses = s.responses[1:]
+	}
+	s.mu.Unlock()
+
+	if response != nil {
+		if response.RetryAfter != "" {
+			w.Header().Set("Retry-After", response.RetryAfter)
+		}
+		writeMessage(w, response.Status, response.Message)
+		return
+	}
+	if r.Method == http.MethodPost {
+		writeMessage(w, http.StatusCreated, "")
+		return
+	}
+	writeMessage(w, http.StatusOK, "")
+}
+
+func writeMessage(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	if message == "" {
+		fmt.Fprint(w, "{}")
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]string{
+		"message":           message,
+		"documentation_url": "https://docs.github.com/rest/pulls/review-requests",
+	})
+}
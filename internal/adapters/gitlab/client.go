@@ -0,0 +1,65 @@
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"pullrequests/internal/domain"
+	"strings"
+)
+
+// Client reads users from the GitLab REST API. Merge request webhooks only
+// carry the author's numeric ID.
+type Client struct {
+	baseURL string
+	token   string
+	client  *http.Client
+}
+
+// NewClient takes the API root, e.g. https://gitlab.com/api/v4. The token may
+// be empty when user profiles are public.
+func NewClient(baseURL, token string, client *http.Client) *Client {
+	return &Client{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		token:   token,
+		client:  client,
+	}
+}
+
+func (c *Client) LoginByID(ctx context.Context, userID int64) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/users/%d", c.baseURL, userID), nil)
+	if err != nil {
+		return "", err
+	}
+	if c.token != "" {
+		req.Header.Set("PRIVATE-TOKEN", c.token)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return "", fmt.Errorf("gitlab responded with status %d", resp.StatusCode)
+	default:
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 4<<10))
+		return "", &domain.VCSRejectedError{StatusCode: resp.StatusCode, Message: strings.TrimSpace(string(message))}
+	}
+
+	var user struct {
+		Username string `json:"username"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&user); err != nil {
+		return "", fmt.Errorf("decode gitlab user %d: %w", userID, err)
+	}
+	if user.Username == "" {
+		return "", fmt.Errorf("gitlab user %d has no username", userID)
+	}
+	return user.Username, nil
+}
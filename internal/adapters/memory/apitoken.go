@@ -0,0 +1,76 @@
+package memory
+
+import (
+	"context"
+	"pullrequests/internal/domain"
+	"sort"
+	"time"
+)
+
+type APITokenRepo struct {
+	store *Store
+}
+
+func NewAPITokenRepo(store *Store) *APITokenRepo {
+	return &APITokenRepo{store: store}
+}
+
+func (r *APITokenRepo) Add(ctx context.Context, token *domain.APIToken) error {
+	return r.store.access(ctx, func(st *state) error {
+		if _, ok := st.tokens[token.TokenID]; ok {
+			return domain.NewDomainError(domain.ErrConflictCode)
+		}
+		if token.UserID != "" {
+			if _, ok := st.users[token.UserID]; !ok {
+				return domain.NewDomainError(domain.ErrNotFoundCode)
+			}
+		}
+		st.tokens[token.TokenID] = *token
+		return nil
+	})
+}
+
+func (r *APITokenRepo) GetTokenByHash(ctx context.Context, tokenHash string) (*domain.APIToken, error) {
+	var token *domain.APIToken
+	err := r.store.access(ctx, func(st *state) error {
+		for _, existing := range st.tokens {
+			if existing.TokenHash == tokenHash {
+				token = &existing
+				return nil
+			}
+		}
+		return nil
+	})
+	return token, err
+}
+
+func (r *APITokenRepo) GetTokens(ctx context.Context) ([]domain.APIToken, error) {
+	tokens := []domain.APIToken{}
+	err := r.store.access(ctx, func(st *state) error {
+		for _, token := range st.tokens {
+			tokens = append(tokens, token)
+		}
+		return nil
+	})
+	sort.Slice(tokens, func(i, j int) bool {
+		if !tokens[i].CreatedAt.Equal(tokens[j].CreatedAt) {
+			return tokens[i].CreatedAt.Before(tokens[j].CreatedAt)
+		}
+		return tokens[i].TokenID < tokens[j].TokenID
+	})
+	return tokens, err
+}
+
+func (r *APITokenRepo) RevokeToken(ctx context.Context, tokenID string, revokedAt time.Time) error {
+	return r.store.access(ctx, func(st *state) error {
+		token, ok := st.tokens[tokenID]
+		if !ok {
+			return domain.NewDomainError(domain.ErrNotFoundCode)
+		}
+		if token.RevokedAt == nil {
+			token.RevokedAt = &revokedAt
+			st.tokens[tokenID] = token
+		}
+		return nil
+	})
+}
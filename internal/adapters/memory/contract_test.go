@@ -0,0 +1,28 @@
+package memory_test
+
+import (
+	"pullrequests/internal/adapters/contracttest"
+	"pullrequests/internal/adapters/memory"
+	"testing"
+)
+
+func TestContract(t *testing.T) {
+	contracttest.Run(t, func(t *testing.T) contracttest.Repositories {
+		store := memory.NewStore()
+		return contracttest.Repositories{
+			Team:         memory.NewTeamRepo(store),
+			User:         memory.NewUserRepo(store),
+			PullRequest:  memory.NewPRRepo(store),
+			History:      memory.NewReviewHistoryRepo(store),
+			Idempotency:  memory.NewIdempotencyRepo(store),
+			Token:        memory.NewAPITokenRepo(store),
+			Webhook:      memory.NewWebhookRepo(store),
+			Outbox:       memory.NewOutboxRepo(store),
+			Job:          memory.NewJobRepo(store),
+			Identity:     memory.NewIdentityRepo(store),
+			Notification: memory.NewNotificationRepo(store),
+			SLA:          memory.NewReviewSLARepo(store),
+			TM:           memory.NewTransactionManager(store),
+		}
+	})
+}
@@ -0,0 +1,61 @@
+package memory
+
+import (
+	"context"
+	"pullrequests/internal/domain"
+)
+
+type IdempotencyRepo struct {
+	store *Store
+}
+
+func NewIdempotencyRepo(store *Store) *IdempotencyRepo {
+	return &IdempotencyRepo{store: store}
+}
+
+func (r *IdempotencyRepo) Reserve(ctx context.Context, record *domain.IdempotencyRecord) (bool, error) {
+	reserved := false
+	err := r.store.access(ctx, func(st *state) error {
+		key := idempotencyKey{actorID: record.ActorID, key: record.Key, route: record.Route}
+		if _, ok := st.idempotency[key]; ok {
+			return nil
+		}
+		st.idempotency[key] = *record
+		reserved = true
+		return nil
+	})
+	return reserved, err
+}
+
+func (r *IdempotencyRepo) Get(ctx context.Context, actorID, key, route string) (*domain.IdempotencyRecord, error) {
+	var record *domain.IdempotencyRecord
+	err := r.store.access(ctx, func(st *state) error {
+		if existing, ok := st.idempotency[idempotencyKey{actorID: actorID, key: key, route: route}]; ok {
+			record = &existing
+		}
+		return nil
+	})
+	return record, err
+}
+
+func (r *IdempotencyRepo) Complete(ctx context.Context, record *domain.IdempotencyRecord) error {
+	return r.store.access(ctx, func(st *state) error {
+		key := idempotencyKey{actorID: record.ActorID, key: record.Key, route: record.Route}
+		existing, ok := st.idempotency[key]
+		if !ok {
+			return nil
+		}
+		existing.StatusCode = record.StatusCode
+		existing.Headers = record.Headers
+		existing.ResponseBody = record.ResponseBody
+		st.idempotency[key] = existing
+		return nil
+	})
+}
+
+func (r *IdempotencyRepo) Delete(ctx context.Context, actorID, key, route string) error {
+	return r.store.access(ctx, func(st *state) error {
+		delete(st.idempotency, idempotencyKey{actorID: actorID, key: key, route: route})
+		return nil
+	})
+}
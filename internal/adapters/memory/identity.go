@@ -0,0 +1,85 @@
+package memory
+
+import (
+	"context"
+	"pullrequests/internal/domain"
+	"sort"
+)
+
+type IdentityRepo struct {
+	store *Store
+}
+
+func NewIdentityRepo(store *Store) *IdentityRepo {
+	return &IdentityRepo{store: store}
+}
+
+func (r *IdentityRepo) SetIdentity(ctx context.Context, identity *domain.ExternalIdentity) error {
+	return r.store.access(ctx, func(st *state) error {
+		if _, ok := st.users[identity.UserID]; !ok {
+			return domain.NewDomainError(domain.ErrNotFoundCode)
+		}
+		key := identityKey{provider: identity.Provider, login: identity.Login}
+		if existing, ok := st.identities[key]; ok {
+			existing.UserID = identity.UserID
+			st.identities[key] = existing
+			return nil
+		}
+		st.identities[key] = *identity
+		return nil
+	})
+}
+
+func (r *IdentityRepo) GetIdentity(ctx context.Context, provider, login string) (*domain.ExternalIdentity, error) {
+	var identity *domain.ExternalIdentity
+	err := r.store.access(ctx, func(st *state) error {
+		if existing, ok := st.identities[identityKey{provider: provider, login: login}]; ok {
+			identity = &existing
+		}
+		return nil
+	})
+	return identity, err
+}
+
+func (r *IdentityRepo) GetIdentityByUserID(ctx context.Context, provider, userID string) (*domain.ExternalIdentity, error) {
+	var identity *domain.ExternalIdentity
+	err := r.store.access(ctx, func(st *state) error {
+		for key, existing := range st.identities {
+			if key.provider != provider || existing.UserID != userID {
+				continue
+			}
+			if identity == nil || existing.Login < identity.Login {
+				identity = &existing
+			}
+		}
+		return nil
+	})
+	return identity, err
+}
+
+func (r *IdentityRepo) GetIdentities(ctx context.Context, provider string) ([]domain.ExternalIdentity, error) {
+	identities := []domain.ExternalIdentity{}
+	err := r.store.access(ctx, func(st *state) error {
+		for key, identity := range st.identities {
+			if key.provider == provider {
+				identities = append(identities, identity)
+			}
+		}
+		return nil
+	})
+	sort.Slice(identities, func(i, j int) bool {
+		return identities[i].Login < identities[j].Login
+	})
+	return identities, err
+}
+
+func (r *IdentityRepo) DeleteIdentity(ctx context.Context, provider, login string) error {
+	return r.store.access(ctx, func(st *state) error {
+		key := identityKey{provider: provider, login: login}
+		if _, ok := st.identities[key]; !ok {
+			return domain.NewDomainError(domain.ErrNotFoundCode)
+		}
+		delete(st.identities, key)
+		return nil
+	})
+}
@@ -0,0 +1,57 @@
+package memory
+
+import (
+	"context"
+	"pullrequests/internal/domain"
+	"sort"
+	"time"
+)
+
+type JobRepo struct {
+	store *Store
+}
+
+func NewJobRepo(store *Store) *JobRepo {
+	return &JobRepo{store: store}
+}
+
+func (r *JobRepo) AddJob(ctx context.Context, job *domain.Job) error {
+	return r.store.access(ctx, func(st *state) error {
+		if _, ok := st.jobs[job.JobID]; ok {
+			return domain.NewDomainError(domain.ErrConflictCode)
+		}
+		st.jobs[job.JobID] = *job
+		return nil
+	})
+}
+
+func (r *JobRepo) GetDueJobs(ctx context.Context, kind domain.JobKind, now time.Time, limit int) ([]domain.Job, error) {
+	jobs := []domain.Job{}
+	err := r.store.access(ctx, func(st *state) error {
+		for _, job := range st.jobs {
+			if job.Kind == kind && job.Status == domain.JobStatusPending && !job.NextAttemptAt.After(now) {
+				jobs = append(jobs, job)
+			}
+		}
+		return nil
+	})
+	sort.Slice(jobs, func(i, j int) bool {
+		if !jobs[i].NextAttemptAt.Equal(jobs[j].NextAttemptAt) {
+			return jobs[i].NextAttemptAt.Before(jobs[j].NextAttemptAt)
+		}
+		return jobs[i].JobID < jobs[j].JobID
+	})
+	if len(jobs) > limit {
+		jobs = jobs[:limit]
+	}
+	return jobs, err
+}
+
+func (r *JobRepo) UpdateJob(ctx context.Context, job *domain.Job) error {
+	return r.store.access(ctx, func(st *state) error {
+		if _, ok := st.jobs[job.JobID]; ok {
+			st.jobs[job.JobID] = *job
+		}
+		return nil
+	})
+}
@@ -0,0 +1,74 @@
+package memory
+
+import (
+	"context"
+	"pullrequests/internal/domain"
+	"slices"
+	"sort"
+	"time"
+)
+
+type NotificationRepo struct {
+	store *Store
+}
+
+func NewNotificationRepo(store *Store) *NotificationRepo {
+	return &NotificationRepo{store: store}
+}
+
+func (r *NotificationRepo) SetPreferences(ctx context.Context, preferences *domain.NotificationPreferences) error {
+	return r.store.access(ctx, func(st *state) error {
+		if _, ok := st.users[preferences.UserID]; !ok {
+			return domain.NewDomainError(domain.ErrNotFoundCode)
+		}
+		stored := *preferences
+		stored.ChatEvents = slices.Clone(preferences.ChatEvents)
+		stored.DigestSentAt = nil
+		if existing, ok := st.notifications[preferences.UserID]; ok {
+			stored.DigestSentAt = existing.DigestSentAt
+		}
+		st.notifications[preferences.UserID] = stored
+		return nil
+	})
+}
+
+func (r *NotificationRepo) GetPreferences(ctx context.Context, userID string) (*domain.NotificationPreferences, error) {
+	var preferences *domain.NotificationPreferences
+	err := r.store.access(ctx, func(st *state) error {
+		if existing, ok := st.notifications[userID]; ok {
+			existing.ChatEvents = slices.Clone(existing.ChatEvents)
+			preferences = &existing
+		}
+		return nil
+	})
+	return preferences, err
+}
+
+func (r *NotificationRepo) GetDigestRecipients(ctx context.Context) ([]domain.NotificationPreferences, error) {
+	recipients := []domain.NotificationPreferences{}
+	err := r.store.access(ctx, func(st *state) error {
+		for _, preferences := range st.notifications {
+			if preferences.DigestEnabled && preferences.Email != "" {
+				preferences.ChatEvents = slices.Clone(preferences.ChatEvents)
+				recipients = append(recipients, preferences)
+			}
+		}
+		return nil
+	})
+	sort.Slice(recipients, func(i, j int) bool {
+		return recipients[i].UserID < recipients[j].UserID
+	})
+	return recipients, err
+}
+
+func (r *NotificationRepo) SetDigestSentAt(ctx context.Context, userID string, sentAt *time.Time) error {
+	return r.store.access(ctx, func(st *state) error {
+		preferences, ok := st.notifications[userID]
+		if !ok {
+			return domain.NewDomainError(domain.ErrNotFoundCode)
+		}
+		preferences.DigestSentAt = sentAt
+		st.notifications[userID] = preferences
+		return nil
+	})
+}
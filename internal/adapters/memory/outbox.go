@@ -0,0 +1,65 @@
+package memory
+
+import (
+	"context"
+	"pullrequests/internal/domain"
+	"slices"
+	"sort"
+	"time"
+)
+
+type OutboxRepo struct {
+	store *Store
+}
+
+func NewOutboxRepo(store *Store) *OutboxRepo {
+	return &OutboxRepo{store: store}
+}
+
+func (r *OutboxRepo) Add(ctx context.Context, message *domain.OutboxMessage) error {
+	return r.store.access(ctx, func(st *state) error {
+		if _, ok := st.outbox[message.EventID]; ok {
+			return domain.NewDomainError(domain.ErrConflictCode)
+		}
+		st.outboxSequence++
+		stored := *message
+		stored.Sequence = st.outboxSequence
+		stored.DeliveredSinks = slices.Clone(message.DeliveredSinks)
+		st.outbox[message.EventID] = stored
+		return nil
+	})
+}
+
+func (r *OutboxRepo) GetPending(ctx context.Context, now time.Time, limit int) ([]domain.OutboxMessage, error) {
+	messages := []domain.OutboxMessage{}
+	err := r.store.access(ctx, func(st *state) error {
+		for _, message := range st.outbox {
+			if message.Status == domain.OutboxStatusPending && !message.NextAttemptAt.After(now) {
+				messages = append(messages, message)
+			}
+		}
+		return nil
+	})
+	sort.Slice(messages, func(i, j int) bool {
+		return messages[i].Sequence < messages[j].Sequence
+	})
+	if len(messages) > limit {
+		messages = messages[:limit]
+	}
+	return messages, err
+}
+
+func (r *OutboxRepo) Update(ctx context.Context, message *domain.OutboxMessage) error {
+	return r.store.access(ctx, func(st *state) error {
+		if stored, ok := st.outbox[message.EventID]; ok {
+			stored.Status = message.Status
+			stored.Attempts = message.Attempts
+			stored.NextAttemptAt = message.NextAttemptAt
+			stored.LastError = message.LastError
+			stored.ProcessedAt = message.ProcessedAt
+			stored.DeliveredSinks = slices.Clone(message.DeliveredSinks)
+			st.outbox[message.EventID] = stored
+		}
+		return nil
+	})
+}
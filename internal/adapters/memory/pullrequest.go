@@ -0,0 +1,169 @@
+package memory
+
+import (
+	"context"
+	"pullrequests/internal/domain"
+	"slices"
+	"sort"
+)
+
+type PRRepo struct {
+	store *Store
+}
+
+func NewPRRepo(store *Store) *PRRepo {
+	return &PRRepo{store: store}
+}
+
+func (r *PRRepo) Add(ctx context.Context, pullrequest *domain.PullRequest) error {
+	return r.store.access(ctx, func(st *state) error {
+		if _, ok := st.prs[pullrequest.PullRequestID]; ok {
+			return domain.NewDomainError(domain.ErrPRExistsCode)
+		}
+		if _, ok := st.users[pullrequest.AuthorID]; !ok {
+			return domain.NewDomainError(domain.ErrNotFoundCode)
+		}
+		pullrequest.Version = 1
+		st.prs[pullrequest.PullRequestID] = *pullrequest
+		return nil
+	})
+}
+
+func (r *PRRepo) GetPullRequestByID(ctx context.Context, pullrequestID string) (*domain.PullRequest, error) {
+	var pullrequest *domain.PullRequest
+	err := r.store.access(ctx, func(st *state) error {
+		if existing, ok := st.prs[pullrequestID]; ok {
+			pullrequest = &existing
+		}
+		return nil
+	})
+	return pullrequest, err
+}
+
+func (r *PRRepo) UpdatePullRequest(ctx context.Context, pullrequest *domain.PullRequest) error {
+	return r.store.access(ctx, func(st *state) error {
+		if err := r.bumpVersion(st, pullrequest); err != nil {
+			return err
+		}
+		st.prs[pullrequest.PullRequestID] = *pullrequest
+		return nil
+	})
+}
+
+func (r *PRRepo) AddReviewer(ctx context.Context, pullrequest *domain.PullRequest, userID string) error {
+	return r.store.access(ctx, func(st *state) error {
+		if _, ok := st.users[userID]; !ok {
+			return domain.NewDomainError(domain.ErrNotFoundCode)
+		}
+		if slices.Contains(st.reviewers[pullrequest.PullRequestID], userID) {
+			return domain.NewDomainError(domain.ErrConflictCode)
+		}
+		if err := r.bumpVersion(st, pullrequest); err != nil {
+			return err
+		}
+		st.reviewers[pullrequest.PullRequestID] = append(st.reviewers[pullrequest.PullRequestID], userID)
+		return nil
+	})
+}
+
+func (r *PRRepo) RemoveReviewer(ctx context.Context, pullrequest *domain.PullRequest, userID string) error {
+	return r.store.access(ctx, func(st *state) error {
+		if err := r.bumpVersion(st, pullrequest); err != nil {
+			return err
+		}
+		st.reviewers[pullrequest.PullRequestID] = slices.DeleteFunc(
+			st.reviewers[pullrequest.PullRequestID],
+			func(reviewerID string) bool { return reviewerID == userID },
+		)
+		return nil
+	})
+}
+
+func (r *PRRepo) GetReviewers(ctx context.Context, pullrequestID string) ([]domain.PullRequestReviewer, error) {
+	var reviewers []domain.PullRequestReviewer
+	err := r.store.access(ctx, func(st *state) error {
+		userIDs := slices.Clone(st.reviewers[pullrequestID])
+		slices.Sort(userIDs)
+		for _, userID := range userIDs {
+			reviewers = append(reviewers, domain.PullRequestReviewer{
+				PullRequestID: pullrequestID,
+				UserID:        userID,
+			})
+		}
+		return nil
+	})
+	return reviewers, err
+}
+
+func (r *PRRepo) GetUserAssignedPRs(ctx context.Context, userID string) ([]domain.PullRequest, error) {
+	var pullrequests []domain.PullRequest
+	err := r.store.access(ctx, func(st *state) error {
+		for pullrequestID, reviewerIDs := range st.reviewers {
+			if slices.Contains(reviewerIDs, userID) {
+				pullrequests = append(pullrequests, st.prs[pullrequestID])
+			}
+		}
+		return nil
+	})
+	sort.Slice(pullrequests, func(i, j int) bool {
+		return pullrequests[i].CreatedAt.After(pullrequests[j].CreatedAt)
+	})
+	return pullrequests, err
+}
+
+func (r *PRRepo) GetReviewerStatsByTeamName(ctx context.Context, teamName string) ([]domain.ReviewerStat, error) {
+	stats := []domain.ReviewerStat{}
+	err := r.store.access(ctx, func(st *state) error {
+		for _, user := range sortedUsers(st) {
+			if user.TeamName != teamName {
+				continue
+			}
+			stat := domain.ReviewerStat{
+				UserID:   user.UserID,
+				Username: user.Username,
+				Role:     user.Role,
+			}
+			for pullrequestID, reviewerIDs := range st.reviewers {
+				if !slices.Contains(reviewerIDs, user.UserID) {
+					continue
+				}
+				stat.Assignments++
+				if st.prs[pullrequestID].Status == domain.PRStatusOpen {
+					stat.OpenAssignments++
+				}
+			}
+			for _, assignment := range st.history {
+				if assignment.ReviewerID == user.UserID && assignment.Escalated {
+					stat.Escalations++
+				}
+			}
+			stats = append(stats, stat)
+		}
+		return nil
+	})
+	return stats, err
+}
+
+func (r *PRRepo) CountOpenByTeam(ctx context.Context) (map[string]int, error) {
+	counts := make(map[string]int)
+	err := r.store.access(ctx, func(st *state) error {
+		for _, pullrequest := range st.prs {
+			if pullrequest.Status == domain.PRStatusOpen {
+				counts[st.users[pullrequest.AuthorID].TeamName]++
+			}
+		}
+		return nil
+	})
+	return counts, err
+}
+
+func (r *PRRepo) bumpVersion(st *state, pullrequest *domain.PullRequest) error {
+	existing, ok := st.prs[pullrequest.PullRequestID]
+	if !ok || existing.Version != pullrequest.Version {
+		return domain.NewDomainError(domain.ErrConflictCode)
+	}
+	pullrequest.Version++
+	existing.Version = pullrequest.Version
+	st.prs[pullrequest.PullRequestID] = existing
+	return nil
+}
@@ -0,0 +1,38 @@
+package memory
+
+import (
+	"context"
+	"pullrequests/internal/domain"
+	"time"
+)
+
+type ReviewHistoryRepo struct {
+	store *Store
+}
+
+func NewReviewHistoryRepo(store *Store) *ReviewHistoryRepo {
+	return &ReviewHistoryRepo{store: store}
+}
+
+func (r *ReviewHistoryRepo) Add(ctx context.Context, assignment *domain.ReviewAssignment) error {
+	return r.store.access(ctx, func(st *state) error {
+		st.history = append(st.history, *assignment)
+		return nil
+	})
+}
+
+func (r *ReviewHistoryRepo) GetLastAssignedAtByAuthor(ctx context.Context, authorID string) (map[string]time.Time, error) {
+	lastAssignedAt := make(map[string]time.Time)
+	err := r.store.access(ctx, func(st *state) error {
+		for _, assignment := range st.history {
+			if assignment.AuthorID != authorID {
+				continue
+			}
+			if last, ok := lastAssignedAt[assignment.ReviewerID]; !ok || assignment.AssignedAt.After(last) {
+				lastAssignedAt[assignment.ReviewerID] = assignment.AssignedAt
+			}
+		}
+		return nil
+	})
+	return lastAssignedAt, err
+}
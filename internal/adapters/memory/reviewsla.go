@@ -0,0 +1,107 @@
+package memory
+
+import (
+	"context"
+	"pullrequests/internal/domain"
+	"sort"
+	"time"
+)
+
+type ReviewSLARepo struct {
+	store *Store
+}
+
+func NewReviewSLARepo(store *Store) *ReviewSLARepo {
+	return &ReviewSLARepo{store: store}
+}
+
+func (r *ReviewSLARepo) SetSLA(ctx context.Context, sla *domain.ReviewSLA) error {
+	return r.store.access(ctx, func(st *state) error {
+		if _, ok := st.teams[sla.TeamName]; !ok {
+			return domain.NewDomainError(domain.ErrNotFoundCode)
+		}
+		st.slas[sla.TeamName] = *sla
+		return nil
+	})
+}
+
+func (r *ReviewSLARepo) GetSLA(ctx context.Context, teamName string) (*domain.ReviewSLA, error) {
+	var sla *domain.ReviewSLA
+	err := r.store.access(ctx, func(st *state) error {
+		if existing, ok := st.slas[teamName]; ok {
+			sla = &existing
+		}
+		return nil
+	})
+	return sla, err
+}
+
+func (r *ReviewSLARepo) GetSLAs(ctx context.Context) ([]domain.ReviewSLA, error) {
+	slas := []domain.ReviewSLA{}
+	err := r.store.access(ctx, func(st *state) error {
+		for _, sla := range st.slas {
+			slas = append(slas, sla)
+		}
+		return nil
+	})
+	sort.Slice(slas, func(i, j int) bool {
+		return slas[i].TeamName < slas[j].TeamName
+	})
+	return slas, err
+}
+
+func (r *ReviewSLARepo) GetOpenReviews(ctx context.Context, createdBefore time.Time) ([]domain.OpenReview, error) {
+	reviews := []domain.OpenReview{}
+	err := r.store.access(ctx, func(st *state) error {
+		for _, pullrequest := range st.prs {
+			if pullrequest.Status != domain.PRStatusOpen || !pullrequest.CreatedAt.Before(createdBefore) {
+				continue
+			}
+			author, ok := st.users[pullrequest.AuthorID]
+			if !ok {
+				continue
+			}
+			progress := st.slaProgress[pullrequest.PullRequestID]
+			reviews = append(reviews, domain.OpenReview{
+				PullRequestID: pullrequest.PullRequestID,
+				AuthorID:      pullrequest.AuthorID,
+				TeamName:      author.TeamName,
+				CreatedAt:     pullrequest.CreatedAt,
+				RemindedAt:    progress.remindedAt,
+				EscalatedAt:   progress.escalatedAt,
+			})
+		}
+		return nil
+	})
+	sort.Slice(reviews, func(i, j int) bool {
+		if !reviews[i].CreatedAt.Equal(reviews[j].CreatedAt) {
+			return reviews[i].CreatedAt.Before(reviews[j].CreatedAt)
+		}
+		return reviews[i].PullRequestID < reviews[j].PullRequestID
+	})
+	return reviews, err
+}
+
+func (r *ReviewSLARepo) MarkReminded(ctx context.Context, pullRequestID string, at time.Time) error {
+	return r.store.access(ctx, func(st *state) error {
+		if _, ok := st.prs[pullRequestID]; !ok {
+			return domain.NewDomainError(domain.ErrNotFoundCode)
+		}
+		progress := st.slaProgress[pullRequestID]
+		progress.remindedAt = &at
+		st.slaProgress[pullRequestID] = progress
+		return nil
+	})
+}
+
+func (r *ReviewSLARepo) MarkEscalated(ctx context.Context, pullRequestID string, at time.Time) error {
+	return r.store.access(ctx, func(st *state) error {
+		if _, ok := st.prs[pullRequestID]; !ok {
+			return domain.NewDomainError(domain.ErrNotFoundCode)
+		}
+		progress := st.slaProgress[pullRequestID]
+		progress.escalatedAt = &at
+		st.slaProgress[pullRequestID] = progress
+		return nil
+	})
+}
@@ -0,0 +1,135 @@
+package memory
+
+import (
+	"context"
+	"pullrequests/internal/domain"
+	"sync"
+	"time"
+)
+
+type Store struct {
+	mu    sync.Mutex
+	state *state
+}
+
+func NewStore() *Store {
+	return &Store{state: newState()}
+}
+
+type state struct {
+	teams         map[string]domain.Team
+	users         map[string]domain.User
+	prs           map[string]domain.PullRequest
+	reviewers     map[string][]string
+	history       []domain.ReviewAssignment
+	idempotency   map[idempotencyKey]domain.IdempotencyRecord
+	tokens        map[string]domain.APIToken
+	webhooks      map[string]domain.WebhookSubscription
+	deliveries    map[string]domain.WebhookDelivery
+	outbox        map[string]domain.OutboxMessage
+	jobs          map[string]domain.Job
+	identities    map[identityKey]domain.ExternalIdentity
+	notifications map[string]domain.NotificationPreferences
+	slas          map[string]domain.ReviewSLA
+	slaProgress   map[string]slaProgress
+
+	// outboxSequence is the last sequence given to an outbox message.
+	outboxSequence int64
+}
+
+type slaProgress struct {
+	remindedAt  *time.Time
+	escalatedAt *time.Time
+}
+
+type identityKey struct {
+	provider string
+	login    string
+}
+
+type idempotencyKey struct {
+	actorID string
+	key     string
+	route   string
+}
+
+func newState() *state {
+	return &state{
+		teams:         make(map[string]domain.Team),
+		users:         make(map[string]domain.User),
+		prs:           make(map[string]domain.PullRequest),
+		reviewers:     make(map[string][]string),
+		idempotency:   make(map[idempotencyKey]domain.IdempotencyRecord),
+		tokens:        make(map[string]domain.APIToken),
+		webhooks:      make(map[string]domain.WebhookSubscription),
+		deliveries:    make(map[string]domain.WebhookDelivery),
+		outbox:        make(map[string]domain.OutboxMessage),
+		jobs:          make(map[string]domain.Job),
+		identities:    make(map[identityKey]domain.ExternalIdentity),
+		notifications: make(map[string]domain.NotificationPreferences),
+		slas:          make(map[string]domain.ReviewSLA),
+		slaProgress:   make(map[string]slaProgress),
+	}
+}
+
+func (s *state) clone() *state {
+	cloned := newState()
+	for k, v := range s.teams {
+		cloned.teams[k] = v
+	}
+	for k, v := range s.users {
+		cloned.users[k] = v
+	}
+	for k, v := range s.prs {
+		cloned.prs[k] = v
+	}
+	for k, v := range s.reviewers {
+		cloned.reviewers[k] = append([]string(nil), v...)
+	}
+	cloned.history = append([]domain.ReviewAssignment(nil), s.history...)
+	for k, v := range s.idempotency {
+		cloned.idempotency[k] = v
+	}
+	for k, v := range s.tokens {
+		cloned.tokens[k] = v
+	}
+	for k, v := range s.webhooks {
+		cloned.webhooks[k] = v
+	}
+	for k, v := range s.deliveries {
+		cloned.deliveries[k] = v
+	}
+	for k, v := range s.outbox {
+		cloned.outbox[k] = v
+	}
+	cloned.outboxSequence = s.outboxSequence
+	for k, v := range s.jobs {
+		cloned.jobs[k] = v
+	}
+	for k, v := range s.identities {
+		cloned.identities[k] = v
+	}
+	for k, v := range s.notifications {
+		cloned.notifications[k] = v
+	}
+	for k, v := range s.slas {
+		cloned.slas[k] = v
+	}
+	for k, v := range s.slaProgress {
+		cloned.slaProgress[k] = v
+	}
+	return cloned
+}
+
+type stateKey struct{}
+
+// access runs fn against the transaction snapshot from ctx, or against the
+// committed state under the store lock when called outside a transaction.
+func (s *Store) access(ctx context.Context, fn func(*state) error) error {
+	if st, ok := ctx.Value(stateKey{}).(*state); ok {
+		return fn(st)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return fn(s.state)
+}
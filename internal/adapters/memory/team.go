@@ -0,0 +1,84 @@
+package memory
+
+import (
+	"context"
+	"pullrequests/internal/domain"
+	"sort"
+)
+
+type TeamRepo struct {
+	store *Store
+}
+
+func NewTeamRepo(store *Store) *TeamRepo {
+	return &TeamRepo{store: store}
+}
+
+func (r *TeamRepo) Add(ctx context.Context, team *domain.Team) error {
+	return r.store.access(ctx, func(st *state) error {
+		if _, ok := st.teams[team.Name]; ok {
+			return domain.NewDomainError(domain.ErrTeamExistsCode)
+		}
+		st.teams[team.Name] = *team
+		return nil
+	})
+}
+
+func (r *TeamRepo) AddTeamMember(ctx context.Context, teamName string, teamMember *domain.TeamMember) error {
+	return r.store.access(ctx, func(st *state) error {
+		if _, ok := st.teams[teamName]; !ok {
+			return domain.NewDomainError(domain.ErrNotFoundCode)
+		}
+		if _, ok := st.users[teamMember.UserID]; ok {
+			return domain.NewDomainError(domain.ErrUserExistsCode)
+		}
+		st.users[teamMember.UserID] = domain.User{
+			UserID:   teamMember.UserID,
+			Username: teamMember.Username,
+			TeamName: teamName,
+			IsActive: teamMember.IsActive,
+			Role:     teamMember.Role,
+		}
+		return nil
+	})
+}
+
+func (r *TeamRepo) GetTeamByTeamName(ctx context.Context, teamName string) (*domain.Team, error) {
+	var team *domain.Team
+	err := r.store.access(ctx, func(st *state) error {
+		if existing, ok := st.teams[teamName]; ok {
+			team = &existing
+		}
+		return nil
+	})
+	return team, err
+}
+
+func (r *TeamRepo) GetTeamMembersByTeamName(ctx context.Context, teamName string) ([]domain.TeamMember, error) {
+	members := []domain.TeamMember{}
+	err := r.store.access(ctx, func(st *state) error {
+		for _, user := range sortedUsers(st) {
+			if user.TeamName == teamName {
+				members = append(members, domain.TeamMember{
+					UserID:   user.UserID,
+					Username: user.Username,
+					IsActive: user.IsActive,
+					Role:     user.Role,
+				})
+			}
+		}
+		return nil
+	})
+	return members, err
+}
+
+func sortedUsers(st *state) []domain.User {
+	users := make([]domain.User, 0, len(st.users))
+	for _, user := range st.users {
+		users = append(users, user)
+	}
+	sort.Slice(users, func(i, j int) bool {
+		return users[i].UserID < users[j].UserID
+	})
+	return users
+}
@@ -0,0 +1,46 @@
+package memory
+
+import (
+	"context"
+	"pullrequests/internal/domain"
+	"pullrequests/internal/tracing"
+)
+
+type TransactionManager struct {
+	store *Store
+}
+
+func NewTransactionManager(store *Store) *TransactionManager {
+	return &TransactionManager{store: store}
+}
+
+// Do serializes transactions and runs fn against a copy of the state that
+// replaces the committed state only when fn succeeds. A savepoint is a copy
+// of the transaction's own state.
+func (m *TransactionManager) Do(ctx context.Context, fn func(context.Context) error, opts ...domain.TxOption) (err error) {
+	options := domain.NewTxOptions(opts...)
+	outer, nested := ctx.Value(stateKey{}).(*state)
+	ctx, span := tracing.StartTransaction(ctx, options.Name, nested)
+	defer func() { tracing.End(span, err) }()
+	if nested {
+		if !options.Savepoint {
+			return fn(ctx)
+		}
+		savepoint := outer.clone()
+		if err := fn(context.WithValue(ctx, stateKey{}, savepoint)); err != nil {
+			return err
+		}
+		*outer = *savepoint
+		return nil
+	}
+
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	snapshot := m.store.state.clone()
+	if err := fn(context.WithValue(ctx, stateKey{}, snapshot)); err != nil {
+		return err
+	}
+	m.store.state = snapshot
+	return nil
+}
@@ -0,0 +1,51 @@
+package memory
+
+import (
+	"context"
+	"pullrequests/internal/domain"
+)
+
+type UserRepo struct {
+	store *Store
+}
+
+func NewUserRepo(store *Store) *UserRepo {
+	return &UserRepo{store: store}
+}
+
+func (r *UserRepo) GetUserByID(ctx context.Context, userID string) (*domain.User, error) {
+	var user *domain.User
+	err := r.store.access(ctx, func(st *state) error {
+		if existing, ok := st.users[userID]; ok {
+			user = &existing
+		}
+		return nil
+	})
+	return user, err
+}
+
+func (r *UserRepo) UpdateUser(ctx context.Context, user *domain.User) error {
+	return r.store.access(ctx, func(st *state) error {
+		if _, ok := st.users[user.UserID]; !ok {
+			return nil
+		}
+		if _, ok := st.teams[user.TeamName]; !ok {
+			return domain.NewDomainError(domain.ErrNotFoundCode)
+		}
+		st.users[user.UserID] = *user
+		return nil
+	})
+}
+
+func (r *UserRepo) GetActiveUsersByTeamName(ctx context.Context, teamName string) ([]domain.User, error) {
+	var users []domain.User
+	err := r.store.access(ctx, func(st *state) error {
+		for _, user := range sortedUsers(st) {
+			if user.TeamName == teamName && user.IsActive {
+				users = append(users, user)
+			}
+		}
+		return nil
+	})
+	return users, err
+}
@@ -0,0 +1,135 @@
+package memory
+
+import (
+	"context"
+	"pullrequests/internal/domain"
+	"sort"
+	"time"
+)
+
+type WebhookRepo struct {
+	store *Store
+}
+
+func NewWebhookRepo(store *Store) *WebhookRepo {
+	return &WebhookRepo{store: store}
+}
+
+func (r *WebhookRepo) AddSubscription(ctx context.Context, subscription *domain.WebhookSubscription) error {
+	return r.store.access(ctx, func(st *state) error {
+		if _, ok := st.webhooks[subscription.SubscriptionID]; ok {
+			return domain.NewDomainError(domain.ErrConflictCode)
+		}
+		st.webhooks[subscription.SubscriptionID] = *subscription
+		return nil
+	})
+}
+
+func (r *WebhookRepo) GetSubscription(ctx context.Context, subscriptionID string) (*domain.WebhookSubscription, error) {
+	var subscription *domain.WebhookSubscription
+	err := r.store.access(ctx, func(st *state) error {
+		if existing, ok := st.webhooks[subscriptionID]; ok {
+			subscription = &existing
+		}
+		return nil
+	})
+	return subscription, err
+}
+
+func (r *WebhookRepo) GetSubscriptions(ctx context.Context) ([]domain.WebhookSubscription, error) {
+	subscriptions := []domain.WebhookSubscription{}
+	err := r.store.access(ctx, func(st *state) error {
+		for _, subscription := range st.webhooks {
+			subscriptions = append(subscriptions, subscription)
+		}
+		return nil
+	})
+	sort.Slice(subscriptions, func(i, j int) bool {
+		if !subscriptions[i].CreatedAt.Equal(subscriptions[j].CreatedAt) {
+			return subscriptions[i].CreatedAt.Before(subscriptions[j].CreatedAt)
+		}
+		return subscriptions[i].SubscriptionID < subscriptions[j].SubscriptionID
+	})
+	return subscriptions, err
+}
+
+func (r *WebhookRepo) DeleteSubscription(ctx context.Context, subscriptionID string) error {
+	return r.store.access(ctx, func(st *state) error {
+		if _, ok := st.webhooks[subscriptionID]; !ok {
+			return domain.NewDomainError(domain.ErrNotFoundCode)
+		}
+		delete(st.webhooks, subscriptionID)
+		for id, delivery := range st.deliveries {
+			if delivery.SubscriptionID == subscriptionID {
+				delete(st.deliveries, id)
+			}
+		}
+		return nil
+	})
+}
+
+func (r *WebhookRepo) AddDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	return r.store.access(ctx, func(st *state) error {
+		if _, ok := st.webhooks[delivery.SubscriptionID]; !ok {
+			return domain.NewDomainError(domain.ErrNotFoundCode)
+		}
+		if _, ok := st.deliveries[delivery.DeliveryID]; ok {
+			return domain.NewDomainError(domain.ErrConflictCode)
+		}
+		st.deliveries[delivery.DeliveryID] = *delivery
+		return nil
+	})
+}
+
+func (r *WebhookRepo) GetDueDeliveries(ctx context.Context, now time.Time, limit int) ([]domain.WebhookDelivery, error) {
+	deliveries := []domain.WebhookDelivery{}
+	err := r.store.access(ctx, func(st *state) error {
+		for _, delivery := range st.deliveries {
+			if delivery.Status == domain.DeliveryStatusPending && !delivery.NextAttemptAt.After(now) {
+				deliveries = append(deliveries, delivery)
+			}
+		}
+		return nil
+	})
+	sort.Slice(deliveries, func(i, j int) bool {
+		if !deliveries[i].NextAttemptAt.Equal(deliveries[j].NextAttemptAt) {
+			return deliveries[i].NextAttemptAt.Before(deliveries[j].NextAttemptAt)
+		}
+		return deliveries[i].DeliveryID < deliveries[j].DeliveryID
+	})
+	if len(deliveries) > limit {
+		deliveries = deliveries[:limit]
+	}
+	return deliveries, err
+}
+
+func (r *WebhookRepo) UpdateDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	return r.store.access(ctx, func(st *state) error {
+		if _, ok := st.deliveries[delivery.DeliveryID]; ok {
+			st.deliveries[delivery.DeliveryID] = *delivery
+		}
+		return nil
+	})
+}
+
+func (r *WebhookRepo) GetDeliveries(ctx context.Context, subscriptionID string, limit int) ([]domain.WebhookDelivery, error) {
+	deliveries := []domain.WebhookDelivery{}
+	err := r.store.access(ctx, func(st *state) error {
+		for _, delivery := range st.deliveries {
+			if subscriptionID == "" || delivery.SubscriptionID == subscriptionID {
+				deliveries = append(deliveries, delivery)
+			}
+		}
+		return nil
+	})
+	sort.Slice(deliveries, func(i, j int) bool {
+		if !deliveries[i].CreatedAt.Equal(deliveries[j].CreatedAt) {
+			return deliveries[i].CreatedAt.After(deliveries[j].CreatedAt)
+		}
+		return deliveries[i].DeliveryID > deliveries[j].DeliveryID
+	})
+	if len(deliveries) > limit {
+		deliveries = deliveries[:limit]
+	}
+	return deliveries, err
+}
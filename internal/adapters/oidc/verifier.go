@@ -0,0 +1,87 @@
+package oidc
+
+import (
+	"context"
+	"fmt"
+	"pullrequests/internal/domain"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// rolePriority ranks roles so the most privileged matching group wins.
+var rolePriority = map[domain.APIRole]int{
+	domain.APIRoleReadOnly: 1,
+	domain.APIRoleUser:     2,
+	domain.APIRoleTeamLead: 3,
+	domain.APIRoleAdmin:    4,
+}
+
+type VerifierConfig struct {
+	Issuer      string
+	Audience    string
+	UserClaim   string
+	GroupsClaim string
+	GroupRoles  map[string]domain.APIRole
+	DefaultRole domain.APIRole
+}
+
+type Verifier struct {
+	keys   *JWKS
+	cfg    VerifierConfig
+	parser *jwt.Parser
+}
+
+// NewVerifier always checks the issuer and the audience, so both must be set.
+func NewVerifier(keys *JWKS, cfg VerifierConfig) *Verifier {
+	parser := jwt.NewParser(
+		jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg(), jwt.SigningMethodES256.Alg()}),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuer(cfg.Issuer),
+		jwt.WithAudience(cfg.Audience),
+	)
+	return &Verifier{keys: keys, cfg: cfg, parser: parser}
+}
+
+func (v *Verifier) Verify(ctx context.Context, raw string) (*domain.Actor, error) {
+	claims := jwt.MapClaims{}
+	_, err := v.parser.ParseWithClaims(raw, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return v.keys.Key(ctx, kid)
+	})
+	if err != nil {
+		return nil, domain.NewDomainError(domain.ErrUnauthorizedCode).WithCause(err)
+	}
+
+	userID, _ := claims[v.cfg.UserClaim].(string)
+	if userID == "" {
+		return nil, domain.NewDomainError(domain.ErrUnauthorizedCode).
+			WithCause(fmt.Errorf("claim %q is missing", v.cfg.UserClaim))
+	}
+
+	return &domain.Actor{
+		UserID: userID,
+		Role:   v.roleFor(claims[v.cfg.GroupsClaim]),
+	}, nil
+}
+
+func (v *Verifier) roleFor(groupsClaim interface{}) domain.APIRole {
+	var groups []string
+	switch value := groupsClaim.(type) {
+	case string:
+		groups = []string{value}
+	case []interface{}:
+		for _, group := range value {
+			if name, ok := group.(string); ok {
+				groups = append(groups, name)
+			}
+		}
+	}
+
+	role := v.cfg.DefaultRole
+	for _, group := range groups {
+		if mapped, ok := v.cfg.GroupRoles[group]; ok && rolePriority[mapped] > rolePriority[role] {
+			role = mapped
+		}
+	}
+	return role
+}
@@ -0,0 +1,317 @@
+package oidc_test
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"pullrequests/internal/adapters/oidc"
+	"pullrequests/internal/domain"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	testIssuer   = "https://idp.example.com"
+	testAudience = "pullrequests"
+)
+
+type signingKey struct {
+	kid    string
+	method jwt.SigningMethod
+	key    crypto.Signer
+}
+
+func newRSAKey(t *testing.T, kid string) signingKey {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate rsa key: %v", err)
+	}
+	return signingKey{kid: kid, method: jwt.SigningMethodRS256, key: key}
+}
+
+func newECKey(t *testing.T, kid string) signingKey {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate ec key: %v", err)
+	}
+	return signingKey{kid: kid, method: jwt.SigningMethodES256, key: key}
+}
+
+// jwksJSON encodes the public halves of keys the way an identity provider
+// publishes them.
+func jwksJSON(t *testing.T, keys ...signingKey) []byte {
+	t.Helper()
+	encode := func(value *big.Int, size int) string {
+		return base64.RawURLEncoding.EncodeToString(value.FillBytes(make([]byte, size)))
+	}
+
+	set := struct {
+		Keys []map[string]string `json:"keys"`
+	}{}
+	for _, key := range keys {
+		switch public := key.key.Public().(type) {
+		case *rsa.PublicKey:
+			set.Keys = append(set.Keys, map[string]string{
+				"kid": key.kid,
+				"kty": "RSA",
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(public.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes()),
+			})
+		case *ecdsa.PublicKey:
+			set.Keys = append(set.Keys, map[string]string{
+				"kid": key.kid,
+				"kty": "EC",
+				"crv": "P-256",
+				"x":   encode(public.X, 32),
+				"y":   encode(public.Y, 32),
+			})
+		}
+	}
+	data, err := json.Marshal(set)
+	if err != nil {
+		t.Fatalf("marshal jwks: %v", err)
+	}
+	return data
+}
+
+func sign(t *testing.T, key signingKey, claims jwt.MapClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(key.method, claims)
+	token.Header["kid"] = key.kid
+	raw, err := token.SignedString(key.key)
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+	return raw
+}
+
+func validClaims() jwt.MapClaims {
+	return jwt.MapClaims{
+		"iss": testIssuer,
+		"aud": testAudience,
+		"sub": "u1",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}
+}
+
+func fileJWKS(t *testing.T, keys ...signingKey) *oidc.JWKS {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, jwksJSON(t, keys...), 0o600); err != nil {
+		t.Fatalf("write jwks: %v", err)
+	}
+	jwks, err := oidc.LoadJWKSFile(path)
+	if err != nil {
+		t.Fatalf("load jwks: %v", err)
+	}
+	return jwks
+}
+
+func newVerifier(jwks *oidc.JWKS) *oidc.Verifier {
+	return oidc.NewVerifier(jwks, oidc.VerifierConfig{
+		Issuer:      testIssuer,
+		Audience:    testAudience,
+		UserClaim:   "sub",
+		GroupsClaim: "groups",
+		GroupRoles: map[string]domain.APIRole{
+			"reviewers": domain.APIRoleUser,
+			"leads":     domain.APIRoleTeamLead,
+		},
+		DefaultRole: domain.APIRoleReadOnly,
+	})
+}
+
+func TestVerify(t *testing.T) {
+	rsaKey := newRSAKey(t, "rsa")
+	ecKey := newECKey(t, "ec")
+	verifier := newVerifier(fileJWKS(t, rsaKey, ecKey))
+
+	with := func(changes jwt.MapClaims) jwt.MapClaims {
+		claims := validClaims()
+		for name, value := range changes {
+			if value == nil {
+				delete(claims, name)
+				continue
+			}
+			claims[name] = value
+		}
+		return claims
+	}
+
+	tests := []struct {
+		name     string
+		key      signingKey
+		claims   jwt.MapClaims
+		wantRole domain.APIRole
+		wantErr  bool
+	}{
+		{name: "rsa", key: rsaKey, claims: validClaims(), wantRole: domain.APIRoleReadOnly},
+		{name: "ec", key: ecKey, claims: validClaims(), wantRole: domain.APIRoleReadOnly},
+		{name: "most privileged group wins", key: rsaKey, claims: with(jwt.MapClaims{"groups": []interface{}{"reviewers", "leads", "other"}}), wantRole: domain.APIRoleTeamLead},
+		{name: "single group string", key: rsaKey, claims: with(jwt.MapClaims{"groups": "reviewers"}), wantRole: domain.APIRoleUser},
+		{name: "unmapped group", key: rsaKey, claims: with(jwt.MapClaims{"groups": []interface{}{"ADMIN"}}), wantRole: domain.APIRoleReadOnly},
+		{name: "wrong issuer", key: rsaKey, claims: with(jwt.MapClaims{"iss": "https://other.example.com"}), wantErr: true},
+		{name: "missing issuer", key: rsaKey, claims: with(jwt.MapClaims{"iss": nil}), wantErr: true},
+		{name: "wrong audience", key: rsaKey, claims: with(jwt.MapClaims{"aud": "other-app"}), wantErr: true},
+		{name: "missing audience", key: rsaKey, claims: with(jwt.MapClaims{"aud": nil}), wantErr: true},
+		{name: "expired", key: rsaKey, claims: with(jwt.MapClaims{"exp": time.Now().Add(-time.Minute).Unix()}), wantErr: true},
+		{name: "no expiry", key: rsaKey, claims: with(jwt.MapClaims{"exp": nil}), wantErr: true},
+		{name: "missing subject", key: rsaKey, claims: with(jwt.MapClaims{"sub": nil}), wantErr: true},
+		{name: "unknown key", key: newRSAKey(t, "stranger"), claims: validClaims(), wantErr: true},
+		{name: "key id of another key", key: signingKey{kid: "rsa", method: jwt.SigningMethodRS256, key: newRSAKey(t, "").key}, claims: validClaims(), wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actor, err := verifier.Verify(context.Background(), sign(t, test.key, test.claims))
+			if test.wantErr {
+				var domainErr domain.DomainError
+				if !errors.As(err, &domainErr) || domainErr.Code() != string(domain.ErrUnauthorizedCode) {
+					t.Fatalf("Verify: got %v, want UNAUTHORIZED", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Verify: %v", err)
+			}
+			if actor.UserID != "u1" || actor.Role != test.wantRole {
+				t.Fatalf("actor = %+v, want u1 with role %s", actor, test.wantRole)
+			}
+		})
+	}
+}
+
+func TestVerifyRejectsHS256(t *testing.T) {
+	verifier := newVerifier(fileJWKS(t, newRSAKey(t, "rsa")))
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, validClaims())
+	token.Header["kid"] = "rsa"
+	raw, err := token.SignedString([]byte("secret"))
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+	if _, err := verifier.Verify(context.Background(), raw); err == nil {
+		t.Fatal("Verify accepted an HS256 token")
+	}
+}
+
+func TestRemoteJWKS(t *testing.T) {
+	oldKey := newRSAKey(t, "old")
+	newKey := newECKey(t, "new")
+
+	var published atomic.Value
+	published.Store(jwksJSON(t, oldKey))
+	var fetches atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(published.Load().([]byte))
+	}))
+	defer server.Close()
+
+	jwks := oidc.NewRemoteJWKS(server.URL, server.Client(), 0)
+	verifier := newVerifier(jwks)
+	ctx := context.Background()
+
+	if _, err := verifier.Verify(ctx, sign(t, oldKey, validClaims())); err != nil {
+		t.Fatalf("Verify with the published key: %v", err)
+	}
+	if _, err := verifier.Verify(ctx, sign(t, oldKey, validClaims())); err != nil {
+		t.Fatalf("Verify with a cached key: %v", err)
+	}
+	if got := fetches.Load(); got != 1 {
+		t.Fatalf("fetches = %d, want 1: known keys must come from the cache", got)
+	}
+
+	// Key rotation: an unknown key id triggers a refetch.
+	published.Store(jwksJSON(t, newKey))
+	if _, err := verifier.Verify(ctx, sign(t, newKey, validClaims())); err != nil {
+		t.Fatalf("Verify with a rotated key: %v", err)
+	}
+	if got := fetches.Load(); got != 2 {
+		t.Fatalf("fetches = %d, want 2", got)
+	}
+}
+
+func TestRemoteJWKSRefetchInterval(t *testing.T) {
+	key := newRSAKey(t, "rsa")
+	published := jwksJSON(t, key)
+	var fetches atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		w.Write(published)
+	}))
+	defer server.Close()
+
+	jwks := oidc.NewRemoteJWKS(server.URL, server.Client(), time.Hour)
+	ctx := context.Background()
+
+	if _, err := jwks.Key(ctx, "rsa"); err != nil {
+		t.Fatalf("Key: %v", err)
+	}
+	for range 3 {
+		if _, err := jwks.Key(ctx, "unknown"); err == nil {
+			t.Fatal("Key returned a key for an unknown id")
+		}
+	}
+	if got := fetches.Load(); got != 1 {
+		t.Fatalf("fetches = %d, want 1: unknown ids must not refetch before the interval", got)
+	}
+}
+
+// A slow identity provider must not hold up tokens signed with cached keys.
+func TestRemoteJWKSFetchDoesNotBlockCachedKeys(t *testing.T) {
+	key := newRSAKey(t, "rsa")
+	published := jwksJSON(t, key)
+	release := make(chan struct{})
+	var fetches atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fetches.Add(1) > 1 {
+			<-release
+		}
+		w.Write(published)
+	}))
+	defer server.Close()
+	defer close(release)
+
+	jwks := oidc.NewRemoteJWKS(server.URL, server.Client(), 0)
+	ctx := context.Background()
+	if _, err := jwks.Key(ctx, "rsa"); err != nil {
+		t.Fatalf("Key: %v", err)
+	}
+
+	go jwks.Key(ctx, "unknown")
+	for fetches.Load() < 2 {
+		time.Sleep(time.Millisecond)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := jwks.Key(ctx, "rsa")
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Key: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("lookup of a cached key waited for the remote fetch")
+	}
+}
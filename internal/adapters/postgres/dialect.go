@@ -0,0 +1,10 @@
+package postgres
+
+import "pullrequests/internal/adapters/sqlstore"
+
+// Dialect runs the sqlstore repositories on PostgreSQL.
+var Dialect = sqlstore.Dialect{
+	System:         "postgresql",
+	SkipLocked:     "FOR UPDATE SKIP LOCKED",
+	TranslateError: translateError,
+}
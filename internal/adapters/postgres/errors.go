@@ -0,0 +1,42 @@
+package postgres
+
+import (
+	"errors"
+	"pullrequests/internal/domain"
+
+	"github.com/lib/pq"
+)
+
+const (
+	uniqueViolationCode     pq.ErrorCode = "23505"
+	foreignKeyViolationCode pq.ErrorCode = "23503"
+)
+
+var uniqueConstraintCodes = map[string]domain.ErrCode{
+	"teams_pkey":                  domain.ErrTeamExistsCode,
+	"users_pkey":                  domain.ErrUserExistsCode,
+	"pull_requests_pkey":          domain.ErrPRExistsCode,
+	"pull_request_reviewers_pkey": domain.ErrConflictCode,
+	"api_tokens_pkey":             domain.ErrConflictCode,
+	"webhook_subscriptions_pkey":  domain.ErrConflictCode,
+	"webhook_deliveries_pkey":     domain.ErrConflictCode,
+	"outbox_events_pkey":          domain.ErrConflictCode,
+	"jobs_pkey":                   domain.ErrConflictCode,
+}
+
+func translateError(err error) error {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return err
+	}
+
+	switch pqErr.Code {
+	case uniqueViolationCode:
+		if code, ok := uniqueConstraintCodes[pqErr.Constraint]; ok {
+			return domain.NewDomainError(code).WithCause(err)
+		}
+	case foreignKeyViolationCode:
+		return domain.NewDomainError(domain.ErrNotFoundCode).WithCause(err)
+	}
+	return err
+}
@@ -2,30 +2,90 @@ package postgres
 
 import (
 	"context"
+	"database/sql"
+	"errors"
+	"math/rand/v2"
+	"pullrequests/internal/adapters/sqlstore"
+	"pullrequests/internal/domain"
+	"pullrequests/internal/tracing"
+	"time"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const (
+	defaultMaxAttempts = 5
+	defaultBaseDelay   = 10 * time.Millisecond
+	defaultMaxDelay    = 200 * time.Millisecond
+)
+
+const (
+	serializationFailureCode pq.ErrorCode = "40001"
+	deadlockDetectedCode     pq.ErrorCode = "40P01"
 )
 
 type SQLTransactionManager struct {
-	db *sqlx.DB
+	db          *sqlx.DB
+	observer    domain.TransactionObserver
+	maxAttempts int
+	baseDelay   time.Duration
+	maxDelay    time.Duration
 }
 
-func NewSQLTransactionManager(db *sqlx.DB) *SQLTransactionManager {
-	return &SQLTransactionManager{db: db}
+func NewSQLTransactionManager(db *sqlx.DB, observer domain.TransactionObserver) *SQLTransactionManager {
+	return &SQLTransactionManager{
+		db:          db,
+		observer:    observer,
+		maxAttempts: defaultMaxAttempts,
+		baseDelay:   defaultBaseDelay,
+		maxDelay:    defaultMaxDelay,
+	}
 }
 
-type transactionKey struct{}
-
-func (m SQLTransactionManager) Do(ctx context.Context, fn func(context.Context) error) error {
-	if tx := getTxFromContext(ctx); tx != nil {
+func (m *SQLTransactionManager) Do(ctx context.Context, fn func(context.Context) error, opts ...domain.TxOption) (err error) {
+	options := domain.NewTxOptions(opts...)
+	nested := sqlstore.TxFromContext(ctx) != nil
+	ctx, span := tracing.StartTransaction(ctx, options.Name, nested)
+	defer func() { tracing.End(span, err) }()
+	if nested {
+		if options.Savepoint {
+			return sqlstore.Savepoint(ctx, fn)
+		}
 		return fn(ctx)
 	}
-	tx, err := m.db.BeginTxx(ctx, nil)
+
+	txOpts := toSQLTxOptions(options)
+	for attempt := 1; attempt <= m.maxAttempts; attempt++ {
+		span.SetAttributes(attribute.Int("db.transaction.attempts", attempt))
+		err = m.do(ctx, fn, txOpts)
+		if err == nil || !isRetryable(err) || attempt == m.maxAttempts {
+			return err
+		}
+
+		timer := time.NewTimer(m.backoff(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+	return err
+}
+
+func (m *SQLTransactionManager) do(ctx context.Context, fn func(context.Context) error, txOpts *sql.TxOptions) (err error) {
+	tx, err := m.db.BeginTxx(ctx, txOpts)
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback()
-	ctxWithTx := context.WithValue(ctx, transactionKey{}, tx)
+	start := time.Now()
+	defer func() {
+		m.observer.ObserveTransaction("postgres", time.Since(start), err != nil)
+	}()
+	ctxWithTx := sqlstore.WithTx(ctx, tx)
 
 	err = fn(ctxWithTx)
 	if err != nil {
@@ -34,16 +94,36 @@ func (m SQLTransactionManager) Do(ctx context.Context, fn func(context.Context)
 	return tx.Commit()
 }
 
-func TxOrDb(ctx context.Context, db *sqlx.DB) sqlx.ExtContext {
-	if tx, ok := ctx.Value(transactionKey{}).(*sqlx.Tx); ok {
-		return tx
+func (m *SQLTransactionManager) backoff(attempt int) time.Duration {
+	delay := m.baseDelay << (attempt - 1)
+	if delay <= 0 || delay > m.maxDelay {
+		delay = m.maxDelay
+	}
+	if delay <= 0 {
+		return 0
+	}
+	return delay/2 + rand.N(delay/2+1)
+}
+
+func isRetryable(err error) bool {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return false
 	}
-	return db
+	return pqErr.Code == serializationFailureCode || pqErr.Code == deadlockDetectedCode
 }
 
-func getTxFromContext(ctx context.Context) *sqlx.Tx {
-	if tx, ok := ctx.Value(transactionKey{}).(*sqlx.Tx); ok {
-		return tx
+func toSQLTxOptions(opts domain.TxOptions) *sql.TxOptions {
+	txOpts := &sql.TxOptions{ReadOnly: opts.ReadOnly}
+	switch opts.Isolation {
+	case domain.IsolationReadCommitted:
+		txOpts.Isolation = sql.LevelReadCommitted
+	case domain.IsolationRepeatableRead:
+		txOpts.Isolation = sql.LevelRepeatableRead
+	case domain.IsolationSerializable:
+		txOpts.Isolation = sql.LevelSerializable
+	default:
+		txOpts.Isolation = sql.LevelDefault
 	}
-	return nil
+	return txOpts
 }
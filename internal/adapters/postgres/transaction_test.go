@@ -0,0 +1,219 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"pullrequests/internal/domain"
+	"pullrequests/internal/metrics"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// fakeConnector records the transactions begun on it; statements are not
+// supported, the tests only exercise the retry loop of Do.
+type fakeConnector struct {
+	mu     sync.Mutex
+	begins []driver.TxOptions
+	commit error
+}
+
+func (c *fakeConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	return &fakeConn{connector: c}, nil
+}
+
+func (c *fakeConnector) Driver() driver.Driver {
+	return nil
+}
+
+func (c *fakeConnector) beginCount() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.begins)
+}
+
+type fakeConn struct {
+	connector *fakeConnector
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("statements are not supported")
+}
+
+func (c *fakeConn) Close() error {
+	return nil
+}
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *fakeConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	c.connector.mu.Lock()
+	defer c.connector.mu.Unlock()
+	c.connector.begins = append(c.connector.begins, opts)
+	return &fakeTx{connector: c.connector}, nil
+}
+
+type fakeTx struct {
+	connector *fakeConnector
+}
+
+func (tx *fakeTx) Commit() error {
+	return tx.connector.commit
+}
+
+func (tx *fakeTx) Rollback() error {
+	return nil
+}
+
+func newFakeManager(t *testing.T) (*SQLTransactionManager, *fakeConnector) {
+	t.Helper()
+	connector := &fakeConnector{}
+	db := sqlx.NewDb(sql.OpenDB(connector), "postgres")
+	t.Cleanup(func() { db.Close() })
+
+	m := NewSQLTransactionManager(db, metrics.Recorder{})
+	m.baseDelay = 0
+	m.maxDelay = 0
+	return m, connector
+}
+
+// failing returns a transaction function failing with errs in turn and
+// succeeding once they are used up, and a counter of its calls.
+func failing(errs ...error) (func(context.Context) error, *int) {
+	calls := 0
+	return func(ctx context.Context) error {
+		calls++
+		if calls <= len(errs) {
+			return errs[calls-1]
+		}
+		return nil
+	}, &calls
+}
+
+func TestDoRetries(t *testing.T) {
+	serialization := &pq.Error{Code: serializationFailureCode}
+	deadlock := &pq.Error{Code: deadlockDetectedCode}
+	uniqueViolation := &pq.Error{Code: uniqueViolationCode}
+	conflict := domain.NewDomainError(domain.ErrConflictCode)
+
+	tests := []struct {
+		name      string
+		errs      []error
+		wantCalls int
+		wantErr   error
+	}{
+		{name: "success", wantCalls: 1},
+		{name: "serialization failures", errs: []error{serialization, serialization}, wantCalls: 3},
+		{name: "deadlock", errs: []error{deadlock}, wantCalls: 2},
+		{name: "wrapped serialization failure", errs: []error{domain.NewDomainError(domain.ErrInternalCode).WithCause(serialization)}, wantCalls: 2},
+		{name: "attempts exhausted", errs: []error{serialization, serialization, serialization, serialization, serialization}, wantCalls: defaultMaxAttempts, wantErr: serialization},
+		{name: "unique violation is not retried", errs: []error{uniqueViolation}, wantCalls: 1, wantErr: uniqueViolation},
+		{name: "domain error is not retried", errs: []error{conflict}, wantCalls: 1, wantErr: conflict},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			m, connector := newFakeManager(t)
+			fn, calls := failing(test.errs...)
+
+			err := m.Do(context.Background(), fn, domain.WithIsolation(domain.IsolationSerializable))
+			if !errors.Is(err, test.wantErr) || (test.wantErr == nil && err != nil) {
+				t.Fatalf("Do: got %v, want %v", err, test.wantErr)
+			}
+			if *calls != test.wantCalls {
+				t.Errorf("fn ran %d times, want %d", *calls, test.wantCalls)
+			}
+			if got := connector.beginCount(); got != test.wantCalls {
+				t.Errorf("began %d transactions, want one per attempt (%d)", got, test.wantCalls)
+			}
+			for _, opts := range connector.begins {
+				if opts.Isolation != driver.IsolationLevel(sql.LevelSerializable) {
+					t.Errorf("transaction began with isolation %v, want serializable", sql.IsolationLevel(opts.Isolation))
+				}
+			}
+		})
+	}
+}
+
+func TestDoRetriesCommitFailure(t *testing.T) {
+	m, connector := newFakeManager(t)
+	connector.commit = &pq.Error{Code: serializationFailureCode}
+	fn, calls := failing()
+
+	err := m.Do(context.Background(), fn)
+	if !isRetryable(err) {
+		t.Fatalf("Do: got %v, want the serialization failure of the last commit", err)
+	}
+	if *calls != defaultMaxAttempts {
+		t.Errorf("fn ran %d times, want %d", *calls, defaultMaxAttempts)
+	}
+}
+
+func TestDoNestedJoinsOuterTransaction(t *testing.T) {
+	m, connector := newFakeManager(t)
+	inner, innerCalls := failing(&pq.Error{Code: serializationFailureCode})
+
+	outerCalls := 0
+	err := m.Do(context.Background(), func(ctx context.Context) error {
+		outerCalls++
+		return m.Do(ctx, inner)
+	})
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	// The nested call must not retry on its own: the whole outer
+	// transaction is rolled back and has to run again.
+	if outerCalls != 2 || *innerCalls != 2 {
+		t.Errorf("outer ran %d times, inner %d times, want 2 and 2", outerCalls, *innerCalls)
+	}
+	if got := connector.beginCount(); got != 2 {
+		t.Errorf("began %d transactions, want 2", got)
+	}
+}
+
+func TestDoStopsRetryingWhenCanceled(t *testing.T) {
+	m, _ := newFakeManager(t)
+	m.baseDelay = time.Hour
+	m.maxDelay = time.Hour
+
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	done := make(chan error, 1)
+	go func() {
+		done <- m.Do(ctx, func(ctx context.Context) error {
+			calls++
+			cancel()
+			return &pq.Error{Code: serializationFailureCode}
+		})
+	}()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("Do: got %v, want context.Canceled", err)
+		}
+		if calls != 1 {
+			t.Errorf("fn ran %d times, want 1", calls)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Do kept waiting for the backoff after the context was canceled")
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	m := &SQLTransactionManager{baseDelay: 10 * time.Millisecond, maxDelay: 50 * time.Millisecond}
+	for attempt, limit := range map[int]time.Duration{1: 10 * time.Millisecond, 2: 20 * time.Millisecond, 3: 40 * time.Millisecond, 4: 50 * time.Millisecond, 60: 50 * time.Millisecond} {
+		for range 20 {
+			delay := m.backoff(attempt)
+			if delay < limit/2 || delay > limit {
+				t.Fatalf("backoff(%d) = %v, want within [%v, %v]", attempt, delay, limit/2, limit)
+			}
+		}
+	}
+}
@@ -0,0 +1,23 @@
+package sqlite_test
+
+import (
+	"path/filepath"
+	"pullrequests/internal/adapters/contracttest"
+	"pullrequests/internal/adapters/sqlite"
+	"pullrequests/internal/metrics"
+	"pullrequests/internal/migrate"
+	"pullrequests/migrations"
+	"testing"
+)
+
+func TestContract(t *testing.T) {
+	contracttest.Run(t, func(t *testing.T) contracttest.Repositories {
+		db, err := sqlite.Open(filepath.Join(t.TempDir(), "contract.db"))
+		if err != nil {
+			t.Fatalf("open: %v", err)
+		}
+		t.Cleanup(func() { db.Close() })
+		contracttest.Migrate(t, db, migrate.SQLite, migrations.SQLite, "sqlite")
+		return contracttest.SQLRepositories(db, sqlite.Dialect, sqlite.NewSQLTransactionManager(db, metrics.Recorder{}))
+	})
+}
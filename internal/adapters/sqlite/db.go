@@ -0,0 +1,21 @@
+package sqlite
+
+import (
+	"github.com/jmoiron/sqlx"
+	_ "modernc.org/sqlite"
+)
+
+func init() {
+	sqlx.BindDriver("sqlite", sqlx.QUESTION)
+}
+
+func Open(path string) (*sqlx.DB, error) {
+	dsn := "file:" + path + "?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)"
+	db, err := sqlx.Open("sqlite", dsn)
+	if err != nil {
+		return nil, err
+	}
+	// SQLite allows a single writer, so transactions are serialized on one connection.
+	db.SetMaxOpenConns(1)
+	return db, nil
+}
@@ -0,0 +1,10 @@
+package sqlite
+
+import "pullrequests/internal/adapters/sqlstore"
+
+// Dialect runs the sqlstore repositories on SQLite. Rows need no locking:
+// a write transaction holds the whole database.
+var Dialect = sqlstore.Dialect{
+	System:         "sqlite",
+	TranslateError: translateError,
+}
@@ -0,0 +1,51 @@
+package sqlite
+
+import (
+	"errors"
+	"pullrequests/internal/domain"
+	"strings"
+
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+var uniqueTableCodes = map[string]domain.ErrCode{
+	"teams":                  domain.ErrTeamExistsCode,
+	"users":                  domain.ErrUserExistsCode,
+	"pull_requests":          domain.ErrPRExistsCode,
+	"pull_request_reviewers": domain.ErrConflictCode,
+	"api_tokens":             domain.ErrConflictCode,
+	"webhook_subscriptions":  domain.ErrConflictCode,
+	"webhook_deliveries":     domain.ErrConflictCode,
+	"outbox_events":          domain.ErrConflictCode,
+	"jobs":                   domain.ErrConflictCode,
+}
+
+func translateError(err error) error {
+	var sqliteErr *sqlite.Error
+	if !errors.As(err, &sqliteErr) {
+		return err
+	}
+
+	switch sqliteErr.Code() {
+	case sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY, sqlite3.SQLITE_CONSTRAINT_UNIQUE:
+		if code, ok := uniqueTableCodes[constraintTable(sqliteErr.Error())]; ok {
+			return domain.NewDomainError(code).WithCause(err)
+		}
+	case sqlite3.SQLITE_CONSTRAINT_FOREIGNKEY:
+		return domain.NewDomainError(domain.ErrNotFoundCode).WithCause(err)
+	}
+	return err
+}
+
+// constraintTable extracts the table from messages like
+// "constraint failed: UNIQUE constraint failed: teams.team_name (1555)".
+func constraintTable(message string) string {
+	const marker = "constraint failed: "
+	i := strings.LastIndex(message, marker)
+	if i < 0 {
+		return ""
+	}
+	table, _, _ := strings.Cut(message[i+len(marker):], ".")
+	return strings.TrimSpace(table)
+}
@@ -0,0 +1,211 @@
+package sqlite_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"pullrequests/internal/adapters/contracttest"
+	"pullrequests/internal/adapters/sqlite"
+	"pullrequests/internal/domain"
+	"pullrequests/internal/dtos"
+	"pullrequests/internal/handlers"
+	"pullrequests/internal/metrics"
+	"pullrequests/internal/migrate"
+	"pullrequests/internal/tracing"
+	"pullrequests/internal/usecases"
+	"pullrequests/migrations"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// keptSpans survives the provider shutdown, which resets an in-memory exporter.
+type keptSpans struct {
+	*tracetest.InMemoryExporter
+}
+
+func (keptSpans) Shutdown(context.Context) error {
+	return nil
+}
+
+func openTraced(t *testing.T) contracttest.Repositories {
+	t.Helper()
+	db, err := sqlite.Open(filepath.Join(t.TempDir(), "tracing.db"))
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	contracttest.Migrate(t, db, migrate.SQLite, migrations.SQLite, "sqlite")
+	return contracttest.SQLRepositories(db, sqlite.Dialect, sqlite.NewSQLTransactionManager(db, metrics.Recorder{}))
+}
+
+func TestTransactionSpanTree(t *testing.T) {
+	repos := openTraced(t)
+
+	exporter := keptSpans{tracetest.NewInMemoryExporter()}
+	shutdown, err := tracing.Setup("test", exporter)
+	if err != nil {
+		t.Fatalf("setup tracing: %v", err)
+	}
+
+	ctx := context.Background()
+	err = repos.TM.Do(ctx, func(ctx context.Context) error {
+		if err := repos.Team.Add(ctx, &domain.Team{Name: "backend"}); err != nil {
+			return err
+		}
+		// Nested calls join the outer transaction but still get a span.
+		return repos.TM.Do(ctx, func(ctx context.Context) error {
+			_, err := repos.Team.GetTeamByTeamName(ctx, "backend")
+			return err
+		}, domain.WithName("Test.Inner"))
+	}, domain.WithName("Test.Outer"))
+	if err != nil {
+		t.Fatalf("transaction: %v", err)
+	}
+	if err := shutdown(ctx); err != nil {
+		t.Fatalf("flush spans: %v", err)
+	}
+
+	spans := exporter.GetSpans()
+	byName := make(map[string]tracetest.SpanStub, len(spans))
+	for _, span := range spans {
+		byName[span.Name] = span
+	}
+	names := make([]string, 0, len(byName))
+	for name := range byName {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	want := []string{"TeamRepo.Add", "TeamRepo.GetTeamByTeamName", "Test.Inner", "Test.Outer"}
+	if !slices.Equal(names, want) {
+		t.Fatalf("got spans %v, want %v", names, want)
+	}
+
+	parents := map[string]string{
+		"Test.Inner":                 "Test.Outer",
+		"TeamRepo.Add":               "Test.Outer",
+		"TeamRepo.GetTeamByTeamName": "Test.Inner",
+	}
+	for child, parent := range parents {
+		if got, want := byName[child].Parent.SpanID(), byName[parent].SpanContext.SpanID(); got != want {
+			t.Errorf("span %s has parent %s, want %s", child, got, parent)
+		}
+	}
+	if byName["Test.Outer"].Parent.IsValid() {
+		t.Errorf("span Test.Outer has a parent, want a root span")
+	}
+
+	// GetTeamByTeamName reads the members row by row; its span must cover
+	// the reading and so end inside the inner transaction.
+	query, inner := byName["TeamRepo.GetTeamByTeamName"], byName["Test.Inner"]
+	if query.EndTime.IsZero() || query.EndTime.After(inner.EndTime) {
+		t.Errorf("query span ended at %v, want before the inner transaction ended at %v", query.EndTime, inner.EndTime)
+	}
+	if d := query.EndTime.Sub(query.StartTime); d <= 0 || d > time.Minute {
+		t.Errorf("query span lasted %v", d)
+	}
+}
+
+// TestCreatePRTrace follows a request through the HTTP middleware, the
+// usecase transaction and the repository queries of a real storage.
+func TestCreatePRTrace(t *testing.T) {
+	ctx := context.Background()
+	repos := openTraced(t)
+	teams := usecases.NewTeamUsecase(repos.Team, repos.User, repos.TM)
+	_, err := teams.AddTeam(ctx, dtos.TeamRequest{Team: dtos.Team{
+		TeamName: "backend",
+		Members: []dtos.TeamMember{
+			{UserID: "u1", Username: "alice", IsActive: true},
+			{UserID: "u2", Username: "bob", IsActive: true},
+		},
+	}})
+	if err != nil {
+		t.Fatalf("add team: %v", err)
+	}
+
+	pullrequests := usecases.NewPRUsecase(repos.PullRequest, repos.User, repos.History, usecases.NewFirstNStrategy(),
+		usecases.NewOutboxPublisher(repos.Outbox), metrics.Recorder{}, repos.TM)
+	router := chi.NewRouter()
+	router.Use(handlers.TracingMiddleware)
+	router.Post("/pullRequest/create", handlers.NewPRHandler(pullrequests).CreatePR)
+
+	exporter := keptSpans{tracetest.NewInMemoryExporter()}
+	shutdown, err := tracing.Setup("test", exporter)
+	if err != nil {
+		t.Fatalf("setup tracing: %v", err)
+	}
+
+	const (
+		callerTraceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+		callerSpanID  = "00f067aa0ba902b7"
+	)
+	req := httptest.NewRequest(http.MethodPost, "/pullRequest/create",
+		strings.NewReader(`{"pull_request_id":"pr-1","pull_request_name":"Add search","author_id":"u1"}`))
+	req.Header.Set("traceparent", "00-"+callerTraceID+"-"+callerSpanID+"-01")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create: got status %d: %s", rec.Code, rec.Body)
+	}
+	if err := shutdown(ctx); err != nil {
+		t.Fatalf("flush spans: %v", err)
+	}
+
+	var server, usecase *tracetest.SpanStub
+	spans := exporter.GetSpans()
+	for i := range spans {
+		switch spans[i].Name {
+		case "POST /pullRequest/create":
+			server = &spans[i]
+		case "PRUsecase.CreatePR":
+			usecase = &spans[i]
+		}
+		if got := spans[i].SpanContext.TraceID().String(); got != callerTraceID {
+			t.Errorf("span %s has trace %s, want the caller's %s", spans[i].Name, got, callerTraceID)
+		}
+	}
+	if server == nil || usecase == nil {
+		t.Fatalf("got spans %v, want the HTTP server span and PRUsecase.CreatePR", spanNames(spans))
+	}
+
+	if server.SpanKind != trace.SpanKindServer {
+		t.Errorf("HTTP span kind = %v, want server", server.SpanKind)
+	}
+	if !server.Parent.IsRemote() || server.Parent.SpanID().String() != callerSpanID {
+		t.Errorf("HTTP span has parent %v, want the caller's remote span %s", server.Parent.SpanID(), callerSpanID)
+	}
+	if usecase.Parent.SpanID() != server.SpanContext.SpanID() {
+		t.Errorf("PRUsecase.CreatePR has parent %v, want the HTTP span", usecase.Parent.SpanID())
+	}
+
+	queried := map[string]bool{}
+	for _, span := range spans {
+		if span.Name == server.Name || span.Name == usecase.Name {
+			continue
+		}
+		if span.Parent.SpanID() != usecase.SpanContext.SpanID() {
+			t.Errorf("span %s has parent %v, want PRUsecase.CreatePR", span.Name, span.Parent.SpanID())
+		}
+		repo, _, _ := strings.Cut(span.Name, ".")
+		queried[repo] = true
+	}
+	for _, repo := range []string{"PRRepo", "UserRepo"} {
+		if !queried[repo] {
+			t.Errorf("got spans %v, want %s queries under PRUsecase.CreatePR", spanNames(spans), repo)
+		}
+	}
+}
+
+func spanNames(spans tracetest.SpanStubs) []string {
+	names := make([]string, 0, len(spans))
+	for _, span := range spans {
+		names = append(names, span.Name)
+	}
+	return names
+}
@@ -0,0 +1,50 @@
+package sqlite
+
+import (
+	"context"
+	"pullrequests/internal/adapters/sqlstore"
+	"pullrequests/internal/domain"
+	"pullrequests/internal/tracing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type SQLTransactionManager struct {
+	db       *sqlx.DB
+	observer domain.TransactionObserver
+}
+
+func NewSQLTransactionManager(db *sqlx.DB, observer domain.TransactionObserver) *SQLTransactionManager {
+	return &SQLTransactionManager{db: db, observer: observer}
+}
+
+// Do ignores isolation options: SQLite transactions are always serializable.
+func (m *SQLTransactionManager) Do(ctx context.Context, fn func(context.Context) error, opts ...domain.TxOption) (err error) {
+	options := domain.NewTxOptions(opts...)
+	nested := sqlstore.TxFromContext(ctx) != nil
+	ctx, span := tracing.StartTransaction(ctx, options.Name, nested)
+	defer func() { tracing.End(span, err) }()
+	if nested {
+		if options.Savepoint {
+			return sqlstore.Savepoint(ctx, fn)
+		}
+		return fn(ctx)
+	}
+	tx, err := m.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	start := time.Now()
+	defer func() {
+		m.observer.ObserveTransaction("sqlite", time.Since(start), err != nil)
+	}()
+	ctxWithTx := sqlstore.WithTx(ctx, tx)
+
+	err = fn(ctxWithTx)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
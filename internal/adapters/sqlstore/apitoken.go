@@ -0,0 +1,129 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"pullrequests/internal/domain"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type APITokenRepo struct {
+	db      *sqlx.DB
+	dialect Dialect
+}
+
+func NewAPITokenRepo(db *sqlx.DB, dialect Dialect) *APITokenRepo {
+	return &APITokenRepo{db: db, dialect: dialect}
+}
+
+func (r *APITokenRepo) Add(ctx context.Context, token *domain.APIToken) error {
+	query := `
+		INSERT INTO api_tokens (token_id, name, token_hash, role, user_id, created_at)
+		VALUES (:token_id, :name, :token_hash, :role, :user_id, :created_at)
+	`
+
+	_, err := sqlx.NamedExecContext(
+		ctx,
+		TxOrDb(ctx, r.db, r.dialect, "APITokenRepo.Add"),
+		query,
+		r.toTokenRow(token),
+	)
+	return r.dialect.TranslateError(err)
+}
+
+func (r *APITokenRepo) GetTokenByHash(ctx context.Context, tokenHash string) (*domain.APIToken, error) {
+	tx := TxOrDb(ctx, r.db, r.dialect, "APITokenRepo.GetTokenByHash")
+
+	query := r.db.Rebind(`
+		SELECT token_id, name, token_hash, role, user_id, created_at, revoked_at
+		FROM api_tokens WHERE token_hash = ?
+	`)
+	token, err := r.scanToken(tx.QueryRow(ctx, query, tokenHash))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return token, nil
+}
+
+func (r *APITokenRepo) GetTokens(ctx context.Context) ([]domain.APIToken, error) {
+	tx := TxOrDb(ctx, r.db, r.dialect, "APITokenRepo.GetTokens")
+
+	query := `
+		SELECT token_id, name, token_hash, role, user_id, created_at, revoked_at
+		FROM api_tokens ORDER BY created_at, token_id
+	`
+	rows, err := tx.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tokens := []domain.APIToken{}
+	for rows.Next() {
+		token, err := r.scanToken(rows)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, *token)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+func (r *APITokenRepo) RevokeToken(ctx context.Context, tokenID string, revokedAt time.Time) error {
+	query := r.db.Rebind("UPDATE api_tokens SET revoked_at = COALESCE(revoked_at, ?) WHERE token_id = ?")
+
+	result, err := TxOrDb(ctx, r.db, r.dialect, "APITokenRepo.RevokeToken").ExecContext(ctx, query, utc(revokedAt), tokenID)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return domain.NewDomainError(domain.ErrNotFoundCode)
+	}
+	return nil
+}
+
+func (r *APITokenRepo) scanToken(row sqlx.ColScanner) (*domain.APIToken, error) {
+	token := &domain.APIToken{}
+	var userID sql.NullString
+	var revokedAt sql.NullTime
+	if err := row.Scan(&token.TokenID, &token.Name, &token.TokenHash, &token.Role, &userID, &token.CreatedAt, &revokedAt); err != nil {
+		return nil, err
+	}
+	token.UserID = userID.String
+	if revokedAt.Valid {
+		token.RevokedAt = &revokedAt.Time
+	}
+	return token, nil
+}
+
+func (r *APITokenRepo) toTokenRow(token *domain.APIToken) map[string]interface{} {
+	row := map[string]interface{}{
+		"token_id":   token.TokenID,
+		"name":       token.Name,
+		"token_hash": token.TokenHash,
+		"role":       token.Role,
+		"created_at": utc(token.CreatedAt),
+	}
+
+	if token.UserID != "" {
+		row["user_id"] = token.UserID
+	} else {
+		row["user_id"] = nil
+	}
+
+	return row
+}
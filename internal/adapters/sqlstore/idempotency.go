@@ -0,0 +1,115 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"pullrequests/internal/domain"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type IdempotencyRepo struct {
+	db      *sqlx.DB
+	dialect Dialect
+}
+
+func NewIdempotencyRepo(db *sqlx.DB, dialect Dialect) *IdempotencyRepo {
+	return &IdempotencyRepo{db: db, dialect: dialect}
+}
+
+func (r *IdempotencyRepo) Reserve(ctx context.Context, record *domain.IdempotencyRecord) (bool, error) {
+	query := `
+		INSERT INTO idempotency_keys (actor_id, idempotency_key, route, request_hash, created_at, expires_at)
+		VALUES (:actor_id, :idempotency_key, :route, :request_hash, :created_at, :expires_at)
+		ON CONFLICT (actor_id, idempotency_key, route) DO NOTHING
+	`
+
+	result, err := sqlx.NamedExecContext(
+		ctx,
+		TxOrDb(ctx, r.db, r.dialect, "IdempotencyRepo.Reserve"),
+		query,
+		map[string]interface{}{
+			"actor_id":        record.ActorID,
+			"idempotency_key": record.Key,
+			"route":           record.Route,
+			"request_hash":    record.RequestHash,
+			"created_at":      utc(record.CreatedAt),
+			"expires_at":      utc(record.ExpiresAt),
+		},
+	)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected == 1, nil
+}
+
+func (r *IdempotencyRepo) Get(ctx context.Context, actorID, key, route string) (*domain.IdempotencyRecord, error) {
+	tx := TxOrDb(ctx, r.db, r.dialect, "IdempotencyRepo.Get")
+
+	query := r.db.Rebind(`
+		SELECT actor_id, idempotency_key, route, request_hash, status_code, response_headers, response_body, created_at, expires_at
+		FROM idempotency_keys WHERE actor_id = ? AND idempotency_key = ? AND route = ?
+	`)
+	row := tx.QueryRow(ctx, query, actorID, key, route)
+
+	record := &domain.IdempotencyRecord{}
+	var statusCode sql.NullInt64
+	var headers []byte
+	err := row.Scan(&record.ActorID, &record.Key, &record.Route, &record.RequestHash, &statusCode, &headers, &record.ResponseBody, &record.CreatedAt, &record.ExpiresAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	if statusCode.Valid {
+		record.StatusCode = int(statusCode.Int64)
+	}
+	if len(headers) > 0 {
+		if err := json.Unmarshal(headers, &record.Headers); err != nil {
+			return nil, err
+		}
+	}
+	return record, nil
+}
+
+func (r *IdempotencyRepo) Complete(ctx context.Context, record *domain.IdempotencyRecord) error {
+	headers, err := json.Marshal(record.Headers)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE idempotency_keys
+		SET status_code = :status_code, response_headers = :response_headers, response_body = :response_body
+		WHERE actor_id = :actor_id AND idempotency_key = :idempotency_key AND route = :route
+	`
+
+	_, err = sqlx.NamedExecContext(
+		ctx,
+		TxOrDb(ctx, r.db, r.dialect, "IdempotencyRepo.Complete"),
+		query,
+		map[string]interface{}{
+			"actor_id":         record.ActorID,
+			"idempotency_key":  record.Key,
+			"route":            record.Route,
+			"status_code":      record.StatusCode,
+			"response_headers": headers,
+			"response_body":    record.ResponseBody,
+		},
+	)
+	return err
+}
+
+func (r *IdempotencyRepo) Delete(ctx context.Context, actorID, key, route string) error {
+	query := r.db.Rebind("DELETE FROM idempotency_keys WHERE actor_id = ? AND idempotency_key = ? AND route = ?")
+
+	_, err := TxOrDb(ctx, r.db, r.dialect, "IdempotencyRepo.Delete").ExecContext(ctx, query, actorID, key, route)
+	return err
+}
@@ -0,0 +1,122 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"pullrequests/internal/domain"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type IdentityRepo struct {
+	db      *sqlx.DB
+	dialect Dialect
+}
+
+func NewIdentityRepo(db *sqlx.DB, dialect Dialect) *IdentityRepo {
+	return &IdentityRepo{db: db, dialect: dialect}
+}
+
+func (r *IdentityRepo) SetIdentity(ctx context.Context, identity *domain.ExternalIdentity) error {
+	query := `
+		INSERT INTO external_identities (provider, login, user_id, created_at)
+		VALUES (:provider, :login, :user_id, :created_at)
+		ON CONFLICT (provider, login) DO UPDATE SET user_id = EXCLUDED.user_id
+	`
+
+	_, err := sqlx.NamedExecContext(
+		ctx,
+		TxOrDb(ctx, r.db, r.dialect, "IdentityRepo.SetIdentity"),
+		query,
+		map[string]interface{}{
+			"provider":   identity.Provider,
+			"login":      identity.Login,
+			"user_id":    identity.UserID,
+			"created_at": utc(identity.CreatedAt),
+		},
+	)
+	return r.dialect.TranslateError(err)
+}
+
+func (r *IdentityRepo) GetIdentity(ctx context.Context, provider, login string) (*domain.ExternalIdentity, error) {
+	tx := TxOrDb(ctx, r.db, r.dialect, "IdentityRepo.GetIdentity")
+
+	query := r.db.Rebind(`
+		SELECT provider, login, user_id, created_at
+		FROM external_identities WHERE provider = ? AND login = ?
+	`)
+	identity := &domain.ExternalIdentity{}
+	err := tx.QueryRow(ctx, query, provider, login).Scan(&identity.Provider, &identity.Login, &identity.UserID, &identity.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return identity, nil
+}
+
+func (r *IdentityRepo) GetIdentityByUserID(ctx context.Context, provider, userID string) (*domain.ExternalIdentity, error) {
+	tx := TxOrDb(ctx, r.db, r.dialect, "IdentityRepo.GetIdentityByUserID")
+
+	query := r.db.Rebind(`
+		SELECT provider, login, user_id, created_at
+		FROM external_identities WHERE provider = ? AND user_id = ?
+		ORDER BY login LIMIT 1
+	`)
+	identity := &domain.ExternalIdentity{}
+	err := tx.QueryRow(ctx, query, provider, userID).Scan(&identity.Provider, &identity.Login, &identity.UserID, &identity.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return identity, nil
+}
+
+func (r *IdentityRepo) GetIdentities(ctx context.Context, provider string) ([]domain.ExternalIdentity, error) {
+	tx := TxOrDb(ctx, r.db, r.dialect, "IdentityRepo.GetIdentities")
+
+	query := r.db.Rebind(`
+		SELECT provider, login, user_id, created_at
+		FROM external_identities WHERE provider = ? ORDER BY login
+	`)
+	rows, err := tx.Query(ctx, query, provider)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	identities := []domain.ExternalIdentity{}
+	for rows.Next() {
+		var identity domain.ExternalIdentity
+		if err := rows.Scan(&identity.Provider, &identity.Login, &identity.UserID, &identity.CreatedAt); err != nil {
+			return nil, err
+		}
+		identities = append(identities, identity)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return identities, nil
+}
+
+func (r *IdentityRepo) DeleteIdentity(ctx context.Context, provider, login string) error {
+	query := r.db.Rebind("DELETE FROM external_identities WHERE provider = ? AND login = ?")
+
+	result, err := TxOrDb(ctx, r.db, r.dialect, "IdentityRepo.DeleteIdentity").ExecContext(ctx, query, provider, login)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return domain.NewDomainError(domain.ErrNotFoundCode)
+	}
+	return nil
+}
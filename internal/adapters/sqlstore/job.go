@@ -0,0 +1,110 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"pullrequests/internal/domain"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type JobRepo struct {
+	db      *sqlx.DB
+	dialect Dialect
+}
+
+func NewJobRepo(db *sqlx.DB, dialect Dialect) *JobRepo {
+	return &JobRepo{db: db, dialect: dialect}
+}
+
+func (r *JobRepo) AddJob(ctx context.Context, job *domain.Job) error {
+	query := `
+		INSERT INTO jobs (
+			job_id, kind, payload, status, attempts,
+			next_attempt_at, last_error, created_at, finished_at
+		)
+		VALUES (
+			:job_id, :kind, :payload, :status, :attempts,
+			:next_attempt_at, :last_error, :created_at, :finished_at
+		)
+	`
+
+	_, err := sqlx.NamedExecContext(
+		ctx,
+		TxOrDb(ctx, r.db, r.dialect, "JobRepo.AddJob"),
+		query,
+		r.toJobRow(job),
+	)
+	return r.dialect.TranslateError(err)
+}
+
+func (r *JobRepo) GetDueJobs(ctx context.Context, kind domain.JobKind, now time.Time, limit int) ([]domain.Job, error) {
+	query := r.db.Rebind(`
+		SELECT job_id, kind, payload, status, attempts,
+			next_attempt_at, last_error, created_at, finished_at
+		FROM jobs
+		WHERE kind = ? AND status = 'PENDING' AND next_attempt_at <= ?
+		ORDER BY next_attempt_at, job_id
+		LIMIT ?
+	` + r.dialect.SkipLocked)
+	rows, err := TxOrDb(ctx, r.db, r.dialect, "JobRepo.GetDueJobs").Query(ctx, query, kind, utc(now), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	jobs := []domain.Job{}
+	for rows.Next() {
+		job := domain.Job{}
+		var finishedAt sql.NullTime
+		err := rows.Scan(
+			&job.JobID, &job.Kind, &job.Payload, &job.Status, &job.Attempts,
+			&job.NextAttemptAt, &job.LastError, &job.CreatedAt, &finishedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if finishedAt.Valid {
+			job.FinishedAt = &finishedAt.Time
+		}
+		jobs = append(jobs, job)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return jobs, nil
+}
+
+func (r *JobRepo) UpdateJob(ctx context.Context, job *domain.Job) error {
+	query := `
+		UPDATE jobs
+		SET status = :status, attempts = :attempts, next_attempt_at = :next_attempt_at,
+			last_error = :last_error, finished_at = :finished_at
+		WHERE job_id = :job_id
+	`
+
+	_, err := sqlx.NamedExecContext(
+		ctx,
+		TxOrDb(ctx, r.db, r.dialect, "JobRepo.UpdateJob"),
+		query,
+		r.toJobRow(job),
+	)
+	return err
+}
+
+func (r *JobRepo) toJobRow(job *domain.Job) map[string]interface{} {
+	return map[string]interface{}{
+		"job_id":          job.JobID,
+		"kind":            job.Kind,
+		"payload":         job.Payload,
+		"status":          job.Status,
+		"attempts":        job.Attempts,
+		"next_attempt_at": utc(job.NextAttemptAt),
+		"last_error":      job.LastError,
+		"created_at":      utc(job.CreatedAt),
+		"finished_at":     utcPtr(job.FinishedAt),
+	}
+}
@@ -0,0 +1,152 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"pullrequests/internal/domain"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type NotificationRepo struct {
+	db      *sqlx.DB
+	dialect Dialect
+}
+
+func NewNotificationRepo(db *sqlx.DB, dialect Dialect) *NotificationRepo {
+	return &NotificationRepo{db: db, dialect: dialect}
+}
+
+func (r *NotificationRepo) SetPreferences(ctx context.Context, preferences *domain.NotificationPreferences) error {
+	query := `
+		INSERT INTO notification_preferences (
+			user_id, chat_enabled, chat_channel, chat_events,
+			email, digest_enabled, digest_hour, timezone, updated_at
+		)
+		VALUES (
+			:user_id, :chat_enabled, :chat_channel, :chat_events,
+			:email, :digest_enabled, :digest_hour, :timezone, :updated_at
+		)
+		ON CONFLICT (user_id) DO UPDATE SET
+			chat_enabled = EXCLUDED.chat_enabled,
+			chat_channel = EXCLUDED.chat_channel,
+			chat_events = EXCLUDED.chat_events,
+			email = EXCLUDED.email,
+			digest_enabled = EXCLUDED.digest_enabled,
+			digest_hour = EXCLUDED.digest_hour,
+			timezone = EXCLUDED.timezone,
+			updated_at = EXCLUDED.updated_at
+	`
+
+	_, err := sqlx.NamedExecContext(
+		ctx,
+		TxOrDb(ctx, r.db, r.dialect, "NotificationRepo.SetPreferences"),
+		query,
+		map[string]interface{}{
+			"user_id":        preferences.UserID,
+			"chat_enabled":   preferences.ChatEnabled,
+			"chat_channel":   preferences.ChatChannel,
+			"chat_events":    joinEvents(preferences.ChatEvents),
+			"email":          preferences.Email,
+			"digest_enabled": preferences.DigestEnabled,
+			"digest_hour":    preferences.DigestHour,
+			"timezone":       preferences.Timezone,
+			"updated_at":     utc(preferences.UpdatedAt),
+		},
+	)
+	return r.dialect.TranslateError(err)
+}
+
+func (r *NotificationRepo) GetPreferences(ctx context.Context, userID string) (*domain.NotificationPreferences, error) {
+	tx := TxOrDb(ctx, r.db, r.dialect, "NotificationRepo.GetPreferences")
+
+	query := r.db.Rebind(`
+		SELECT user_id, chat_enabled, chat_channel, chat_events,
+			email, digest_enabled, digest_hour, timezone, digest_sent_at, updated_at
+		FROM notification_preferences WHERE user_id = ?
+	`)
+	preferences, err := r.scanPreferences(tx.QueryRow(ctx, query, userID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return preferences, nil
+}
+
+func (r *NotificationRepo) GetDigestRecipients(ctx context.Context) ([]domain.NotificationPreferences, error) {
+	tx := TxOrDb(ctx, r.db, r.dialect, "NotificationRepo.GetDigestRecipients")
+
+	query := `
+		SELECT user_id, chat_enabled, chat_channel, chat_events,
+			email, digest_enabled, digest_hour, timezone, digest_sent_at, updated_at
+		FROM notification_preferences
+		WHERE digest_enabled AND email <> ''
+		ORDER BY user_id
+	`
+	rows, err := tx.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	recipients := []domain.NotificationPreferences{}
+	for rows.Next() {
+		preferences, err := r.scanPreferences(rows)
+		if err != nil {
+			return nil, err
+		}
+		recipients = append(recipients, *preferences)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return recipients, nil
+}
+
+func (r *NotificationRepo) SetDigestSentAt(ctx context.Context, userID string, sentAt *time.Time) error {
+	query := r.db.Rebind("UPDATE notification_preferences SET digest_sent_at = ? WHERE user_id = ?")
+
+	result, err := TxOrDb(ctx, r.db, r.dialect, "NotificationRepo.SetDigestSentAt").ExecContext(ctx, query, utcPtr(sentAt), userID)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return domain.NewDomainError(domain.ErrNotFoundCode)
+	}
+	return nil
+}
+
+func (r *NotificationRepo) scanPreferences(row sqlx.ColScanner) (*domain.NotificationPreferences, error) {
+	preferences := &domain.NotificationPreferences{}
+	var events string
+	var digestSentAt sql.NullTime
+	err := row.Scan(
+		&preferences.UserID,
+		&preferences.ChatEnabled,
+		&preferences.ChatChannel,
+		&events,
+		&preferences.Email,
+		&preferences.DigestEnabled,
+		&preferences.DigestHour,
+		&preferences.Timezone,
+		&digestSentAt,
+		&preferences.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	preferences.ChatEvents = splitEvents(events)
+	if digestSentAt.Valid {
+		preferences.DigestSentAt = &digestSentAt.Time
+	}
+	return preferences, nil
+}
@@ -0,0 +1,116 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"pullrequests/internal/domain"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type OutboxRepo struct {
+	db      *sqlx.DB
+	dialect Dialect
+}
+
+func NewOutboxRepo(db *sqlx.DB, dialect Dialect) *OutboxRepo {
+	return &OutboxRepo{db: db, dialect: dialect}
+}
+
+func (r *OutboxRepo) Add(ctx context.Context, message *domain.OutboxMessage) error {
+	query := `
+		INSERT INTO outbox_events (
+			event_id, event_type, payload, occurred_at, status,
+			attempts, next_attempt_at, last_error, processed_at, delivered_sinks
+		)
+		VALUES (
+			:event_id, :event_type, :payload, :occurred_at, :status,
+			:attempts, :next_attempt_at, :last_error, :processed_at, :delivered_sinks
+		)
+	`
+
+	_, err := sqlx.NamedExecContext(
+		ctx,
+		TxOrDb(ctx, r.db, r.dialect, "OutboxRepo.Add"),
+		query,
+		r.toMessageRow(message),
+	)
+	return r.dialect.TranslateError(err)
+}
+
+func (r *OutboxRepo) GetPending(ctx context.Context, now time.Time, limit int) ([]domain.OutboxMessage, error) {
+	query := r.db.Rebind(`
+		SELECT sequence, event_id, event_type, payload, occurred_at, status,
+			attempts, next_attempt_at, last_error, processed_at, delivered_sinks
+		FROM outbox_events
+		WHERE status = 'PENDING' AND next_attempt_at <= ?
+		ORDER BY sequence
+		LIMIT ?
+	` + r.dialect.SkipLocked)
+	rows, err := TxOrDb(ctx, r.db, r.dialect, "OutboxRepo.GetPending").Query(ctx, query, utc(now), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	messages := []domain.OutboxMessage{}
+	for rows.Next() {
+		message := domain.OutboxMessage{}
+		var processedAt sql.NullTime
+		var deliveredSinks string
+		err := rows.Scan(
+			&message.Sequence, &message.EventID, &message.EventType, &message.Payload, &message.OccurredAt, &message.Status,
+			&message.Attempts, &message.NextAttemptAt, &message.LastError, &processedAt, &deliveredSinks,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if processedAt.Valid {
+			message.ProcessedAt = &processedAt.Time
+		}
+		if deliveredSinks != "" {
+			message.DeliveredSinks = strings.Split(deliveredSinks, ",")
+		}
+		messages = append(messages, message)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return messages, nil
+}
+
+func (r *OutboxRepo) Update(ctx context.Context, message *domain.OutboxMessage) error {
+	query := `
+		UPDATE outbox_events
+		SET status = :status, attempts = :attempts, next_attempt_at = :next_attempt_at,
+			last_error = :last_error, processed_at = :processed_at, delivered_sinks = :delivered_sinks
+		WHERE event_id = :event_id
+	`
+
+	_, err := sqlx.NamedExecContext(
+		ctx,
+		TxOrDb(ctx, r.db, r.dialect, "OutboxRepo.Update"),
+		query,
+		r.toMessageRow(message),
+	)
+	return err
+}
+
+func (r *OutboxRepo) toMessageRow(message *domain.OutboxMessage) map[string]interface{} {
+	return map[string]interface{}{
+		"event_id":        message.EventID,
+		"event_type":      message.EventType,
+		"payload":         message.Payload,
+		"occurred_at":     utc(message.OccurredAt),
+		"status":          message.Status,
+		"attempts":        message.Attempts,
+		"next_attempt_at": utc(message.NextAttemptAt),
+		"last_error":      message.LastError,
+		"processed_at":    utcPtr(message.ProcessedAt),
+		"delivered_sinks": strings.Join(message.DeliveredSinks, ","),
+	}
+}
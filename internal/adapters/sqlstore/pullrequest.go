@@ -0,0 +1,302 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"pullrequests/internal/domain"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type PRRepo struct {
+	db      *sqlx.DB
+	dialect Dialect
+}
+
+func NewPRRepo(db *sqlx.DB, dialect Dialect) *PRRepo {
+	return &PRRepo{db: db, dialect: dialect}
+}
+
+func (r *PRRepo) Add(ctx context.Context, pullrequest *domain.PullRequest) error {
+	query := `
+		INSERT INTO pull_requests (pull_request_id, pull_request_name, author_id, status, created_at, version)
+		VALUES (:pull_request_id, :pull_request_name, :author_id, :status, :created_at, 1)
+	`
+
+	_, err := sqlx.NamedExecContext(
+		ctx,
+		TxOrDb(ctx, r.db, r.dialect, "PRRepo.Add"),
+		query,
+		r.toPRRow(pullrequest),
+	)
+	if err != nil {
+		return r.dialect.TranslateError(err)
+	}
+	pullrequest.Version = 1
+	return nil
+}
+
+func (r *PRRepo) GetPullRequestByID(ctx context.Context, pullrequestID string) (*domain.PullRequest, error) {
+	tx := TxOrDb(ctx, r.db, r.dialect, "PRRepo.GetPullRequestByID")
+
+	pullrequest := &domain.PullRequest{}
+	query := r.db.Rebind(`
+        SELECT pull_request_id, pull_request_name, author_id, status, created_at, merged_at, version
+        FROM pull_requests WHERE pull_request_id = ?
+    `)
+	row := tx.QueryRow(ctx, query, pullrequestID)
+
+	var mergedAt sql.NullTime
+	err := row.Scan(&pullrequest.PullRequestID, &pullrequest.PullRequestName, &pullrequest.AuthorID, &pullrequest.Status, &pullrequest.CreatedAt, &mergedAt, &pullrequest.Version)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	if mergedAt.Valid {
+		pullrequest.MergedAt = &mergedAt.Time
+	}
+
+	return pullrequest, nil
+}
+
+func (r *PRRepo) UpdatePullRequest(ctx context.Context, pullrequest *domain.PullRequest) error {
+	query := `
+        UPDATE pull_requests
+        SET pull_request_name = :pull_request_name, author_id = :author_id,
+            status = :status, created_at = :created_at, merged_at = :merged_at,
+            version = version + 1
+        WHERE pull_request_id = :pull_request_id AND version = :version
+    `
+
+	result, err := sqlx.NamedExecContext(
+		ctx,
+		TxOrDb(ctx, r.db, r.dialect, "PRRepo.UpdatePullRequest"),
+		query,
+		r.toPRRow(pullrequest),
+	)
+	if err != nil {
+		return r.dialect.TranslateError(err)
+	}
+	return r.checkVersionBumped(result, pullrequest)
+}
+
+func (r *PRRepo) AddReviewer(ctx context.Context, pullrequest *domain.PullRequest, userID string) error {
+	if err := r.bumpVersion(ctx, pullrequest); err != nil {
+		return err
+	}
+
+	query := "INSERT INTO pull_request_reviewers (pull_request_id, user_id) VALUES (:pull_request_id, :user_id)"
+
+	_, err := sqlx.NamedExecContext(
+		ctx,
+		TxOrDb(ctx, r.db, r.dialect, "PRRepo.AddReviewer"),
+		query,
+		map[string]interface{}{
+			"pull_request_id": pullrequest.PullRequestID,
+			"user_id":         userID,
+		},
+	)
+	return r.dialect.TranslateError(err)
+}
+
+func (r *PRRepo) GetReviewers(ctx context.Context, pullrequestID string) ([]domain.PullRequestReviewer, error) {
+	tx := TxOrDb(ctx, r.db, r.dialect, "PRRepo.GetReviewers")
+	query := r.db.Rebind("SELECT pull_request_id, user_id FROM pull_request_reviewers WHERE pull_request_id = ? ORDER BY user_id")
+	rows, err := tx.Query(ctx, query, pullrequestID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reviewers []domain.PullRequestReviewer
+	for rows.Next() {
+		var reviewer domain.PullRequestReviewer
+		if err := rows.Scan(&reviewer.PullRequestID, &reviewer.UserID); err != nil {
+			return nil, err
+		}
+		reviewers = append(reviewers, reviewer)
+	}
+	return reviewers, nil
+}
+
+func (r *PRRepo) RemoveReviewer(ctx context.Context, pullrequest *domain.PullRequest, userID string) error {
+	if err := r.bumpVersion(ctx, pullrequest); err != nil {
+		return err
+	}
+
+	query := r.db.Rebind("DELETE FROM pull_request_reviewers WHERE pull_request_id = ? AND user_id = ?")
+
+	_, err := TxOrDb(ctx, r.db, r.dialect, "PRRepo.RemoveReviewer").ExecContext(ctx, query, pullrequest.PullRequestID, userID)
+	return r.dialect.TranslateError(err)
+}
+
+func (r *PRRepo) bumpVersion(ctx context.Context, pullrequest *domain.PullRequest) error {
+	query := r.db.Rebind("UPDATE pull_requests SET version = version + 1 WHERE pull_request_id = ? AND version = ?")
+
+	result, err := TxOrDb(ctx, r.db, r.dialect, "PRRepo.bumpVersion").ExecContext(ctx, query, pullrequest.PullRequestID, pullrequest.Version)
+	if err != nil {
+		return err
+	}
+	return r.checkVersionBumped(result, pullrequest)
+}
+
+func (r *PRRepo) checkVersionBumped(result sql.Result, pullrequest *domain.PullRequest) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return domain.NewDomainError(domain.ErrConflictCode)
+	}
+	pullrequest.Version++
+	return nil
+}
+
+func (r *PRRepo) toPRRow(pullrequest *domain.PullRequest) map[string]interface{} {
+	return map[string]interface{}{
+		"pull_request_id":   pullrequest.PullRequestID,
+		"pull_request_name": pullrequest.PullRequestName,
+		"author_id":         pullrequest.AuthorID,
+		"status":            pullrequest.Status,
+		"created_at":        utc(pullrequest.CreatedAt),
+		"merged_at":         utcPtr(pullrequest.MergedAt),
+		"version":           pullrequest.Version,
+	}
+}
+
+func (r *PRRepo) GetUserAssignedPRs(ctx context.Context, userID string) ([]domain.PullRequest, error) {
+	tx := TxOrDb(ctx, r.db, r.dialect, "PRRepo.GetUserAssignedPRs")
+
+	query := r.db.Rebind(`
+        SELECT
+            pr.pull_request_id,
+            pr.pull_request_name,
+            pr.author_id,
+            pr.status,
+            pr.created_at,
+            pr.merged_at,
+            pr.version
+        FROM pull_requests pr
+        JOIN pull_request_reviewers prr ON pr.pull_request_id = prr.pull_request_id
+        WHERE prr.user_id = ?
+        ORDER BY pr.created_at DESC
+    `)
+
+	rows, err := tx.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var pullrequests []domain.PullRequest
+	for rows.Next() {
+		var pullrequest domain.PullRequest
+		var mergedAt sql.NullTime
+
+		err := rows.Scan(
+			&pullrequest.PullRequestID,
+			&pullrequest.PullRequestName,
+			&pullrequest.AuthorID,
+			&pullrequest.Status,
+			&pullrequest.CreatedAt,
+			&mergedAt,
+			&pullrequest.Version,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		if mergedAt.Valid {
+			pullrequest.MergedAt = &mergedAt.Time
+		}
+
+		pullrequests = append(pullrequests, pullrequest)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return pullrequests, nil
+}
+
+func (r *PRRepo) GetReviewerStatsByTeamName(ctx context.Context, teamName string) ([]domain.ReviewerStat, error) {
+	tx := TxOrDb(ctx, r.db, r.dialect, "PRRepo.GetReviewerStatsByTeamName")
+
+	query := r.db.Rebind(`
+        SELECT
+            u.user_id,
+            u.username,
+            u.role,
+            COUNT(pr.pull_request_id) AS assignments,
+            COUNT(pr.pull_request_id) FILTER (WHERE pr.status = 'OPEN') AS open_assignments,
+            (
+                SELECT COUNT(*) FROM review_assignments ra
+                WHERE ra.reviewer_id = u.user_id AND ra.escalated
+            ) AS escalations
+        FROM users u
+        LEFT JOIN pull_request_reviewers prr ON prr.user_id = u.user_id
+        LEFT JOIN pull_requests pr ON pr.pull_request_id = prr.pull_request_id
+        WHERE u.team_name = ?
+        GROUP BY u.user_id, u.username, u.role
+        ORDER BY u.user_id
+    `)
+
+	rows, err := tx.Query(ctx, query, teamName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stats := []domain.ReviewerStat{}
+	for rows.Next() {
+		var stat domain.ReviewerStat
+		if err := rows.Scan(&stat.UserID, &stat.Username, &stat.Role, &stat.Assignments, &stat.OpenAssignments, &stat.Escalations); err != nil {
+			return nil, err
+		}
+		stats = append(stats, stat)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+func (r *PRRepo) CountOpenByTeam(ctx context.Context) (map[string]int, error) {
+	tx := TxOrDb(ctx, r.db, r.dialect, "PRRepo.CountOpenByTeam")
+
+	query := `
+        SELECT u.team_name, COUNT(*)
+        FROM pull_requests pr
+        JOIN users u ON u.user_id = pr.author_id
+        WHERE pr.status = 'OPEN'
+        GROUP BY u.team_name
+    `
+
+	rows, err := tx.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var teamName string
+		var open int
+		if err := rows.Scan(&teamName, &open); err != nil {
+			return nil, err
+		}
+		counts[teamName] = open
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return counts, nil
+}
@@ -0,0 +1,80 @@
+package sqlstore
+
+import (
+	"context"
+	"pullrequests/internal/domain"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type ReviewHistoryRepo struct {
+	db      *sqlx.DB
+	dialect Dialect
+}
+
+func NewReviewHistoryRepo(db *sqlx.DB, dialect Dialect) *ReviewHistoryRepo {
+	return &ReviewHistoryRepo{db: db, dialect: dialect}
+}
+
+func (r *ReviewHistoryRepo) Add(ctx context.Context, assignment *domain.ReviewAssignment) error {
+	query := `
+		INSERT INTO review_assignments (pull_request_id, author_id, reviewer_id, assigned_at, escalated)
+		VALUES (:pull_request_id, :author_id, :reviewer_id, :assigned_at, :escalated)
+	`
+
+	_, err := sqlx.NamedExecContext(
+		ctx,
+		TxOrDb(ctx, r.db, r.dialect, "ReviewHistoryRepo.Add"),
+		query,
+		r.toAssignmentRow(assignment),
+	)
+	return r.dialect.TranslateError(err)
+}
+
+func (r *ReviewHistoryRepo) GetLastAssignedAtByAuthor(ctx context.Context, authorID string) (map[string]time.Time, error) {
+	tx := TxOrDb(ctx, r.db, r.dialect, "ReviewHistoryRepo.GetLastAssignedAtByAuthor")
+	// The latest row is picked with NOT EXISTS rather than MAX so that the
+	// column keeps its type: SQLite returns MAX of a timestamp as text.
+	query := r.db.Rebind(`
+		SELECT ra.reviewer_id, ra.assigned_at
+		FROM review_assignments ra
+		WHERE ra.author_id = ? AND NOT EXISTS (
+			SELECT 1 FROM review_assignments newer
+			WHERE newer.author_id = ra.author_id
+				AND newer.reviewer_id = ra.reviewer_id
+				AND newer.assigned_at > ra.assigned_at
+		)
+	`)
+	rows, err := tx.Query(ctx, query, authorID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	lastAssignedAt := make(map[string]time.Time)
+	for rows.Next() {
+		var reviewerID string
+		var assignedAt time.Time
+		if err := rows.Scan(&reviewerID, &assignedAt); err != nil {
+			return nil, err
+		}
+		lastAssignedAt[reviewerID] = assignedAt
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return lastAssignedAt, nil
+}
+
+func (r *ReviewHistoryRepo) toAssignmentRow(assignment *domain.ReviewAssignment) map[string]interface{} {
+	return map[string]interface{}{
+		"pull_request_id": assignment.PullRequestID,
+		"author_id":       assignment.AuthorID,
+		"reviewer_id":     assignment.ReviewerID,
+		"assigned_at":     utc(assignment.AssignedAt),
+		"escalated":       assignment.Escalated,
+	}
+}
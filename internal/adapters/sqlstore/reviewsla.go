@@ -0,0 +1,159 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"pullrequests/internal/domain"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type ReviewSLARepo struct {
+	db      *sqlx.DB
+	dialect Dialect
+}
+
+func NewReviewSLARepo(db *sqlx.DB, dialect Dialect) *ReviewSLARepo {
+	return &ReviewSLARepo{db: db, dialect: dialect}
+}
+
+func (r *ReviewSLARepo) SetSLA(ctx context.Context, sla *domain.ReviewSLA) error {
+	query := `
+		INSERT INTO team_review_slas (team_name, remind_after_seconds, escalate_after_seconds, updated_at)
+		VALUES (:team_name, :remind_after_seconds, :escalate_after_seconds, :updated_at)
+		ON CONFLICT (team_name) DO UPDATE SET
+			remind_after_seconds = EXCLUDED.remind_after_seconds,
+			escalate_after_seconds = EXCLUDED.escalate_after_seconds,
+			updated_at = EXCLUDED.updated_at
+	`
+
+	_, err := sqlx.NamedExecContext(
+		ctx,
+		TxOrDb(ctx, r.db, r.dialect, "ReviewSLARepo.SetSLA"),
+		query,
+		map[string]interface{}{
+			"team_name":              sla.TeamName,
+			"remind_after_seconds":   int64(sla.RemindAfter / time.Second),
+			"escalate_after_seconds": int64(sla.EscalateAfter / time.Second),
+			"updated_at":             utc(sla.UpdatedAt),
+		},
+	)
+	return r.dialect.TranslateError(err)
+}
+
+func (r *ReviewSLARepo) GetSLA(ctx context.Context, teamName string) (*domain.ReviewSLA, error) {
+	tx := TxOrDb(ctx, r.db, r.dialect, "ReviewSLARepo.GetSLA")
+
+	query := r.db.Rebind(`
+		SELECT team_name, remind_after_seconds, escalate_after_seconds, updated_at
+		FROM team_review_slas WHERE team_name = ?
+	`)
+	sla, err := r.scanSLA(tx.QueryRow(ctx, query, teamName))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return sla, nil
+}
+
+func (r *ReviewSLARepo) GetSLAs(ctx context.Context) ([]domain.ReviewSLA, error) {
+	tx := TxOrDb(ctx, r.db, r.dialect, "ReviewSLARepo.GetSLAs")
+
+	query := `
+		SELECT team_name, remind_after_seconds, escalate_after_seconds, updated_at
+		FROM team_review_slas ORDER BY team_name
+	`
+	rows, err := tx.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	slas := []domain.ReviewSLA{}
+	for rows.Next() {
+		sla, err := r.scanSLA(rows)
+		if err != nil {
+			return nil, err
+		}
+		slas = append(slas, *sla)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return slas, nil
+}
+
+func (r *ReviewSLARepo) GetOpenReviews(ctx context.Context, createdBefore time.Time) ([]domain.OpenReview, error) {
+	tx := TxOrDb(ctx, r.db, r.dialect, "ReviewSLARepo.GetOpenReviews")
+
+	query := r.db.Rebind(`
+		SELECT pr.pull_request_id, pr.author_id, u.team_name, pr.created_at, s.reminded_at, s.escalated_at
+		FROM pull_requests pr
+		JOIN users u ON u.user_id = pr.author_id
+		LEFT JOIN pull_request_sla s ON s.pull_request_id = pr.pull_request_id
+		WHERE pr.status = 'OPEN' AND pr.created_at < ?
+		ORDER BY pr.created_at, pr.pull_request_id
+	`)
+	rows, err := tx.Query(ctx, query, utc(createdBefore))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reviews := []domain.OpenReview{}
+	for rows.Next() {
+		var review domain.OpenReview
+		var remindedAt, escalatedAt sql.NullTime
+		err := rows.Scan(&review.PullRequestID, &review.AuthorID, &review.TeamName, &review.CreatedAt, &remindedAt, &escalatedAt)
+		if err != nil {
+			return nil, err
+		}
+		if remindedAt.Valid {
+			review.RemindedAt = &remindedAt.Time
+		}
+		if escalatedAt.Valid {
+			review.EscalatedAt = &escalatedAt.Time
+		}
+		reviews = append(reviews, review)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return reviews, nil
+}
+
+func (r *ReviewSLARepo) MarkReminded(ctx context.Context, pullRequestID string, at time.Time) error {
+	query := r.db.Rebind(`
+		INSERT INTO pull_request_sla (pull_request_id, reminded_at) VALUES (?, ?)
+		ON CONFLICT (pull_request_id) DO UPDATE SET reminded_at = EXCLUDED.reminded_at
+	`)
+	_, err := TxOrDb(ctx, r.db, r.dialect, "ReviewSLARepo.MarkReminded").ExecContext(ctx, query, pullRequestID, utc(at))
+	return r.dialect.TranslateError(err)
+}
+
+func (r *ReviewSLARepo) MarkEscalated(ctx context.Context, pullRequestID string, at time.Time) error {
+	query := r.db.Rebind(`
+		INSERT INTO pull_request_sla (pull_request_id, escalated_at) VALUES (?, ?)
+		ON CONFLICT (pull_request_id) DO UPDATE SET escalated_at = EXCLUDED.escalated_at
+	`)
+	_, err := TxOrDb(ctx, r.db, r.dialect, "ReviewSLARepo.MarkEscalated").ExecContext(ctx, query, pullRequestID, utc(at))
+	return r.dialect.TranslateError(err)
+}
+
+func (r *ReviewSLARepo) scanSLA(row sqlx.ColScanner) (*domain.ReviewSLA, error) {
+	sla := &domain.ReviewSLA{}
+	var remindAfter, escalateAfter int64
+	if err := row.Scan(&sla.TeamName, &remindAfter, &escalateAfter, &sla.UpdatedAt); err != nil {
+		return nil, err
+	}
+	sla.RemindAfter = time.Duration(remindAfter) * time.Second
+	sla.EscalateAfter = time.Duration(escalateAfter) * time.Second
+	return sla, nil
+}
@@ -0,0 +1,82 @@
+// Package sqlstore implements the repositories once for every SQL database.
+// Queries use ? placeholders rebound for the driver; what differs between
+// databases is described by a Dialect.
+package sqlstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"pullrequests/internal/tracing"
+	"sync/atomic"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type Dialect struct {
+	// System names the database in query spans, e.g. "postgresql".
+	System string
+	// SkipLocked ends queries that claim pending rows so that concurrent
+	// workers skip each other's rows; empty where the database locks whole files.
+	SkipLocked string
+	// TranslateError maps constraint violations to domain errors.
+	TranslateError func(error) error
+}
+
+type transactionKey struct{}
+
+// WithTx binds tx to ctx for the repositories; transaction managers call it.
+func WithTx(ctx context.Context, tx *sqlx.Tx) context.Context {
+	return context.WithValue(ctx, transactionKey{}, tx)
+}
+
+func TxFromContext(ctx context.Context) *sqlx.Tx {
+	if tx, ok := ctx.Value(transactionKey{}).(*sqlx.Tx); ok {
+		return tx
+	}
+	return nil
+}
+
+var savepoints atomic.Int64
+
+// Savepoint runs fn in a savepoint of the transaction bound to ctx. When fn
+// fails the transaction is rolled back to the savepoint, which also clears
+// the aborted state a failed statement leaves in PostgreSQL.
+func Savepoint(ctx context.Context, fn func(context.Context) error) error {
+	tx := TxFromContext(ctx)
+	name := fmt.Sprintf("sp_%d", savepoints.Add(1))
+	if _, err := tx.ExecContext(ctx, "SAVEPOINT "+name); err != nil {
+		return err
+	}
+	if err := fn(ctx); err != nil {
+		if _, rollbackErr := tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+name); rollbackErr != nil {
+			return errors.Join(err, rollbackErr)
+		}
+		return err
+	}
+	_, err := tx.ExecContext(ctx, "RELEASE SAVEPOINT "+name)
+	return err
+}
+
+// TxOrDb returns the transaction bound to ctx, or db outside transactions,
+// with query spans named name, e.g. "PRRepo.GetPullRequestByID".
+func TxOrDb(ctx context.Context, db *sqlx.DB, dialect Dialect, name string) *tracing.Queries {
+	if tx := TxFromContext(ctx); tx != nil {
+		return tracing.WrapQueries(tx, dialect.System, name)
+	}
+	return tracing.WrapQueries(db, dialect.System, name)
+}
+
+// utc normalizes written timestamps: SQLite stores them as text, which only
+// compares correctly when every value has the same zone.
+func utc(t time.Time) time.Time {
+	return t.UTC()
+}
+
+func utcPtr(t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+	return t.UTC()
+}
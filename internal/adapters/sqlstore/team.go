@@ -0,0 +1,99 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"pullrequests/internal/domain"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type TeamRepo struct {
+	db      *sqlx.DB
+	dialect Dialect
+}
+
+func NewTeamRepo(db *sqlx.DB, dialect Dialect) *TeamRepo {
+	return &TeamRepo{db: db, dialect: dialect}
+}
+
+func (r *TeamRepo) Add(ctx context.Context, team *domain.Team) error {
+	query := "INSERT INTO teams (team_name) VALUES (:team_name)"
+
+	_, err := sqlx.NamedExecContext(
+		ctx,
+		TxOrDb(ctx, r.db, r.dialect, "TeamRepo.Add"),
+		query,
+		r.toTeamRow(team),
+	)
+	return r.dialect.TranslateError(err)
+}
+
+func (r *TeamRepo) AddTeamMember(
+	ctx context.Context,
+	teamName string,
+	teamMember *domain.TeamMember) error {
+	query := "INSERT INTO users (user_id, username, team_name, is_active, role) VALUES (:user_id, :username, :team_name, :is_active, :role)"
+
+	_, err := sqlx.NamedExecContext(
+		ctx,
+		TxOrDb(ctx, r.db, r.dialect, "TeamRepo.AddTeamMember"),
+		query,
+		r.toTeamMemberRow(teamName, teamMember),
+	)
+
+	return r.dialect.TranslateError(err)
+}
+
+func (r *TeamRepo) GetTeamByTeamName(ctx context.Context, teamName string) (*domain.Team, error) {
+	tx := TxOrDb(ctx, r.db, r.dialect, "TeamRepo.GetTeamByTeamName")
+
+	team := &domain.Team{}
+	query := r.db.Rebind("SELECT team_name FROM teams WHERE team_name = ?")
+	row := tx.QueryRow(ctx, query, teamName)
+
+	if err := row.Scan(&team.Name); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return team, nil
+}
+
+func (r *TeamRepo) GetTeamMembersByTeamName(ctx context.Context, teamName string) ([]domain.TeamMember, error) {
+	tx := TxOrDb(ctx, r.db, r.dialect, "TeamRepo.GetTeamMembersByTeamName")
+	query := r.db.Rebind("SELECT user_id, username, is_active, role FROM users WHERE team_name = ? ORDER BY user_id")
+	rows, err := tx.Query(ctx, query, teamName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	members := []domain.TeamMember{}
+	for rows.Next() {
+		var m domain.TeamMember
+		if err := rows.Scan(&m.UserID, &m.Username, &m.IsActive, &m.Role); err != nil {
+			return nil, err
+		}
+		members = append(members, m)
+	}
+
+	return members, nil
+}
+
+func (r *TeamRepo) toTeamRow(team *domain.Team) map[string]interface{} {
+	return map[string]interface{}{
+		"team_name": team.Name,
+	}
+}
+
+func (r *TeamRepo) toTeamMemberRow(teamName string, member *domain.TeamMember) map[string]interface{} {
+	return map[string]interface{}{
+		"user_id":   member.UserID,
+		"username":  member.Username,
+		"team_name": teamName,
+		"is_active": member.IsActive,
+		"role":      member.Role,
+	}
+}
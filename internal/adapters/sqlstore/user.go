@@ -0,0 +1,87 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"pullrequests/internal/domain"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type UserRepo struct {
+	db      *sqlx.DB
+	dialect Dialect
+}
+
+func NewUserRepo(db *sqlx.DB, dialect Dialect) *UserRepo {
+	return &UserRepo{db: db, dialect: dialect}
+}
+
+func (r *UserRepo) GetUserByID(ctx context.Context, userID string) (*domain.User, error) {
+	tx := TxOrDb(ctx, r.db, r.dialect, "UserRepo.GetUserByID")
+
+	user := &domain.User{}
+	query := r.db.Rebind(`
+		SELECT user_id, username, team_name, is_active, role
+		FROM users WHERE user_id = ?
+	`)
+	row := tx.QueryRow(ctx, query, userID)
+
+	if err := row.Scan(&user.UserID, &user.Username, &user.TeamName, &user.IsActive, &user.Role); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+func (r *UserRepo) UpdateUser(ctx context.Context, user *domain.User) error {
+	query := `
+		UPDATE users
+		SET username = :username, team_name = :team_name, is_active = :is_active, role = :role
+		WHERE user_id = :user_id
+	`
+
+	_, err := sqlx.NamedExecContext(
+		ctx,
+		TxOrDb(ctx, r.db, r.dialect, "UserRepo.UpdateUser"),
+		query,
+		r.toUserRow(user),
+	)
+	return r.dialect.TranslateError(err)
+}
+
+func (r *UserRepo) GetActiveUsersByTeamName(ctx context.Context, teamName string) ([]domain.User, error) {
+	tx := TxOrDb(ctx, r.db, r.dialect, "UserRepo.GetActiveUsersByTeamName")
+	query := r.db.Rebind(`
+		SELECT user_id, username, team_name, is_active, role
+		FROM users WHERE team_name = ? AND is_active = true
+		ORDER BY user_id
+	`)
+	rows, err := tx.Query(ctx, query, teamName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []domain.User
+	for rows.Next() {
+		var user domain.User
+		if err := rows.Scan(&user.UserID, &user.Username, &user.TeamName, &user.IsActive, &user.Role); err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+	return users, nil
+}
+
+func (r *UserRepo) toUserRow(user *domain.User) map[string]interface{} {
+	return map[string]interface{}{
+		"user_id":   user.UserID,
+		"username":  user.Username,
+		"team_name": user.TeamName,
+		"is_active": user.IsActive,
+		"role":      user.Role,
+	}
+}
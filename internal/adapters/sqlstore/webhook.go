@@ -0,0 +1,245 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"pullrequests/internal/domain"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type WebhookRepo struct {
+	db      *sqlx.DB
+	dialect Dialect
+}
+
+func NewWebhookRepo(db *sqlx.DB, dialect Dialect) *WebhookRepo {
+	return &WebhookRepo{db: db, dialect: dialect}
+}
+
+func (r *WebhookRepo) AddSubscription(ctx context.Context, subscription *domain.WebhookSubscription) error {
+	query := `
+		INSERT INTO webhook_subscriptions (subscription_id, url, secret, events, created_at)
+		VALUES (:subscription_id, :url, :secret, :events, :created_at)
+	`
+
+	_, err := sqlx.NamedExecContext(
+		ctx,
+		TxOrDb(ctx, r.db, r.dialect, "WebhookRepo.AddSubscription"),
+		query,
+		map[string]interface{}{
+			"subscription_id": subscription.SubscriptionID,
+			"url":             subscription.URL,
+			"secret":          subscription.Secret,
+			"events":          joinEvents(subscription.Events),
+			"created_at":      utc(subscription.CreatedAt),
+		},
+	)
+	return r.dialect.TranslateError(err)
+}
+
+func (r *WebhookRepo) GetSubscription(ctx context.Context, subscriptionID string) (*domain.WebhookSubscription, error) {
+	tx := TxOrDb(ctx, r.db, r.dialect, "WebhookRepo.GetSubscription")
+
+	query := r.db.Rebind(`
+		SELECT subscription_id, url, secret, events, created_at
+		FROM webhook_subscriptions WHERE subscription_id = ?
+	`)
+	subscription, err := r.scanSubscription(tx.QueryRow(ctx, query, subscriptionID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return subscription, nil
+}
+
+func (r *WebhookRepo) GetSubscriptions(ctx context.Context) ([]domain.WebhookSubscription, error) {
+	tx := TxOrDb(ctx, r.db, r.dialect, "WebhookRepo.GetSubscriptions")
+
+	query := `
+		SELECT subscription_id, url, secret, events, created_at
+		FROM webhook_subscriptions ORDER BY created_at, subscription_id
+	`
+	rows, err := tx.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	subscriptions := []domain.WebhookSubscription{}
+	for rows.Next() {
+		subscription, err := r.scanSubscription(rows)
+		if err != nil {
+			return nil, err
+		}
+		subscriptions = append(subscriptions, *subscription)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return subscriptions, nil
+}
+
+func (r *WebhookRepo) DeleteSubscription(ctx context.Context, subscriptionID string) error {
+	query := r.db.Rebind("DELETE FROM webhook_subscriptions WHERE subscription_id = ?")
+
+	result, err := TxOrDb(ctx, r.db, r.dialect, "WebhookRepo.DeleteSubscription").ExecContext(ctx, query, subscriptionID)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return domain.NewDomainError(domain.ErrNotFoundCode)
+	}
+	return nil
+}
+
+func (r *WebhookRepo) AddDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	query := `
+		INSERT INTO webhook_deliveries (
+			delivery_id, subscription_id, event_id, event_type, payload, status,
+			attempts, next_attempt_at, last_error, response_status, created_at, delivered_at
+		)
+		VALUES (
+			:delivery_id, :subscription_id, :event_id, :event_type, :payload, :status,
+			:attempts, :next_attempt_at, :last_error, :response_status, :created_at, :delivered_at
+		)
+	`
+
+	_, err := sqlx.NamedExecContext(
+		ctx,
+		TxOrDb(ctx, r.db, r.dialect, "WebhookRepo.AddDelivery"),
+		query,
+		r.toDeliveryRow(delivery),
+	)
+	return r.dialect.TranslateError(err)
+}
+
+func (r *WebhookRepo) GetDueDeliveries(ctx context.Context, now time.Time, limit int) ([]domain.WebhookDelivery, error) {
+	query := r.db.Rebind(`
+		SELECT delivery_id, subscription_id, event_id, event_type, payload, status,
+			attempts, next_attempt_at, last_error, response_status, created_at, delivered_at
+		FROM webhook_deliveries
+		WHERE status = 'PENDING' AND next_attempt_at <= ?
+		ORDER BY next_attempt_at, delivery_id
+		LIMIT ?
+	` + r.dialect.SkipLocked)
+	return r.queryDeliveries(ctx, "WebhookRepo.GetDueDeliveries", query, utc(now), limit)
+}
+
+func (r *WebhookRepo) UpdateDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	query := `
+		UPDATE webhook_deliveries
+		SET status = :status, attempts = :attempts, next_attempt_at = :next_attempt_at,
+			last_error = :last_error, response_status = :response_status, delivered_at = :delivered_at
+		WHERE delivery_id = :delivery_id
+	`
+
+	_, err := sqlx.NamedExecContext(
+		ctx,
+		TxOrDb(ctx, r.db, r.dialect, "WebhookRepo.UpdateDelivery"),
+		query,
+		r.toDeliveryRow(delivery),
+	)
+	return err
+}
+
+func (r *WebhookRepo) GetDeliveries(ctx context.Context, subscriptionID string, limit int) ([]domain.WebhookDelivery, error) {
+	query := r.db.Rebind(`
+		SELECT delivery_id, subscription_id, event_id, event_type, payload, status,
+			attempts, next_attempt_at, last_error, response_status, created_at, delivered_at
+		FROM webhook_deliveries
+		WHERE ? = '' OR subscription_id = ?
+		ORDER BY created_at DESC, delivery_id DESC
+		LIMIT ?
+	`)
+	return r.queryDeliveries(ctx, "WebhookRepo.GetDeliveries", query, subscriptionID, subscriptionID, limit)
+}
+
+func (r *WebhookRepo) queryDeliveries(ctx context.Context, name, query string, args ...interface{}) ([]domain.WebhookDelivery, error) {
+	rows, err := TxOrDb(ctx, r.db, r.dialect, name).Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := []domain.WebhookDelivery{}
+	for rows.Next() {
+		delivery := domain.WebhookDelivery{}
+		var deliveredAt sql.NullTime
+		err := rows.Scan(
+			&delivery.DeliveryID, &delivery.SubscriptionID, &delivery.EventID, &delivery.EventType,
+			&delivery.Payload, &delivery.Status, &delivery.Attempts, &delivery.NextAttemptAt,
+			&delivery.LastError, &delivery.ResponseStatus, &delivery.CreatedAt, &deliveredAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if deliveredAt.Valid {
+			delivery.DeliveredAt = &deliveredAt.Time
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+func (r *WebhookRepo) scanSubscription(row sqlx.ColScanner) (*domain.WebhookSubscription, error) {
+	subscription := &domain.WebhookSubscription{}
+	var events string
+	if err := row.Scan(&subscription.SubscriptionID, &subscription.URL, &subscription.Secret, &events, &subscription.CreatedAt); err != nil {
+		return nil, err
+	}
+	subscription.Events = splitEvents(events)
+	return subscription, nil
+}
+
+func (r *WebhookRepo) toDeliveryRow(delivery *domain.WebhookDelivery) map[string]interface{} {
+	return map[string]interface{}{
+		"delivery_id":     delivery.DeliveryID,
+		"subscription_id": delivery.SubscriptionID,
+		"event_id":        delivery.EventID,
+		"event_type":      delivery.EventType,
+		"payload":         delivery.Payload,
+		"status":          delivery.Status,
+		"attempts":        delivery.Attempts,
+		"next_attempt_at": utc(delivery.NextAttemptAt),
+		"last_error":      delivery.LastError,
+		"response_status": delivery.ResponseStatus,
+		"created_at":      utc(delivery.CreatedAt),
+		"delivered_at":    utcPtr(delivery.DeliveredAt),
+	}
+}
+
+func joinEvents(events []domain.EventType) string {
+	names := make([]string, 0, len(events))
+	for _, event := range events {
+		names = append(names, string(event))
+	}
+	return strings.Join(names, ",")
+}
+
+func splitEvents(value string) []domain.EventType {
+	if value == "" {
+		return nil
+	}
+	names := strings.Split(value, ",")
+	events := make([]domain.EventType, 0, len(names))
+	for _, name := range names {
+		events = append(events, domain.EventType(name))
+	}
+	return events
+}
@@ -3,11 +3,108 @@ package config
 import (
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	StoragePostgres = "postgres"
+	StorageMemory   = "memory"
+	StorageSQLite   = "sqlite"
 )
 
 type Config struct {
-	Server struct {
-		Port string
+	Storage string
+	Server  struct {
+		Port             string
+		DrainDelay       time.Duration
+		ReadinessTimeout time.Duration
+	}
+	Log struct {
+		Level string
+	}
+	Assignment struct {
+		Strategy string
+	}
+	Idempotency struct {
+		TTL time.Duration
+	}
+	Auth struct {
+		Enabled    bool
+		AdminToken string
+	}
+	Integrations struct {
+		GitHubSecret   string
+		GitLabToken    string
+		GitHubToken    string
+		GitHubAPIURL   string
+		GitLabAPIURL   string
+		GitLabAPIToken string
+	}
+	Chat struct {
+		WebhookURL    string
+		Username      string
+		TeamChannels  map[string]string
+		TemplatesFile string
+		Timeout       time.Duration
+	}
+	SMTP struct {
+		Host     string
+		Port     int
+		Username string
+		Password string
+		From     string
+		StartTLS bool
+		Timeout  time.Duration
+	}
+	Digest struct {
+		PollInterval time.Duration
+	}
+	ReviewSLA struct {
+		RemindAfter   time.Duration
+		EscalateAfter time.Duration
+		PollInterval  time.Duration
+		LockKey       int64
+	}
+	Tracing struct {
+		Exporter    string
+		ServiceName string
+	}
+	Outbox struct {
+		PollInterval time.Duration
+		MaxAttempts  int
+		LogEvents    bool
+	}
+	Webhooks struct {
+		PollInterval time.Duration
+		Timeout      time.Duration
+		MaxAttempts  int
+		RetryBase    time.Duration
+		RetryMax     time.Duration
+	}
+	Jobs struct {
+		PollInterval time.Duration
+		MaxAttempts  int
+		RetryBase    time.Duration
+		RetryMax     time.Duration
+	}
+	JWT struct {
+		JWKSFile    string
+		JWKSURL     string
+		JWKSRefresh time.Duration
+		Issuer      string
+		Audience    string
+		UserClaim   string
+		GroupsClaim string
+		GroupRoles  map[string]string
+		DefaultRole string
+	}
+	SQLite struct {
+		Path string
+	}
+	Migrations struct {
+		OnStart bool
 	}
 	Database struct {
 		Host     string
@@ -33,7 +130,77 @@ func (c *Config) GetConnectionString() string {
 func LoadConfig() *Config {
 	cfg := &Config{}
 
+	cfg.Storage = getEnv("STORAGE", StoragePostgres)
 	cfg.Server.Port = getEnv("SERVER_PORT", "8080")
+	cfg.Server.DrainDelay = getDurationEnv("SHUTDOWN_DRAIN_DELAY", 5*time.Second)
+	cfg.Server.ReadinessTimeout = getDurationEnv("READINESS_TIMEOUT", 2*time.Second)
+	cfg.Log.Level = getEnv("LOG_LEVEL", "info")
+
+	cfg.Assignment.Strategy = getEnv("REVIEWER_STRATEGY", "first_n")
+
+	cfg.Idempotency.TTL = getDurationEnv("IDEMPOTENCY_TTL", 24*time.Hour)
+
+	// Authentication can only be switched off explicitly.
+	cfg.Auth.Enabled = getEnv("AUTH_ENABLED", "true") != "false"
+	cfg.Auth.AdminToken = getEnv("AUTH_ADMIN_TOKEN", "")
+
+	cfg.Integrations.GitHubSecret = getEnv("GITHUB_WEBHOOK_SECRET", "")
+	cfg.Integrations.GitLabToken = getEnv("GITLAB_WEBHOOK_TOKEN", "")
+	cfg.Integrations.GitHubToken = getEnv("GITHUB_TOKEN", "")
+	cfg.Integrations.GitHubAPIURL = getEnv("GITHUB_API_URL", "https://api.github.com")
+	cfg.Integrations.GitLabAPIURL = getEnv("GITLAB_API_URL", "https://gitlab.com/api/v4")
+	cfg.Integrations.GitLabAPIToken = getEnv("GITLAB_API_TOKEN", "")
+
+	cfg.Chat.WebhookURL = getEnv("CHAT_WEBHOOK_URL", "")
+	cfg.Chat.Username = getEnv("CHAT_USERNAME", "pullrequests")
+	cfg.Chat.TeamChannels = getMapEnv("CHAT_TEAM_CHANNELS", "")
+	cfg.Chat.TemplatesFile = getEnv("CHAT_TEMPLATES_FILE", "")
+	cfg.Chat.Timeout = getDurationEnv("CHAT_TIMEOUT", 10*time.Second)
+
+	cfg.SMTP.Host = getEnv("SMTP_HOST", "")
+	cfg.SMTP.Port = getIntEnv("SMTP_PORT", 25)
+	cfg.SMTP.Username = getEnv("SMTP_USERNAME", "")
+	cfg.SMTP.Password = getEnv("SMTP_PASSWORD", "")
+	cfg.SMTP.From = getEnv("SMTP_FROM", "pullrequests@localhost")
+	cfg.SMTP.StartTLS = getEnv("SMTP_STARTTLS", "true") == "true"
+	cfg.SMTP.Timeout = getDurationEnv("SMTP_TIMEOUT", 30*time.Second)
+	cfg.Digest.PollInterval = getDurationEnv("DIGEST_POLL_INTERVAL", time.Minute)
+
+	cfg.ReviewSLA.RemindAfter = getDurationEnv("REVIEW_SLA_REMIND_AFTER", 24*time.Hour)
+	cfg.ReviewSLA.EscalateAfter = getDurationEnv("REVIEW_SLA_ESCALATE_AFTER", 0)
+	cfg.ReviewSLA.PollInterval = getDurationEnv("REVIEW_SLA_POLL_INTERVAL", time.Minute)
+	cfg.ReviewSLA.LockKey = int64(getIntEnv("REVIEW_SLA_LOCK_KEY", 7305512))
+
+	cfg.Tracing.Exporter = getEnv("OTEL_TRACES_EXPORTER", "none")
+	cfg.Tracing.ServiceName = getEnv("OTEL_SERVICE_NAME", "pullrequests")
+
+	cfg.Outbox.PollInterval = getDurationEnv("OUTBOX_POLL_INTERVAL", time.Second)
+	cfg.Outbox.MaxAttempts = getIntEnv("OUTBOX_MAX_ATTEMPTS", 10)
+	cfg.Outbox.LogEvents = getEnv("OUTBOX_LOG_EVENTS", "false") == "true"
+
+	cfg.Webhooks.PollInterval = getDurationEnv("WEBHOOK_POLL_INTERVAL", time.Second)
+	cfg.Webhooks.Timeout = getDurationEnv("WEBHOOK_TIMEOUT", 10*time.Second)
+	cfg.Webhooks.MaxAttempts = getIntEnv("WEBHOOK_MAX_ATTEMPTS", 8)
+	cfg.Webhooks.RetryBase = getDurationEnv("WEBHOOK_RETRY_BASE", 10*time.Second)
+	cfg.Webhooks.RetryMax = getDurationEnv("WEBHOOK_RETRY_MAX", time.Hour)
+
+	cfg.Jobs.PollInterval = getDurationEnv("JOB_POLL_INTERVAL", time.Second)
+	cfg.Jobs.MaxAttempts = getIntEnv("JOB_MAX_ATTEMPTS", 8)
+	cfg.Jobs.RetryBase = getDurationEnv("JOB_RETRY_BASE", 10*time.Second)
+	cfg.Jobs.RetryMax = getDurationEnv("JOB_RETRY_MAX", time.Hour)
+
+	cfg.JWT.JWKSFile = getEnv("JWT_JWKS_FILE", "")
+	cfg.JWT.JWKSURL = getEnv("JWT_JWKS_URL", "")
+	cfg.JWT.JWKSRefresh = getDurationEnv("JWT_JWKS_REFRESH", time.Minute)
+	cfg.JWT.Issuer = getEnv("JWT_ISSUER", "")
+	cfg.JWT.Audience = getEnv("JWT_AUDIENCE", "")
+	cfg.JWT.UserClaim = getEnv("JWT_USER_CLAIM", "sub")
+	cfg.JWT.GroupsClaim = getEnv("JWT_GROUPS_CLAIM", "groups")
+	cfg.JWT.GroupRoles = getMapEnv("JWT_GROUP_ROLES", "")
+	cfg.JWT.DefaultRole = getEnv("JWT_DEFAULT_ROLE", "USER")
+
+	cfg.SQLite.Path = getEnv("SQLITE_PATH", "pullrequests.db")
+	cfg.Migrations.OnStart = getEnv("MIGRATE_ON_START", "true") == "true"
 
 	cfg.Database.Host = getEnv("DB_HOST", "postgres")
 	cfg.Database.Port = getEnv("DB_PORT", "5432")
@@ -50,3 +217,34 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+func getDurationEnv(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if duration, err := time.ParseDuration(value); err == nil {
+			return duration
+		}
+	}
+	return defaultValue
+}
+
+func getIntEnv(key string, defaultValue int) int {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := strconv.Atoi(value); err == nil {
+			return parsed
+		}
+	}
+	return defaultValue
+}
+
+// getMapEnv parses values like "admins=ADMIN,leads=TEAM_LEAD".
+func getMapEnv(key, defaultValue string) map[string]string {
+	result := make(map[string]string)
+	for _, pair := range strings.Split(getEnv(key, defaultValue), ",") {
+		name, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			continue
+		}
+		result[strings.TrimSpace(name)] = strings.TrimSpace(value)
+	}
+	return result
+}
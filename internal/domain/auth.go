@@ -0,0 +1,56 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+type APIRole string
+
+const (
+	APIRoleAdmin    APIRole = "ADMIN"
+	APIRoleTeamLead APIRole = "TEAM_LEAD"
+	APIRoleUser     APIRole = "USER"
+	APIRoleReadOnly APIRole = "READONLY"
+)
+
+func (r APIRole) IsValid() bool {
+	switch r {
+	case APIRoleAdmin, APIRoleTeamLead, APIRoleUser, APIRoleReadOnly:
+		return true
+	}
+	return false
+}
+
+type APIToken struct {
+	TokenID   string
+	Name      string
+	TokenHash string
+	Role      APIRole
+	UserID    string
+	CreatedAt time.Time
+	RevokedAt *time.Time
+}
+
+type Actor struct {
+	UserID  string
+	Role    APIRole
+	TokenID string
+}
+
+type TokenVerifier interface {
+	Verify(ctx context.Context, token string) (*Actor, error)
+}
+
+type actorKey struct{}
+
+func WithActor(ctx context.Context, actor *Actor) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+func ActorFromContext(ctx context.Context) *Actor {
+	if actor, ok := ctx.Value(actorKey{}).(*Actor); ok {
+		return actor
+	}
+	return nil
+}
@@ -3,30 +3,45 @@ package domain
 type ErrCode string
 
 const (
-	ErrTeamExistsCode  ErrCode = "TEAM_EXISTS"
-	ErrUserExistsCode  ErrCode = "USER_EXISTS"
-	ErrPRExistsCode    ErrCode = "PR_EXISTS"
-	ErrPRMergedCode    ErrCode = "PR_MERGED"
-	ErrNotAssignedCode ErrCode = "NOT_ASSIGNED"
-	ErrNoCandidateCode ErrCode = "NO_CANDIDATE"
-	ErrNotFoundCode    ErrCode = "NOT_FOUND"
-	ErrInternalCode    ErrCode = "INTERNAL_ERROR"
+	ErrTeamExistsCode            ErrCode = "TEAM_EXISTS"
+	ErrUserExistsCode            ErrCode = "USER_EXISTS"
+	ErrPRExistsCode              ErrCode = "PR_EXISTS"
+	ErrPRMergedCode              ErrCode = "PR_MERGED"
+	ErrNotAssignedCode           ErrCode = "NOT_ASSIGNED"
+	ErrNoCandidateCode           ErrCode = "NO_CANDIDATE"
+	ErrNotFoundCode              ErrCode = "NOT_FOUND"
+	ErrConflictCode              ErrCode = "CONFLICT"
+	ErrPreconditionCode          ErrCode = "PRECONDITION_FAILED"
+	ErrIdempotencyMismatchCode   ErrCode = "IDEMPOTENCY_KEY_MISMATCH"
+	ErrIdempotencyInProgressCode ErrCode = "IDEMPOTENCY_KEY_IN_PROGRESS"
+	ErrUnauthorizedCode          ErrCode = "UNAUTHORIZED"
+	ErrForbiddenCode             ErrCode = "FORBIDDEN"
+	ErrUnknownIdentityCode       ErrCode = "UNKNOWN_IDENTITY"
+	ErrInternalCode              ErrCode = "INTERNAL_ERROR"
 )
 
 var descriptions = map[ErrCode]string{
-	ErrTeamExistsCode:  "team already exists",
-	ErrUserExistsCode:  "user already exists",
-	ErrPRExistsCode:    "PR already exists",
-	ErrPRMergedCode:    "PR is already merged",
-	ErrNotAssignedCode: "item is not assigned",
-	ErrNoCandidateCode: "no candidate found",
-	ErrNotFoundCode:    "resource not found",
-	ErrInternalCode:    "internal server error",
+	ErrTeamExistsCode:            "team already exists",
+	ErrUserExistsCode:            "user already exists",
+	ErrPRExistsCode:              "PR already exists",
+	ErrPRMergedCode:              "PR is already merged",
+	ErrNotAssignedCode:           "item is not assigned",
+	ErrNoCandidateCode:           "no candidate found",
+	ErrNotFoundCode:              "resource not found",
+	ErrConflictCode:              "resource was modified concurrently",
+	ErrPreconditionCode:          "resource version does not match If-Match",
+	ErrIdempotencyMismatchCode:   "idempotency key was used with a different request",
+	ErrIdempotencyInProgressCode: "request with this idempotency key is still in progress",
+	ErrUnauthorizedCode:          "missing or invalid API token",
+	ErrForbiddenCode:             "caller is not allowed to perform this action",
+	ErrUnknownIdentityCode:       "external account is not mapped to a user",
+	ErrInternalCode:              "internal server error",
 }
 
 type DomainError struct {
 	code    ErrCode
 	message string
+	cause   error
 }
 
 func (err DomainError) Error() string {
@@ -41,6 +56,15 @@ func (err DomainError) Message() string {
 	return err.message
 }
 
+func (err DomainError) Unwrap() error {
+	return err.cause
+}
+
+func (err DomainError) WithCause(cause error) DomainError {
+	err.cause = cause
+	return err
+}
+
 func NewDomainError(code ErrCode) DomainError {
 	if desc, ok := descriptions[code]; ok {
 		return DomainError{code: code, message: desc}
@@ -0,0 +1,21 @@
+package domain
+
+import "time"
+
+type IdempotencyRecord struct {
+	// ActorID scopes the key to the caller that sent it; it is empty for
+	// unauthenticated requests.
+	ActorID      string
+	Key          string
+	Route        string
+	RequestHash  string
+	StatusCode   int
+	Headers      map[string][]string
+	ResponseBody []byte
+	CreatedAt    time.Time
+	ExpiresAt    time.Time
+}
+
+func (r *IdempotencyRecord) IsCompleted() bool {
+	return r.StatusCode != 0
+}
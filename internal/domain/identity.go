@@ -0,0 +1,21 @@
+package domain
+
+import "time"
+
+const (
+	ProviderGitHub = "github"
+	ProviderGitLab = "gitlab"
+)
+
+func IsValidProvider(provider string) bool {
+	return provider == ProviderGitHub || provider == ProviderGitLab
+}
+
+// ExternalIdentity maps an account on a code hosting provider to a user.
+// Logins are stored lower-cased since providers compare them case-insensitively.
+type ExternalIdentity struct {
+	Provider  string
+	Login     string
+	UserID    string
+	CreatedAt time.Time
+}
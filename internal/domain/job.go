@@ -0,0 +1,35 @@
+package domain
+
+import "time"
+
+type JobKind string
+
+const (
+	// JobReviewerSync mirrors a reviewer change on the code host.
+	JobReviewerSync JobKind = "reviewer.sync"
+	// JobChatMessage posts a rendered ChatMessage.
+	JobChatMessage JobKind = "chat.message"
+)
+
+type JobStatus string
+
+const (
+	JobStatusPending JobStatus = "PENDING"
+	JobStatusDone    JobStatus = "DONE"
+	JobStatusFailed  JobStatus = "FAILED"
+)
+
+// Job is work queued by an outbox sink for a worker that calls an external
+// service outside the dispatcher transaction; Payload holds JSON encoded
+// arguments specific to the kind.
+type Job struct {
+	JobID         string
+	Kind          JobKind
+	Payload       []byte
+	Status        JobStatus
+	Attempts      int
+	NextAttemptAt time.Time
+	LastError     string
+	CreatedAt     time.Time
+	FinishedAt    *time.Time
+}
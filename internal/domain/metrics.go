@@ -0,0 +1,14 @@
+package domain
+
+import "time"
+
+// TransactionObserver records finished top-level transaction attempts.
+type TransactionObserver interface {
+	ObserveTransaction(storage string, duration time.Duration, rolledBack bool)
+}
+
+// AssignmentMetrics records outcomes of reviewer assignment. Calls are made
+// once the outermost transaction is over, so retried attempts are not counted.
+type AssignmentMetrics interface {
+	IncNoCandidate(teamName string)
+}
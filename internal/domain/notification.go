@@ -0,0 +1,85 @@
+package domain
+
+import (
+	"context"
+	"slices"
+	"time"
+)
+
+const DefaultDigestHour = 9
+
+// NotificationPreferences are a user's chat and email settings. Users without
+// stored preferences get DefaultNotificationPreferences.
+type NotificationPreferences struct {
+	UserID      string
+	ChatEnabled bool
+	// ChatChannel replaces the team channel, e.g. "@alice" for a direct message.
+	ChatChannel string
+	// ChatEvents is empty when the user is notified about every event type.
+	ChatEvents []EventType
+	// Email receives the daily digest of open reviews; no digest is sent
+	// without it.
+	Email         string
+	DigestEnabled bool
+	// DigestHour is the hour in Timezone from which the day's digest is due.
+	DigestHour   int
+	Timezone     string
+	DigestSentAt *time.Time
+	UpdatedAt    time.Time
+}
+
+func DefaultNotificationPreferences(userID string) *NotificationPreferences {
+	return &NotificationPreferences{
+		UserID:        userID,
+		ChatEnabled:   true,
+		DigestEnabled: true,
+		DigestHour:    DefaultDigestHour,
+		Timezone:      "UTC",
+	}
+}
+
+func (p *NotificationPreferences) WantsChat(eventType EventType) bool {
+	return p.ChatEnabled && (len(p.ChatEvents) == 0 || slices.Contains(p.ChatEvents, eventType))
+}
+
+// DigestDue reports whether the digest for the user's current local day is
+// due and has not been sent yet.
+func (p *NotificationPreferences) DigestDue(now time.Time) bool {
+	if !p.DigestEnabled || p.Email == "" {
+		return false
+	}
+	location, err := time.LoadLocation(p.Timezone)
+	if err != nil {
+		location = time.UTC
+	}
+	local := now.In(location)
+	if local.Hour() < p.DigestHour {
+		return false
+	}
+	if p.DigestSentAt == nil {
+		return true
+	}
+	year, month, day := local.Date()
+	sentYear, sentMonth, sentDay := p.DigestSentAt.In(location).Date()
+	return year != sentYear || month != sentMonth || day != sentDay
+}
+
+type ChatMessage struct {
+	// Channel is empty to post to the default channel of the incoming webhook.
+	Channel string
+	Text    string
+}
+
+type ChatSender interface {
+	Send(ctx context.Context, message *ChatMessage) error
+}
+
+type EmailMessage struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+type EmailSender interface {
+	Send(ctx context.Context, message *EmailMessage) error
+}
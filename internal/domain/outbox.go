@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+type OutboxStatus string
+
+const (
+	OutboxStatusPending   OutboxStatus = "PENDING"
+	OutboxStatusProcessed OutboxStatus = "PROCESSED"
+	OutboxStatusFailed    OutboxStatus = "FAILED"
+)
+
+// OutboxMessage is an event stored in the same transaction as the state
+// change that produced it; Payload holds the JSON encoded event data.
+type OutboxMessage struct {
+	// Sequence is assigned by the store in insertion order; pending messages
+	// are dispatched by it.
+	Sequence      int64
+	EventID       string
+	EventType     EventType
+	Payload       []byte
+	OccurredAt    time.Time
+	Status        OutboxStatus
+	Attempts      int
+	NextAttemptAt time.Time
+	LastError     string
+	ProcessedAt   *time.Time
+	// DeliveredSinks names the sinks that have handled the event; retries
+	// skip them.
+	DeliveredSinks []string
+}
+
+// EventSink receives events read from the outbox. Each sink runs in a
+// savepoint of the dispatcher transaction, so a failing sink rolls back only
+// its own writes and is retried alone. Delivery is still at-least-once: a
+// sink sees an event again if the dispatcher transaction does not commit.
+type EventSink interface {
+	Name() string
+	Handle(ctx context.Context, event Event) error
+}
@@ -16,6 +16,7 @@ type PullRequest struct {
 	Status          PullRequestStatus
 	CreatedAt       time.Time
 	MergedAt        *time.Time
+	Version         int
 }
 
 type PullRequestReviewer struct {
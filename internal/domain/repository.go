@@ -2,27 +2,122 @@ package domain
 
 import (
 	"context"
+	"time"
 )
 
 type TeamRepo interface {
 	Add(ctx context.Context, team *Team) error
 	AddTeamMember(ctx context.Context, teamName string, teamMember *TeamMember) error
 	GetTeamByTeamName(ctx context.Context, teamName string) (*Team, error)
+	// GetTeamMembersByTeamName returns members ordered by user_id.
 	GetTeamMembersByTeamName(ctx context.Context, teamName string) ([]TeamMember, error)
 }
 
 type UserRepo interface {
 	GetUserByID(ctx context.Context, userID string) (*User, error)
 	UpdateUser(ctx context.Context, user *User) error
+	// GetActiveUsersByTeamName returns active users ordered by user_id, so
+	// reviewer selection does not depend on the physical row order.
 	GetActiveUsersByTeamName(ctx context.Context, teamName string) ([]User, error)
 }
 
 type PullRequestRepo interface {
 	Add(ctx context.Context, pullrequest *PullRequest) error
 	GetPullRequestByID(ctx context.Context, pullrequestID string) (*PullRequest, error)
-	AddReviewer(ctx context.Context, pullrequestID, userID string) error
-	RemoveReviewer(ctx context.Context, pullrequestID, userID string) error
+	// AddReviewer, RemoveReviewer and UpdatePullRequest fail with CONFLICT
+	// when pullrequest.Version is stale, and bump it on success.
+	AddReviewer(ctx context.Context, pullrequest *PullRequest, userID string) error
+	RemoveReviewer(ctx context.Context, pullrequest *PullRequest, userID string) error
+	// GetReviewers returns reviewers ordered by user_id.
 	GetReviewers(ctx context.Context, pullrequestID string) ([]PullRequestReviewer, error)
 	UpdatePullRequest(ctx context.Context, pullrequest *PullRequest) error
 	GetUserAssignedPRs(ctx context.Context, userID string) ([]PullRequest, error)
+	GetReviewerStatsByTeamName(ctx context.Context, teamName string) ([]ReviewerStat, error)
+	// CountOpenByTeam counts OPEN pull requests by the author's team; teams
+	// without open pull requests are absent.
+	CountOpenByTeam(ctx context.Context) (map[string]int, error)
+}
+
+type ReviewHistoryRepo interface {
+	Add(ctx context.Context, assignment *ReviewAssignment) error
+	GetLastAssignedAtByAuthor(ctx context.Context, authorID string) (map[string]time.Time, error)
+}
+
+type IdempotencyRepo interface {
+	// Reserve inserts a pending record and reports false if the key is already taken.
+	Reserve(ctx context.Context, record *IdempotencyRecord) (bool, error)
+	Get(ctx context.Context, actorID, key, route string) (*IdempotencyRecord, error)
+	Complete(ctx context.Context, record *IdempotencyRecord) error
+	Delete(ctx context.Context, actorID, key, route string) error
+}
+
+type APITokenRepo interface {
+	Add(ctx context.Context, token *APIToken) error
+	GetTokenByHash(ctx context.Context, tokenHash string) (*APIToken, error)
+	GetTokens(ctx context.Context) ([]APIToken, error)
+	RevokeToken(ctx context.Context, tokenID string, revokedAt time.Time) error
+}
+
+type WebhookRepo interface {
+	AddSubscription(ctx context.Context, subscription *WebhookSubscription) error
+	GetSubscription(ctx context.Context, subscriptionID string) (*WebhookSubscription, error)
+	GetSubscriptions(ctx context.Context) ([]WebhookSubscription, error)
+	DeleteSubscription(ctx context.Context, subscriptionID string) error
+	AddDelivery(ctx context.Context, delivery *WebhookDelivery) error
+	// GetDueDeliveries returns pending deliveries with next_attempt_at <= now,
+	// oldest first, skipping rows locked by other workers where supported.
+	GetDueDeliveries(ctx context.Context, now time.Time, limit int) ([]WebhookDelivery, error)
+	UpdateDelivery(ctx context.Context, delivery *WebhookDelivery) error
+	// GetDeliveries returns the newest deliveries first; an empty
+	// subscriptionID matches every subscription.
+	GetDeliveries(ctx context.Context, subscriptionID string, limit int) ([]WebhookDelivery, error)
+}
+
+type OutboxRepo interface {
+	Add(ctx context.Context, message *OutboxMessage) error
+	// GetPending returns pending messages with next_attempt_at <= now, oldest
+	// first, skipping rows locked by other dispatchers where supported.
+	GetPending(ctx context.Context, now time.Time, limit int) ([]OutboxMessage, error)
+	Update(ctx context.Context, message *OutboxMessage) error
+}
+
+type JobRepo interface {
+	AddJob(ctx context.Context, job *Job) error
+	// GetDueJobs returns pending jobs of the kind with next_attempt_at <= now,
+	// oldest first, skipping rows locked by other workers where supported.
+	GetDueJobs(ctx context.Context, kind JobKind, now time.Time, limit int) ([]Job, error)
+	UpdateJob(ctx context.Context, job *Job) error
+}
+
+type IdentityRepo interface {
+	// SetIdentity creates the mapping or points an existing login at a new user.
+	SetIdentity(ctx context.Context, identity *ExternalIdentity) error
+	GetIdentity(ctx context.Context, provider, login string) (*ExternalIdentity, error)
+	// GetIdentityByUserID returns the first login (by name) mapped to userID.
+	GetIdentityByUserID(ctx context.Context, provider, userID string) (*ExternalIdentity, error)
+	GetIdentities(ctx context.Context, provider string) ([]ExternalIdentity, error)
+	DeleteIdentity(ctx context.Context, provider, login string) error
+}
+
+type NotificationRepo interface {
+	// SetPreferences stores every field except DigestSentAt.
+	SetPreferences(ctx context.Context, preferences *NotificationPreferences) error
+	// GetPreferences returns nil when the user has not stored preferences.
+	GetPreferences(ctx context.Context, userID string) (*NotificationPreferences, error)
+	// GetDigestRecipients returns preferences with an email and the digest
+	// enabled, ordered by user_id.
+	GetDigestRecipients(ctx context.Context) ([]NotificationPreferences, error)
+	SetDigestSentAt(ctx context.Context, userID string, sentAt *time.Time) error
+}
+
+type ReviewSLARepo interface {
+	SetSLA(ctx context.Context, sla *ReviewSLA) error
+	// GetSLA returns nil when the team uses the default SLA.
+	GetSLA(ctx context.Context, teamName string) (*ReviewSLA, error)
+	GetSLAs(ctx context.Context) ([]ReviewSLA, error)
+	// GetOpenReviews returns OPEN pull requests created before createdBefore,
+	// oldest first.
+	GetOpenReviews(ctx context.Context, createdBefore time.Time) ([]OpenReview, error)
+	MarkReminded(ctx context.Context, pullRequestID string, at time.Time) error
+	MarkEscalated(ctx context.Context, pullRequestID string, at time.Time) error
 }
@@ -0,0 +1,19 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+type ReviewAssignment struct {
+	PullRequestID string
+	AuthorID      string
+	ReviewerID    string
+	AssignedAt    time.Time
+	// Escalated marks a lead assigned because no member was available.
+	Escalated bool
+}
+
+type ReviewerStrategy interface {
+	SelectReviewers(ctx context.Context, authorID string, candidates []User, count int) ([]User, error)
+}
@@ -0,0 +1,34 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// ReviewSLA sets how long a team's pull requests may stay OPEN before the
+// reviewers are reminded and, later, replaced. A zero duration disables the step.
+type ReviewSLA struct {
+	TeamName      string
+	RemindAfter   time.Duration
+	EscalateAfter time.Duration
+	UpdatedAt     time.Time
+}
+
+// OpenReview is an OPEN pull request together with the SLA steps already
+// taken for it.
+type OpenReview struct {
+	PullRequestID string
+	AuthorID      string
+	TeamName      string
+	CreatedAt     time.Time
+	RemindedAt    *time.Time
+	EscalatedAt   *time.Time
+}
+
+// LeaderElector lets only one replica run singleton jobs.
+type LeaderElector interface {
+	// IsLeader takes leadership when it is free and reports whether this
+	// replica holds it.
+	IsLeader(ctx context.Context) (bool, error)
+	Release(ctx context.Context) error
+}
@@ -1,5 +1,12 @@
 package domain
 
+type TeamRole string
+
+const (
+	TeamRoleMember TeamRole = "MEMBER"
+	TeamRoleLead   TeamRole = "LEAD"
+)
+
 type Team struct {
 	Name string
 }
@@ -8,4 +15,16 @@ type TeamMember struct {
 	UserID   string
 	Username string
 	IsActive bool
+	Role     TeamRole
+}
+
+type ReviewerStat struct {
+	UserID          string
+	Username        string
+	Role            TeamRole
+	Assignments     int
+	OpenAssignments int
+	// Escalations counts the times the user was assigned as a last resort,
+	// including assignments that were later reassigned.
+	Escalations int
 }
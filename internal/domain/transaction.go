@@ -2,6 +2,64 @@ package domain
 
 import "context"
 
+type IsolationLevel int
+
+const (
+	IsolationDefault IsolationLevel = iota
+	IsolationReadCommitted
+	IsolationRepeatableRead
+	IsolationSerializable
+)
+
+type TxOptions struct {
+	Isolation IsolationLevel
+	ReadOnly  bool
+	// Name names the transaction span, e.g. "PRUsecase.CreatePR".
+	Name string
+	// Savepoint makes a nested call undo only its own writes when fn fails.
+	Savepoint bool
+}
+
+type TxOption func(*TxOptions)
+
+func WithIsolation(level IsolationLevel) TxOption {
+	return func(opts *TxOptions) {
+		opts.Isolation = level
+	}
+}
+
+func WithName(name string) TxOption {
+	return func(opts *TxOptions) {
+		opts.Name = name
+	}
+}
+
+func WithReadOnly() TxOption {
+	return func(opts *TxOptions) {
+		opts.ReadOnly = true
+	}
+}
+
+// WithSavepoint runs a nested call in a savepoint: when fn fails its writes
+// are rolled back and the outer transaction can go on. Top-level calls
+// ignore it.
+func WithSavepoint() TxOption {
+	return func(opts *TxOptions) {
+		opts.Savepoint = true
+	}
+}
+
+func NewTxOptions(opts ...TxOption) TxOptions {
+	options := TxOptions{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return options
+}
+
 type TransactionManager interface {
-	Do(ctx context.Context, fn func(context.Context) error) error
+	// Do runs fn in a transaction. Nested calls join the outer transaction
+	// and their options other than WithSavepoint are ignored. fn may be retried, so it must not leak
+	// state from a failed attempt.
+	Do(ctx context.Context, fn func(context.Context) error, opts ...TxOption) error
 }
@@ -5,4 +5,5 @@ type User struct {
 	Username string
 	TeamName string
 	IsActive bool
+	Role     TeamRole
 }
@@ -0,0 +1,45 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// VCSClient mirrors reviewer assignments on the code host that owns a pull
+// request. Both calls are idempotent on the host side.
+type VCSClient interface {
+	Provider() string
+	// Supports reports whether pullRequestID refers to a pull request on this host.
+	Supports(pullRequestID string) bool
+	RequestReviewers(ctx context.Context, pullRequestID string, logins []string) error
+	RemoveReviewers(ctx context.Context, pullRequestID string, logins []string) error
+}
+
+// VCSUserDirectory resolves users that a code host's webhooks only reference
+// by numeric ID.
+type VCSUserDirectory interface {
+	LoginByID(ctx context.Context, userID int64) (string, error)
+}
+
+// VCSRejectedError reports a request the code host refused, e.g. because the
+// reviewer is not a collaborator; retrying it will not help.
+type VCSRejectedError struct {
+	StatusCode int
+	Message    string
+}
+
+func (err *VCSRejectedError) Error() string {
+	return fmt.Sprintf("code host rejected request with status %d: %s", err.StatusCode, err.Message)
+}
+
+// VCSUnavailableError reports a response worth retrying: a server error or a
+// rate limit. RetryAfter is the wait the host asked for, zero if it did not.
+type VCSUnavailableError struct {
+	StatusCode int
+	RetryAfter time.Duration
+}
+
+func (err *VCSUnavailableError) Error() string {
+	return fmt.Sprintf("code host responded with status %d", err.StatusCode)
+}
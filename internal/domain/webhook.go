@@ -0,0 +1,81 @@
+package domain
+
+import (
+	"context"
+	"slices"
+	"time"
+)
+
+type EventType string
+
+const (
+	EventPRCreated          EventType = "pr.created"
+	EventReviewerAssigned   EventType = "reviewer.assigned"
+	EventReviewerReassigned EventType = "reviewer.reassigned"
+	EventPRMerged           EventType = "pr.merged"
+	EventUserDeactivated    EventType = "user.deactivated"
+	EventReviewReminder     EventType = "review.reminder"
+)
+
+func (t EventType) IsValid() bool {
+	switch t {
+	case EventPRCreated, EventReviewerAssigned, EventReviewerReassigned, EventPRMerged, EventUserDeactivated,
+		EventReviewReminder:
+		return true
+	}
+	return false
+}
+
+type Event struct {
+	EventID    string
+	Type       EventType
+	OccurredAt time.Time
+	Data       interface{}
+}
+
+// EventPublisher is called inside the transaction that produced the event, so
+// implementations must only write through repositories bound to ctx.
+type EventPublisher interface {
+	Publish(ctx context.Context, event Event) error
+}
+
+type WebhookSubscription struct {
+	SubscriptionID string
+	URL            string
+	Secret         string
+	// Events is empty when the subscription receives every event type.
+	Events    []EventType
+	CreatedAt time.Time
+}
+
+func (s *WebhookSubscription) Matches(eventType EventType) bool {
+	return len(s.Events) == 0 || slices.Contains(s.Events, eventType)
+}
+
+type DeliveryStatus string
+
+const (
+	DeliveryStatusPending   DeliveryStatus = "PENDING"
+	DeliveryStatusDelivered DeliveryStatus = "DELIVERED"
+	DeliveryStatusFailed    DeliveryStatus = "FAILED"
+)
+
+type WebhookDelivery struct {
+	DeliveryID     string
+	SubscriptionID string
+	EventID        string
+	EventType      EventType
+	Payload        []byte
+	Status         DeliveryStatus
+	Attempts       int
+	NextAttemptAt  time.Time
+	LastError      string
+	ResponseStatus int
+	CreatedAt      time.Time
+	DeliveredAt    *time.Time
+}
+
+// WebhookSender posts a signed payload and returns the receiver's status code.
+type WebhookSender interface {
+	Send(ctx context.Context, subscription *WebhookSubscription, delivery *WebhookDelivery) (int, error)
+}
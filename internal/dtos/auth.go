@@ -0,0 +1,34 @@
+package dtos
+
+type CreateTokenRequest struct {
+	Name   string `json:"name"`
+	Role   string `json:"role"`
+	UserID string `json:"user_id,omitempty"`
+}
+
+type RevokeTokenRequest struct {
+	TokenID string `json:"token_id"`
+}
+
+type APIToken struct {
+	TokenID   string `json:"token_id"`
+	Name      string `json:"name"`
+	Role      string `json:"role"`
+	UserID    string `json:"user_id,omitempty"`
+	CreatedAt string `json:"created_at"`
+	RevokedAt string `json:"revoked_at,omitempty"`
+}
+
+type CreateTokenResponse struct {
+	Token APIToken `json:"token"`
+	// Secret is only returned once, on creation.
+	Secret string `json:"secret"`
+}
+
+type TokenResponse struct {
+	Token APIToken `json:"token"`
+}
+
+type TokensResponse struct {
+	Tokens []APIToken `json:"tokens"`
+}
@@ -0,0 +1,48 @@
+package dtos
+
+const (
+	VCSActionOpen  = "open"
+	VCSActionMerge = "merge"
+)
+
+type SetIdentityRequest struct {
+	Provider string `json:"provider"`
+	Login    string `json:"login"`
+	UserID   string `json:"user_id"`
+}
+
+type DeleteIdentityRequest struct {
+	Provider string `json:"provider"`
+	Login    string `json:"login"`
+}
+
+type Identity struct {
+	Provider  string `json:"provider"`
+	Login     string `json:"login"`
+	UserID    string `json:"user_id"`
+	CreatedAt string `json:"created_at"`
+}
+
+type IdentityResponse struct {
+	Identity Identity `json:"identity"`
+}
+
+type IdentitiesResponse struct {
+	Identities []Identity `json:"identities"`
+}
+
+// VCSPullRequestEvent is a provider-neutral pull request change parsed from
+// an incoming webhook.
+type VCSPullRequestEvent struct {
+	Provider        string
+	Action          string
+	PullRequestID   string
+	PullRequestName string
+	AuthorLogin     string
+}
+
+type IntegrationResult struct {
+	Status        string `json:"status"`
+	PullRequestID string `json:"pull_request_id,omitempty"`
+	Reason        string `json:"reason,omitempty"`
+}
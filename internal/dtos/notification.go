@@ -0,0 +1,31 @@
+package dtos
+
+// SetNotificationPreferencesRequest changes the given fields; omitted fields
+// keep their current value.
+type SetNotificationPreferencesRequest struct {
+	UserID        string   `json:"user_id"`
+	ChatEnabled   *bool    `json:"chat_enabled,omitempty"`
+	ChatChannel   *string  `json:"chat_channel,omitempty"`
+	ChatEvents    []string `json:"chat_events,omitempty"`
+	Email         *string  `json:"email,omitempty"`
+	DigestEnabled *bool    `json:"digest_enabled,omitempty"`
+	DigestHour    *int     `json:"digest_hour,omitempty"`
+	Timezone      *string  `json:"timezone,omitempty"`
+}
+
+type NotificationPreferences struct {
+	UserID        string   `json:"user_id"`
+	ChatEnabled   bool     `json:"chat_enabled"`
+	ChatChannel   string   `json:"chat_channel"`
+	ChatEvents    []string `json:"chat_events"`
+	Email         string   `json:"email"`
+	DigestEnabled bool     `json:"digest_enabled"`
+	DigestHour    int      `json:"digest_hour"`
+	Timezone      string   `json:"timezone"`
+	DigestSentAt  string   `json:"digest_sent_at,omitempty"`
+	UpdatedAt     string   `json:"updated_at,omitempty"`
+}
+
+type NotificationPreferencesResponse struct {
+	Preferences NotificationPreferences `json:"preferences"`
+}
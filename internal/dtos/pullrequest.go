@@ -7,12 +7,27 @@ type CreatePRRequest struct {
 }
 
 type MergePRRequest struct {
-	PullRequestID string `json:"pull_request_id"`
+	PullRequestID   string `json:"pull_request_id"`
+	ExpectedVersion *int   `json:"-"`
 }
 
 type ReassignPRRequest struct {
-	PullRequestID string `json:"pull_request_id"`
-	OldUserID     string `json:"old_user_id"`
+	PullRequestID   string `json:"pull_request_id"`
+	OldUserID       string `json:"old_user_id"`
+	AllowEscalation bool   `json:"allow_escalation"`
+	ExpectedVersion *int   `json:"-"`
+	// ExcludeUserIDs are never picked as the replacement.
+	ExcludeUserIDs []string `json:"-"`
+}
+
+type PreviewReviewersRequest struct {
+	AuthorID string `json:"author_id"`
+}
+
+type PreviewReviewersResponse struct {
+	AuthorID          string   `json:"author_id"`
+	TeamName          string   `json:"team_name"`
+	AssignedReviewers []string `json:"assigned_reviewers"`
 }
 
 type PRResponse struct {
@@ -27,6 +42,7 @@ type PullRequest struct {
 	AssignedReviewers []string `json:"assigned_reviewers"`
 	CreatedAt         string   `json:"createdAt,omitempty"`
 	MergedAt          string   `json:"mergedAt,omitempty"`
+	Version           int      `json:"version"`
 }
 
 type PullRequestShort struct {
@@ -44,4 +60,5 @@ type UserReviewResponse struct {
 type ReassignResponse struct {
 	PR         PullRequest `json:"pr"`
 	ReplacedBy string      `json:"replaced_by"`
+	Escalated  bool        `json:"escalated,omitempty"`
 }
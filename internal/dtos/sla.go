@@ -0,0 +1,21 @@
+package dtos
+
+// SetReviewSLARequest takes Go durations such as "36h"; "0" disables a step.
+type SetReviewSLARequest struct {
+	TeamName      string `json:"team_name"`
+	RemindAfter   string `json:"remind_after"`
+	EscalateAfter string `json:"escalate_after"`
+}
+
+type ReviewSLA struct {
+	TeamName      string `json:"team_name"`
+	RemindAfter   string `json:"remind_after"`
+	EscalateAfter string `json:"escalate_after"`
+	// IsDefault is set when the team has no SLA of its own.
+	IsDefault bool   `json:"is_default"`
+	UpdatedAt string `json:"updated_at,omitempty"`
+}
+
+type ReviewSLAResponse struct {
+	SLA ReviewSLA `json:"sla"`
+}
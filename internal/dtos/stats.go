@@ -0,0 +1,16 @@
+package dtos
+
+type ReviewerStatsResponse struct {
+	TeamName         string         `json:"team_name"`
+	TotalAssignments int            `json:"total_assignments"`
+	LeadAssignments  int            `json:"lead_assignments"`
+	Reviewers        []ReviewerStat `json:"reviewers"`
+}
+
+type ReviewerStat struct {
+	UserID          string `json:"user_id"`
+	Username        string `json:"username"`
+	Role            string `json:"role"`
+	Assignments     int    `json:"assignments"`
+	OpenAssignments int    `json:"open_assignments"`
+}
@@ -0,0 +1,73 @@
+package dtos
+
+type CreateWebhookRequest struct {
+	URL string `json:"url"`
+	// Secret is generated when omitted.
+	Secret string   `json:"secret,omitempty"`
+	Events []string `json:"events,omitempty"`
+}
+
+type DeleteWebhookRequest struct {
+	SubscriptionID string `json:"subscription_id"`
+}
+
+type WebhookSubscription struct {
+	SubscriptionID string   `json:"subscription_id"`
+	URL            string   `json:"url"`
+	Events         []string `json:"events"`
+	CreatedAt      string   `json:"created_at"`
+}
+
+type CreateWebhookResponse struct {
+	Subscription WebhookSubscription `json:"subscription"`
+	// Secret is only returned once, on creation.
+	Secret string `json:"secret"`
+}
+
+type WebhooksResponse struct {
+	Subscriptions []WebhookSubscription `json:"subscriptions"`
+}
+
+type WebhookDelivery struct {
+	DeliveryID     string `json:"delivery_id"`
+	SubscriptionID string `json:"subscription_id"`
+	EventID        string `json:"event_id"`
+	Event          string `json:"event"`
+	Status         string `json:"status"`
+	Attempts       int    `json:"attempts"`
+	NextAttemptAt  string `json:"next_attempt_at,omitempty"`
+	LastError      string `json:"last_error,omitempty"`
+	ResponseStatus int    `json:"response_status,omitempty"`
+	CreatedAt      string `json:"created_at"`
+	DeliveredAt    string `json:"delivered_at,omitempty"`
+}
+
+type WebhookDeliveriesResponse struct {
+	Deliveries []WebhookDelivery `json:"deliveries"`
+}
+
+// WebhookEvent is the JSON body posted to subscribers.
+type WebhookEvent struct {
+	EventID    string      `json:"event_id"`
+	Event      string      `json:"event"`
+	OccurredAt string      `json:"occurred_at"`
+	Data       interface{} `json:"data"`
+}
+
+type ReviewerAssignedEvent struct {
+	PullRequestID string `json:"pull_request_id"`
+	ReviewerID    string `json:"reviewer_id"`
+}
+
+type ReviewerReassignedEvent struct {
+	PullRequestID string `json:"pull_request_id"`
+	OldReviewerID string `json:"old_reviewer_id"`
+	NewReviewerID string `json:"new_reviewer_id"`
+	Escalated     bool   `json:"escalated"`
+}
+
+type ReviewReminderEvent struct {
+	PullRequestID string `json:"pull_request_id"`
+	ReviewerID    string `json:"reviewer_id"`
+	OpenSince     string `json:"open_since"`
+}
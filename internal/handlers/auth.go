@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"pullrequests/internal/domain"
+	"pullrequests/internal/dtos"
+	"pullrequests/internal/usecases"
+	"slices"
+	"strings"
+)
+
+type AuthHandler struct {
+	usecase *usecases.AuthUsecase
+	enabled bool
+}
+
+func NewAuthHandler(usecase *usecases.AuthUsecase, enabled bool) *AuthHandler {
+	return &AuthHandler{usecase: usecase, enabled: enabled}
+}
+
+func (h *AuthHandler) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !h.enabled {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		scheme, secret, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+		if !ok || !strings.EqualFold(scheme, "Bearer") || strings.TrimSpace(secret) == "" {
+			WriteAPIError(w, http.StatusUnauthorized, string(domain.ErrUnauthorizedCode), "Bearer token is required")
+			return
+		}
+
+		actor, err := h.usecase.Authenticate(r.Context(), strings.TrimSpace(secret))
+		if err != nil {
+			h.handleDomainError(w, r, err)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(domain.WithActor(r.Context(), actor)))
+	})
+}
+
+func (h *AuthHandler) RequireRoles(roles ...domain.APIRole) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !h.enabled {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			actor := domain.ActorFromContext(r.Context())
+			if actor == nil {
+				WriteAPIError(w, http.StatusUnauthorized, string(domain.ErrUnauthorizedCode), "Bearer token is required")
+				return
+			}
+			if !slices.Contains(roles, actor.Role) {
+				WriteAPIError(w, http.StatusForbidden, string(domain.ErrForbiddenCode), "Token role is not allowed to call this endpoint")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func (h *AuthHandler) CreateToken(w http.ResponseWriter, r *http.Request) {
+	var req dtos.CreateTokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteAPIError(w, http.StatusBadRequest, "INVALID_REQUEST", "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	if err := h.validateCreateTokenRequest(req); err != nil {
+		WriteAPIError(w, http.StatusBadRequest, "VALIDATION_ERROR", err.Error())
+		return
+	}
+
+	response, err := h.usecase.CreateToken(r.Context(), req)
+	if err != nil {
+		h.handleDomainError(w, r, err)
+		return
+	}
+
+	WriteJSON(w, http.StatusCreated, response)
+}
+
+func (h *AuthHandler) GetTokens(w http.ResponseWriter, r *http.Request) {
+	response, err := h.usecase.GetTokens(r.Context())
+	if err != nil {
+		h.handleDomainError(w, r, err)
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, response)
+}
+
+func (h *AuthHandler) RevokeToken(w http.ResponseWriter, r *http.Request) {
+	var req dtos.RevokeTokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteAPIError(w, http.StatusBadRequest, "INVALID_REQUEST", "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	if strings.TrimSpace(req.TokenID) == "" {
+		WriteAPIError(w, http.StatusBadRequest, "VALIDATION_ERROR", "token_id is required")
+		return
+	}
+
+	if err := h.usecase.RevokeToken(r.Context(), req); err != nil {
+		h.handleDomainError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *AuthHandler) validateCreateTokenRequest(req dtos.CreateTokenRequest) error {
+	if strings.TrimSpace(req.Name) == "" {
+		return fmt.Errorf("name is required")
+	}
+	role := domain.APIRole(req.Role)
+	if !role.IsValid() {
+		return fmt.Errorf("role must be one of ADMIN, TEAM_LEAD, USER, READONLY")
+	}
+	if (role == domain.APIRoleTeamLead || role == domain.APIRoleUser) && strings.TrimSpace(req.UserID) == "" {
+		return fmt.Errorf("user_id is required for %s tokens", role)
+	}
+	return nil
+}
+
+func (h *AuthHandler) handleDomainError(w http.ResponseWriter, r *http.Request, err error) {
+	var domainErr domain.DomainError
+	if errors.As(err, &domainErr) {
+		switch domainErr.Code() {
+		case string(domain.ErrUnauthorizedCode):
+			WriteAPIError(w, http.StatusUnauthorized, domainErr.Code(), domainErr.Message())
+		case string(domain.ErrNotFoundCode):
+			WriteAPIError(w, http.StatusNotFound, domainErr.Code(), domainErr.Message())
+		case string(domain.ErrForbiddenCode):
+			WriteAPIError(w, http.StatusForbidden, domainErr.Code(), domainErr.Message())
+		default:
+			WriteInternalError(w, r, err)
+		}
+		return
+	}
+	WriteInternalError(w, r, err)
+}
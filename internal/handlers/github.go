@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"pullrequests/internal/domain"
+	"pullrequests/internal/dtos"
+	"pullrequests/internal/usecases"
+	"strings"
+)
+
+const (
+	GitHubEventHeader     = "X-GitHub-Event"
+	GitHubDeliveryHeader  = "X-GitHub-Delivery"
+	GitHubSignatureHeader = "X-Hub-Signature-256"
+
+	// maxWebhookPayload matches the payload cap GitHub applies to webhooks.
+	maxWebhookPayload = 25 << 20
+)
+
+type gitHubPullRequestPayload struct {
+	Action      string `json:"action"`
+	Number      int    `json:"number"`
+	PullRequest struct {
+		Title  string `json:"title"`
+		Draft  bool   `json:"draft"`
+		Merged bool   `json:"merged"`
+		User   struct {
+			Login string `json:"login"`
+		} `json:"user"`
+	} `json:"pull_request"`
+	Repository struct {
+		FullName string `json:"full_name"`
+	} `json:"repository"`
+}
+
+type GitHubHandler struct {
+	usecase *usecases.IntegrationUsecase
+	secret  []byte
+}
+
+func NewGitHubHandler(usecase *usecases.IntegrationUsecase, secret string) *GitHubHandler {
+	return &GitHubHandler{usecase: usecase, secret: []byte(secret)}
+}
+
+// VerifySignature rejects requests whose body does not match the
+// X-Hub-Signature-256 HMAC. It must run before the idempotency middleware so
+// that forged requests cannot claim a delivery ID.
+func (h *GitHubHandler) VerifySignature(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxWebhookPayload))
+		if err != nil {
+			WriteAPIError(w, http.StatusBadRequest, "INVALID_REQUEST", "Invalid request payload")
+			return
+		}
+		r.Body.Close()
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		signature, ok := strings.CutPrefix(r.Header.Get(GitHubSignatureHeader), "sha256=")
+		expected, err := hex.DecodeString(signature)
+		if !ok || err != nil {
+			WriteAPIError(w, http.StatusUnauthorized, string(domain.ErrUnauthorizedCode), "Missing or malformed signature")
+			return
+		}
+		mac := hmac.New(sha256.New, h.secret)
+		mac.Write(body)
+		if !hmac.Equal(mac.Sum(nil), expected) {
+			WriteAPIError(w, http.StatusUnauthorized, string(domain.ErrUnauthorizedCode), "Signature does not match")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (h *GitHubHandler) Webhook(w http.ResponseWriter, r *http.Request) {
+	switch r.Header.Get(GitHubEventHeader) {
+	case "ping":
+		WriteJSON(w, http.StatusOK, dtos.IntegrationResult{Status: "pong"})
+		return
+	case "pull_request":
+	default:
+		WriteJSON(w, http.StatusOK, dtos.IntegrationResult{Status: usecases.IntegrationStatusIgnored, Reason: "unsupported event"})
+		return
+	}
+
+	var payload gitHubPullRequestPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		WriteAPIError(w, http.StatusBadRequest, "INVALID_REQUEST", "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	if payload.Repository.FullName == "" || payload.Number == 0 {
+		WriteAPIError(w, http.StatusBadRequest, "VALIDATION_ERROR", "repository.full_name and number are required")
+		return
+	}
+
+	event := dtos.VCSPullRequestEvent{
+		Provider:        domain.ProviderGitHub,
+		PullRequestID:   fmt.Sprintf("%s#%d", payload.Repository.FullName, payload.Number),
+		PullRequestName: payload.PullRequest.Title,
+		AuthorLogin:     payload.PullRequest.User.Login,
+	}
+	switch {
+	case (payload.Action == "opened" || payload.Action == "reopened") && !payload.PullRequest.Draft,
+		payload.Action == "ready_for_review":
+		event.Action = dtos.VCSActionOpen
+	case payload.Action == "closed" && payload.PullRequest.Merged:
+		event.Action = dtos.VCSActionMerge
+	default:
+		WriteJSON(w, http.StatusOK, dtos.IntegrationResult{
+			Status:        usecases.IntegrationStatusIgnored,
+			PullRequestID: event.PullRequestID,
+			Reason:        fmt.Sprintf("action %q is not handled", payload.Action),
+		})
+		return
+	}
+
+	result, err := h.usecase.HandlePullRequestEvent(r.Context(), event)
+	if err != nil {
+		h.handleDomainError(w, r, err)
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, result)
+}
+
+func (h *GitHubHandler) handleDomainError(w http.ResponseWriter, r *http.Request, err error) {
+	var domainErr domain.DomainError
+	if errors.As(err, &domainErr) {
+		switch domainErr.Code() {
+		case string(domain.ErrUnknownIdentityCode):
+			WriteAPIError(w, http.StatusUnprocessableEntity, domainErr.Code(), domainErr.Message())
+		case string(domain.ErrNotFoundCode):
+			WriteAPIError(w, http.StatusNotFound, domainErr.Code(), domainErr.Message())
+		case string(domain.ErrConflictCode):
+			WriteAPIError(w, http.StatusConflict, domainErr.Code(), domainErr.Message())
+		case string(domain.ErrForbiddenCode):
+			WriteAPIError(w, http.StatusForbidden, domainErr.Code(), domainErr.Message())
+		default:
+			WriteInternalError(w, r, err)
+		}
+		return
+	}
+	WriteInternalError(w, r, err)
+}
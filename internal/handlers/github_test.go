@@ -0,0 +1,184 @@
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"pullrequests/internal/adapters/memory"
+	"pullrequests/internal/domain"
+	"pullrequests/internal/dtos"
+	"pullrequests/internal/handlers"
+	"pullrequests/internal/metrics"
+	"pullrequests/internal/usecases"
+	"testing"
+)
+
+const gitHubSecret = "It's a Secret to Everybody"
+
+// gitHubFixture reads a webhook body as GitHub sends it, including the fields
+// the handler does not read.
+func gitHubFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	body, err := os.ReadFile(filepath.Join("testdata", "github", name))
+	if err != nil {
+		t.Fatalf("read fixture: %v", err)
+	}
+	return body
+}
+
+func signGitHub(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+type gitHubEnv struct {
+	handler      http.Handler
+	integrations *usecases.IntegrationUsecase
+	pullrequests *usecases.PRUsecase
+}
+
+func newGitHubEnv(t *testing.T) *gitHubEnv {
+	t.Helper()
+	ctx := context.Background()
+	store := memory.NewStore()
+	trm := memory.NewTransactionManager(store)
+	historyRepo := memory.NewReviewHistoryRepo(store)
+
+	teams := usecases.NewTeamUsecase(memory.NewTeamRepo(store), memory.NewUserRepo(store), trm)
+	_, err := teams.AddTeam(ctx, dtos.TeamRequest{Team: dtos.Team{
+		TeamName: "billing",
+		Members: []dtos.TeamMember{
+			{UserID: "u1", Username: "alice", IsActive: true},
+			{UserID: "u2", Username: "bob", IsActive: true},
+		},
+	}})
+	if err != nil {
+		t.Fatalf("add team: %v", err)
+	}
+
+	pullrequests := usecases.NewPRUsecase(memory.NewPRRepo(store), memory.NewUserRepo(store), historyRepo,
+		usecases.NewFirstNStrategy(), usecases.NewOutboxPublisher(memory.NewOutboxRepo(store)), metrics.Recorder{}, trm)
+	integrations := usecases.NewIntegrationUsecase(memory.NewIdentityRepo(store), pullrequests, trm)
+	h := handlers.NewGitHubHandler(integrations, gitHubSecret)
+	return &gitHubEnv{
+		handler:      h.VerifySignature(http.HandlerFunc(h.Webhook)),
+		integrations: integrations,
+		pullrequests: pullrequests,
+	}
+}
+
+func (env *gitHubEnv) deliver(event string, body []byte, signature string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/integrations/github/webhook", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(handlers.GitHubEventHeader, event)
+	req.Header.Set(handlers.GitHubDeliveryHeader, "0b8f1a2c-9d3e-11ef-8a4b-5c6d7e8f9a0b")
+	if signature != "" {
+		req.Header.Set(handlers.GitHubSignatureHeader, signature)
+	}
+	rec := httptest.NewRecorder()
+	env.handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestGitHubWebhookSignature(t *testing.T) {
+	env := newGitHubEnv(t)
+	body := gitHubFixture(t, "ping.json")
+	tampered := bytes.Replace(body, []byte("Design for failure."), []byte("Design for success."), 1)
+
+	tests := []struct {
+		name       string
+		body       []byte
+		signature  string
+		wantStatus int
+	}{
+		{name: "valid", body: body, signature: signGitHub(gitHubSecret, body), wantStatus: http.StatusOK},
+		{name: "missing", body: body, wantStatus: http.StatusUnauthorized},
+		{name: "sha1 header format", body: body, signature: "sha1=0123456789abcdef0123456789abcdef01234567", wantStatus: http.StatusUnauthorized},
+		{name: "not hex", body: body, signature: "sha256=zz", wantStatus: http.StatusUnauthorized},
+		{name: "other secret", body: body, signature: signGitHub("other", body), wantStatus: http.StatusUnauthorized},
+		{name: "tampered body", body: tampered, signature: signGitHub(gitHubSecret, body), wantStatus: http.StatusUnauthorized},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rec := env.deliver("ping", test.body, test.signature)
+			if rec.Code != test.wantStatus {
+				t.Fatalf("got status %d, want %d: %s", rec.Code, test.wantStatus, rec.Body)
+			}
+		})
+	}
+}
+
+func TestGitHubWebhookEvents(t *testing.T) {
+	ctx := context.Background()
+	env := newGitHubEnv(t)
+	const pullRequestID = "acme/billing#42"
+
+	send := func(t *testing.T, event, fixture string, wantStatus int) dtos.IntegrationResult {
+		t.Helper()
+		body := gitHubFixture(t, fixture)
+		rec := env.deliver(event, body, signGitHub(gitHubSecret, body))
+		if rec.Code != wantStatus {
+			t.Fatalf("%s: got status %d, want %d: %s", fixture, rec.Code, wantStatus, rec.Body)
+		}
+		var result dtos.IntegrationResult
+		if wantStatus == http.StatusOK {
+			if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil {
+				t.Fatalf("%s: decode response: %v", fixture, err)
+			}
+		}
+		return result
+	}
+
+	if result := send(t, "ping", "ping.json", http.StatusOK); result.Status != "pong" {
+		t.Errorf("ping: got %+v, want pong", result)
+	}
+	// The author has no identity mapping yet.
+	send(t, "pull_request", "pull_request_opened.json", http.StatusUnprocessableEntity)
+
+	// Logins are matched case-insensitively: GitHub sends "Octo-Alice".
+	_, err := env.integrations.SetIdentity(ctx, dtos.SetIdentityRequest{Provider: domain.ProviderGitHub, Login: "octo-alice", UserID: "u1"})
+	if err != nil {
+		t.Fatalf("set identity: %v", err)
+	}
+
+	steps := []struct {
+		event      string
+		fixture    string
+		wantStatus string
+	}{
+		{event: "pull_request", fixture: "pull_request_opened_draft.json", wantStatus: usecases.IntegrationStatusIgnored},
+		{event: "pull_request", fixture: "pull_request_ready_for_review.json", wantStatus: usecases.IntegrationStatusCreated},
+		{event: "pull_request", fixture: "pull_request_opened.json", wantStatus: usecases.IntegrationStatusUnchanged},
+		{event: "pull_request", fixture: "pull_request_synchronize.json", wantStatus: usecases.IntegrationStatusIgnored},
+		{event: "pull_request", fixture: "pull_request_closed_unmerged.json", wantStatus: usecases.IntegrationStatusIgnored},
+		{event: "pull_request_review", fixture: "pull_request_opened.json", wantStatus: usecases.IntegrationStatusIgnored},
+		{event: "pull_request", fixture: "pull_request_closed_merged.json", wantStatus: usecases.IntegrationStatusMerged},
+	}
+	for _, step := range steps {
+		result := send(t, step.event, step.fixture, http.StatusOK)
+		if result.Status != step.wantStatus {
+			t.Fatalf("%s %s: got %+v, want %s", step.event, step.fixture, result, step.wantStatus)
+		}
+	}
+
+	review, err := env.pullrequests.GetUserReviewPRs(ctx, "u2")
+	if err != nil {
+		t.Fatalf("get reviews: %v", err)
+	}
+	if len(review.PullRequests) != 1 {
+		t.Fatalf("reviewer has %d pull requests, want 1", len(review.PullRequests))
+	}
+	got := review.PullRequests[0]
+	if got.PullRequestID != pullRequestID || got.PullRequestName != "Retry failed invoice exports" ||
+		got.AuthorID != "u1" || got.Status != string(domain.PRStatusMerged) {
+		t.Errorf("got pull request %+v, want %s by u1, merged", got, pullRequestID)
+	}
+}
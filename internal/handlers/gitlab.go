@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"pullrequests/internal/domain"
+	"pullrequests/internal/dtos"
+	"pullrequests/internal/usecases"
+)
+
+const (
+	GitLabEventHeader     = "X-Gitlab-Event"
+	GitLabEventUUIDHeader = "X-Gitlab-Event-UUID"
+	GitLabTokenHeader     = "X-Gitlab-Token"
+)
+
+type gitLabMergeRequestPayload struct {
+	ObjectKind string `json:"object_kind"`
+	// User is who triggered the event, not necessarily the author.
+	User struct {
+		ID       int64  `json:"id"`
+		Username string `json:"username"`
+	} `json:"user"`
+	Project struct {
+		PathWithNamespace string `json:"path_with_namespace"`
+	} `json:"project"`
+	ObjectAttributes struct {
+		IID            int    `json:"iid"`
+		AuthorID       int64  `json:"author_id"`
+		Title          string `json:"title"`
+		Action         string `json:"action"`
+		Draft          bool   `json:"draft"`
+		WorkInProgress bool   `json:"work_in_progress"`
+	} `json:"object_attributes"`
+}
+
+type GitLabHandler struct {
+	usecase *usecases.IntegrationUsecase
+	token   []byte
+	users   domain.VCSUserDirectory
+}
+
+// NewGitLabHandler takes the directory used to find the author of merge
+// requests opened by someone else than the user triggering the event.
+func NewGitLabHandler(usecase *usecases.IntegrationUsecase, token string, users domain.VCSUserDirectory) *GitLabHandler {
+	return &GitLabHandler{usecase: usecase, token: []byte(token), users: users}
+}
+
+// VerifyToken rejects requests without the configured X-Gitlab-Token. Like
+// GitHubHandler.VerifySignature it must run before the idempotency middleware.
+func (h *GitLabHandler) VerifyToken(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get(GitLabTokenHeader)), h.token) != 1 {
+			WriteAPIError(w, http.StatusUnauthorized, string(domain.ErrUnauthorizedCode), "Missing or invalid webhook token")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (h *GitLabHandler) Webhook(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get(GitLabEventHeader) != "Merge Request Hook" {
+		WriteJSON(w, http.StatusOK, dtos.IntegrationResult{Status: usecases.IntegrationStatusIgnored, Reason: "unsupported event"})
+		return
+	}
+
+	var payload gitLabMergeRequestPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		WriteAPIError(w, http.StatusBadRequest, "INVALID_REQUEST", "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	if payload.Project.PathWithNamespace == "" || payload.ObjectAttributes.IID == 0 {
+		WriteAPIError(w, http.StatusBadRequest, "VALIDATION_ERROR", "project.path_with_namespace and object_attributes.iid are required")
+		return
+	}
+
+	attributes := payload.ObjectAttributes
+	draft := attributes.Draft || attributes.WorkInProgress
+	event := dtos.VCSPullRequestEvent{
+		Provider:        domain.ProviderGitLab,
+		PullRequestID:   fmt.Sprintf("%s!%d", payload.Project.PathWithNamespace, attributes.IID),
+		PullRequestName: attributes.Title,
+	}
+	switch {
+	case (attributes.Action == "open" || attributes.Action == "reopen" || attributes.Action == "update") && !draft:
+		event.Action = dtos.VCSActionOpen
+	case attributes.Action == "merge":
+		event.Action = dtos.VCSActionMerge
+	case attributes.Action == "close":
+		// Pull requests have no closed state; the review stays OPEN until it
+		// is merged or reopened and merged.
+		slog.WarnContext(r.Context(), "gitlab merge request closed without merge", "pull_request_id", event.PullRequestID)
+		WriteJSON(w, http.StatusOK, dtos.IntegrationResult{
+			Status:        usecases.IntegrationStatusIgnored,
+			PullRequestID: event.PullRequestID,
+			Reason:        "closing without merge is not supported, the pull request stays open",
+		})
+		return
+	default:
+		WriteJSON(w, http.StatusOK, dtos.IntegrationResult{
+			Status:        usecases.IntegrationStatusIgnored,
+			PullRequestID: event.PullRequestID,
+			Reason:        fmt.Sprintf("action %q is not handled", attributes.Action),
+		})
+		return
+	}
+
+	if event.Action == dtos.VCSActionOpen {
+		var err error
+		if event.AuthorLogin, err = h.authorLogin(r.Context(), &payload); err != nil {
+			h.handleDomainError(w, r, err)
+			return
+		}
+	}
+
+	result, err := h.usecase.HandlePullRequestEvent(r.Context(), event)
+	if err != nil {
+		h.handleDomainError(w, r, err)
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, result)
+}
+
+// authorLogin returns the username of object_attributes.author_id, which is
+// only looked up when someone else than the author triggered the event.
+func (h *GitLabHandler) authorLogin(ctx context.Context, payload *gitLabMergeRequestPayload) (string, error) {
+	authorID := payload.ObjectAttributes.AuthorID
+	if authorID == 0 || authorID == payload.User.ID {
+		return payload.User.Username, nil
+	}
+	if h.users == nil {
+		return "", domain.NewDomainError(domain.ErrUnknownIdentityCode).
+			WithCause(fmt.Errorf("gitlab user %d", authorID))
+	}
+
+	login, err := h.users.LoginByID(ctx, authorID)
+	var rejected *domain.VCSRejectedError
+	if errors.As(err, &rejected) {
+		return "", domain.NewDomainError(domain.ErrUnknownIdentityCode).WithCause(err)
+	}
+	return login, err
+}
+
+func (h *GitLabHandler) handleDomainError(w http.ResponseWriter, r *http.Request, err error) {
+	var domainErr domain.DomainError
+	if errors.As(err, &domainErr) {
+		switch domainErr.Code() {
+		case string(domain.ErrUnknownIdentityCode):
+			WriteAPIError(w, http.StatusUnprocessableEntity, domainErr.Code(), domainErr.Message())
+		case string(domain.ErrNotFoundCode):
+			WriteAPIError(w, http.StatusNotFound, domainErr.Code(), domainErr.Message())
+		case string(domain.ErrConflictCode):
+			WriteAPIError(w, http.StatusConflict, domainErr.Code(), domainErr.Message())
+		case string(domain.ErrForbiddenCode):
+			WriteAPIError(w, http.StatusForbidden, domainErr.Code(), domainErr.Message())
+		default:
+			WriteInternalError(w, r, err)
+		}
+		return
+	}
+	WriteInternalError(w, r, err)
+}
@@ -0,0 +1,29 @@
+package handlers
+
+import (
+	"net/http"
+	"pullrequests/internal/dtos"
+	"pullrequests/internal/usecases"
+)
+
+type HealthHandler struct {
+	usecase *usecases.HealthUsecase
+}
+
+func NewHealthHandler(usecase *usecases.HealthUsecase) *HealthHandler {
+	return &HealthHandler{usecase: usecase}
+}
+
+// Liveness reports that the process is up; it checks no dependencies.
+func (h *HealthHandler) Liveness(w http.ResponseWriter, r *http.Request) {
+	WriteJSON(w, http.StatusOK, dtos.HealthResponse{Status: usecases.HealthStatusOK})
+}
+
+func (h *HealthHandler) Readiness(w http.ResponseWriter, r *http.Request) {
+	response, ready := h.usecase.Readiness(r.Context())
+	status := http.StatusOK
+	if !ready {
+		status = http.StatusServiceUnavailable
+	}
+	WriteJSON(w, status, response)
+}
@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"pullrequests/internal/domain"
+	"pullrequests/internal/usecases"
+)
+
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+var replayedHeaders = []string{"Content-Type", "ETag"}
+
+type IdempotencyHandler struct {
+	usecase *usecases.IdempotencyUsecase
+}
+
+func NewIdempotencyHandler(usecase *usecases.IdempotencyUsecase) *IdempotencyHandler {
+	return &IdempotencyHandler{usecase: usecase}
+}
+
+func (h *IdempotencyHandler) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(IdempotencyKeyHeader)
+		if r.Method != http.MethodPost || key == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			WriteAPIError(w, http.StatusBadRequest, "INVALID_REQUEST", "Invalid request payload")
+			return
+		}
+		r.Body.Close()
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		route := r.URL.Path
+		actorID := idempotencyActorID(domain.ActorFromContext(r.Context()))
+		requestHash := h.hashRequest(r.Method, route, body)
+
+		stored, err := h.usecase.Begin(r.Context(), actorID, key, route, requestHash)
+		if err != nil {
+			h.handleDomainError(w, r, err)
+			return
+		}
+		if stored != nil {
+			h.replay(w, stored)
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+		ctx := context.WithoutCancel(r.Context())
+		completed := false
+		defer func() {
+			if !completed {
+				h.usecase.Release(ctx, actorID, key, route)
+			}
+		}()
+
+		next.ServeHTTP(recorder, r)
+
+		_, fromDelivery := r.Context().Value(deliveryKeyMarker{}).(bool)
+		if recorder.status >= http.StatusInternalServerError || (fromDelivery && recorder.status >= http.StatusBadRequest) {
+			return
+		}
+		headers := make(map[string][]string)
+		for _, name := range replayedHeaders {
+			if values := recorder.Header().Values(name); len(values) > 0 {
+				headers[name] = values
+			}
+		}
+		err = h.usecase.Complete(ctx, &domain.IdempotencyRecord{
+			ActorID:      actorID,
+			Key:          key,
+			Route:        route,
+			StatusCode:   recorder.status,
+			Headers:      headers,
+			ResponseBody: recorder.body.Bytes(),
+		})
+		completed = err == nil
+	})
+}
+
+// idempotencyActorID scopes keys to the API token, or to the user for tokens
+// from an identity provider, so that one caller cannot replay another's.
+func idempotencyActorID(actor *domain.Actor) string {
+	switch {
+	case actor == nil:
+		return ""
+	case actor.TokenID != "":
+		return "token:" + actor.TokenID
+	default:
+		return "user:" + actor.UserID
+	}
+}
+
+type deliveryKeyMarker struct{}
+
+// KeyFromHeader uses a provider delivery ID, such as X-GitHub-Delivery, as the
+// idempotency key; the first of names present on the request wins. Providers
+// redeliver failed requests with the same ID once the cause is fixed, so only
+// successful responses are kept for those keys.
+func (h *IdempotencyHandler) KeyFromHeader(names ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			for _, name := range names {
+				if key := r.Header.Get(name); key != "" {
+					r.Header.Set(IdempotencyKeyHeader, key)
+					r = r.WithContext(context.WithValue(r.Context(), deliveryKeyMarker{}, true))
+					break
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func (h *IdempotencyHandler) replay(w http.ResponseWriter, record *domain.IdempotencyRecord) {
+	for name, values := range record.Headers {
+		for _, value := range values {
+			w.Header().Add(name, value)
+		}
+	}
+	w.Header().Set("Idempotent-Replayed", "true")
+	w.WriteHeader(record.StatusCode)
+	w.Write(record.ResponseBody)
+}
+
+func (h *IdempotencyHandler) hashRequest(method, route string, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(method))
+	hash.Write([]byte{0})
+	hash.Write([]byte(route))
+	hash.Write([]byte{0})
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func (h *IdempotencyHandler) handleDomainError(w http.ResponseWriter, r *http.Request, err error) {
+	var domainErr domain.DomainError
+	if errors.As(err, &domainErr) {
+		switch domainErr.Code() {
+		case string(domain.ErrIdempotencyMismatchCode):
+			WriteAPIError(w, http.StatusUnprocessableEntity, domainErr.Code(), domainErr.Message())
+		case string(domain.ErrIdempotencyInProgressCode):
+			WriteAPIError(w, http.StatusConflict, domainErr.Code(), domainErr.Message())
+		default:
+			WriteInternalError(w, r, err)
+		}
+		return
+	}
+	WriteInternalError(w, r, err)
+}
+
+type responseRecorder struct {
+	http.ResponseWriter
+	status      int
+	body        bytes.Buffer
+	wroteHeader bool
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	if !r.wroteHeader {
+		r.WriteHeader(http.StatusOK)
+	}
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"pullrequests/internal/domain"
+	"pullrequests/internal/dtos"
+	"pullrequests/internal/usecases"
+	"strings"
+)
+
+type IntegrationHandler struct {
+	usecase *usecases.IntegrationUsecase
+}
+
+func NewIntegrationHandler(usecase *usecases.IntegrationUsecase) *IntegrationHandler {
+	return &IntegrationHandler{usecase: usecase}
+}
+
+func (h *IntegrationHandler) SetIdentity(w http.ResponseWriter, r *http.Request) {
+	var req dtos.SetIdentityRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteAPIError(w, http.StatusBadRequest, "INVALID_REQUEST", "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	if err := h.validateSetIdentityRequest(req); err != nil {
+		WriteAPIError(w, http.StatusBadRequest, "VALIDATION_ERROR", err.Error())
+		return
+	}
+
+	response, err := h.usecase.SetIdentity(r.Context(), req)
+	if err != nil {
+		h.handleDomainError(w, r, err)
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, response)
+}
+
+func (h *IntegrationHandler) GetIdentities(w http.ResponseWriter, r *http.Request) {
+	provider := r.URL.Query().Get("provider")
+	if !domain.IsValidProvider(provider) {
+		WriteAPIError(w, http.StatusBadRequest, "VALIDATION_ERROR", "provider is invalid")
+		return
+	}
+
+	response, err := h.usecase.GetIdentities(r.Context(), provider)
+	if err != nil {
+		h.handleDomainError(w, r, err)
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, response)
+}
+
+func (h *IntegrationHandler) DeleteIdentity(w http.ResponseWriter, r *http.Request) {
+	var req dtos.DeleteIdentityRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteAPIError(w, http.StatusBadRequest, "INVALID_REQUEST", "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	if !domain.IsValidProvider(req.Provider) || strings.TrimSpace(req.Login) == "" {
+		WriteAPIError(w, http.StatusBadRequest, "VALIDATION_ERROR", "provider and login are required")
+		return
+	}
+
+	if err := h.usecase.DeleteIdentity(r.Context(), req); err != nil {
+		h.handleDomainError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *IntegrationHandler) validateSetIdentityRequest(req dtos.SetIdentityRequest) error {
+	if !domain.IsValidProvider(req.Provider) {
+		return fmt.Errorf("provider is invalid")
+	}
+	if strings.TrimSpace(req.Login) == "" {
+		return fmt.Errorf("login is required")
+	}
+	if strings.TrimSpace(req.UserID) == "" {
+		return fmt.Errorf("user_id is required")
+	}
+	return nil
+}
+
+func (h *IntegrationHandler) handleDomainError(w http.ResponseWriter, r *http.Request, err error) {
+	var domainErr domain.DomainError
+	if errors.As(err, &domainErr) {
+		switch domainErr.Code() {
+		case string(domain.ErrNotFoundCode):
+			WriteAPIError(w, http.StatusNotFound, domainErr.Code(), domainErr.Message())
+		case string(domain.ErrForbiddenCode):
+			WriteAPIError(w, http.StatusForbidden, domainErr.Code(), domainErr.Message())
+		default:
+			WriteInternalError(w, r, err)
+		}
+		return
+	}
+	WriteInternalError(w, r, err)
+}
@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"net/http"
+	"pullrequests/internal/metrics"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// MetricsMiddleware records request counts and latency labelled with the chi
+// route pattern, so that path parameters do not blow up label cardinality.
+func MetricsMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r)
+
+		metrics.ObserveHTTPRequest(r.Method, routePattern(r), recorder.status, time.Since(start))
+	})
+}
+
+// routePattern returns the chi pattern matched by r; it is only known once
+// the router has served the request.
+func routePattern(r *http.Request) string {
+	if rctx := chi.RouteContext(r.Context()); rctx != nil {
+		if pattern := rctx.RoutePattern(); pattern != "" {
+			return pattern
+		}
+	}
+	return "unmatched"
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	if !r.wroteHeader {
+		r.WriteHeader(http.StatusOK)
+	}
+	return r.ResponseWriter.Write(b)
+}
@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/mail"
+	"pullrequests/internal/domain"
+	"pullrequests/internal/dtos"
+	"pullrequests/internal/usecases"
+	"slices"
+	"strings"
+	"time"
+)
+
+type NotificationHandler struct {
+	usecase *usecases.NotificationUsecase
+}
+
+func NewNotificationHandler(usecase *usecases.NotificationUsecase) *NotificationHandler {
+	return &NotificationHandler{usecase: usecase}
+}
+
+func (h *NotificationHandler) GetPreferences(w http.ResponseWriter, r *http.Request) {
+	userID := r.URL.Query().Get("user_id")
+	if actor := domain.ActorFromContext(r.Context()); userID == "" && actor != nil {
+		userID = actor.UserID
+	}
+	if userID == "" {
+		WriteAPIError(w, http.StatusBadRequest, "VALIDATION_ERROR", "user_id query parameter is required")
+		return
+	}
+
+	response, err := h.usecase.GetPreferences(r.Context(), userID)
+	if err != nil {
+		h.handleDomainError(w, r, err)
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, response)
+}
+
+func (h *NotificationHandler) SetPreferences(w http.ResponseWriter, r *http.Request) {
+	var req dtos.SetNotificationPreferencesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteAPIError(w, http.StatusBadRequest, "INVALID_REQUEST", "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	if actor := domain.ActorFromContext(r.Context()); req.UserID == "" && actor != nil {
+		req.UserID = actor.UserID
+	}
+	if err := h.validateSetPreferencesRequest(req); err != nil {
+		WriteAPIError(w, http.StatusBadRequest, "VALIDATION_ERROR", err.Error())
+		return
+	}
+
+	response, err := h.usecase.SetPreferences(r.Context(), req)
+	if err != nil {
+		h.handleDomainError(w, r, err)
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, response)
+}
+
+func (h *NotificationHandler) validateSetPreferencesRequest(req dtos.SetNotificationPreferencesRequest) error {
+	if strings.TrimSpace(req.UserID) == "" {
+		return fmt.Errorf("user_id is required")
+	}
+	for _, event := range req.ChatEvents {
+		if !slices.Contains(usecases.ChatEvents, domain.EventType(event)) {
+			return fmt.Errorf("unsupported chat event %q", event)
+		}
+	}
+	if req.Email != nil && *req.Email != "" {
+		if address, err := mail.ParseAddress(*req.Email); err != nil || address.Address != *req.Email {
+			return fmt.Errorf("email must be a plain address like name@example.com")
+		}
+	}
+	if req.DigestHour != nil && (*req.DigestHour < 0 || *req.DigestHour > 23) {
+		return fmt.Errorf("digest_hour must be between 0 and 23")
+	}
+	if req.Timezone != nil {
+		if _, err := time.LoadLocation(*req.Timezone); err != nil || *req.Timezone == "" || *req.Timezone == "Local" {
+			return fmt.Errorf("unknown timezone %q", *req.Timezone)
+		}
+	}
+	return nil
+}
+
+func (h *NotificationHandler) handleDomainError(w http.ResponseWriter, r *http.Request, err error) {
+	var domainErr domain.DomainError
+	if errors.As(err, &domainErr) {
+		switch domainErr.Code() {
+		case string(domain.ErrNotFoundCode):
+			WriteAPIError(w, http.StatusNotFound, domainErr.Code(), domainErr.Message())
+		case string(domain.ErrForbiddenCode):
+			WriteAPIError(w, http.StatusForbidden, domainErr.Code(), domainErr.Message())
+		default:
+			WriteInternalError(w, r, err)
+		}
+		return
+	}
+	WriteInternalError(w, r, err)
+}
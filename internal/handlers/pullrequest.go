@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"pullrequests/internal/domain"
@@ -33,13 +34,36 @@ func (h *PRHandler) CreatePR(w http.ResponseWriter, r *http.Request) {
 
 	pr, err := h.usecase.CreatePR(r.Context(), req)
 	if err != nil {
-		h.handleDomainError(w, err)
+		h.handleDomainError(w, r, err)
 		return
 	}
 
+	SetETag(w, pr.PR.Version)
 	WriteJSON(w, http.StatusCreated, pr)
 }
 
+func (h *PRHandler) PreviewReviewers(w http.ResponseWriter, r *http.Request) {
+	var req dtos.PreviewReviewersRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteAPIError(w, http.StatusBadRequest, "INVALID_REQUEST", "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	if err := h.validatePreviewReviewersRequest(req); err != nil {
+		WriteAPIError(w, http.StatusBadRequest, "VALIDATION_ERROR", err.Error())
+		return
+	}
+
+	response, err := h.usecase.PreviewReviewers(r.Context(), req)
+	if err != nil {
+		h.handleDomainError(w, r, err)
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, response)
+}
+
 func (h *PRHandler) MergePR(w http.ResponseWriter, r *http.Request) {
 	var req dtos.MergePRRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -53,12 +77,20 @@ func (h *PRHandler) MergePR(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	expectedVersion, err := ParseIfMatch(r)
+	if err != nil {
+		WriteAPIError(w, http.StatusBadRequest, "VALIDATION_ERROR", err.Error())
+		return
+	}
+	req.ExpectedVersion = expectedVersion
+
 	pr, err := h.usecase.MergePR(r.Context(), req)
 	if err != nil {
-		h.handleDomainError(w, err)
+		h.handleDomainError(w, r, err)
 		return
 	}
 
+	SetETag(w, pr.PR.Version)
 	WriteJSON(w, http.StatusOK, pr)
 }
 
@@ -75,17 +107,28 @@ func (h *PRHandler) ReassignReviewer(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	expectedVersion, err := ParseIfMatch(r)
+	if err != nil {
+		WriteAPIError(w, http.StatusBadRequest, "VALIDATION_ERROR", err.Error())
+		return
+	}
+	req.ExpectedVersion = expectedVersion
+
 	response, err := h.usecase.ReassignReviewer(r.Context(), req)
 	if err != nil {
-		h.handleDomainError(w, err)
+		h.handleDomainError(w, r, err)
 		return
 	}
 
+	SetETag(w, response.PR.Version)
 	WriteJSON(w, http.StatusOK, response)
 }
 
 func (h *PRHandler) GetUserReviewPRs(w http.ResponseWriter, r *http.Request) {
 	userID := r.URL.Query().Get("user_id")
+	if actor := domain.ActorFromContext(r.Context()); userID == "" && actor != nil {
+		userID = actor.UserID
+	}
 	if userID == "" {
 		WriteAPIError(w, http.StatusBadRequest, "VALIDATION_ERROR", "user_id query parameter is required")
 		return
@@ -93,7 +136,7 @@ func (h *PRHandler) GetUserReviewPRs(w http.ResponseWriter, r *http.Request) {
 
 	response, err := h.usecase.GetUserReviewPRs(r.Context(), userID)
 	if err != nil {
-		h.handleDomainError(w, err)
+		h.handleDomainError(w, r, err)
 		return
 	}
 
@@ -113,6 +156,13 @@ func (h *PRHandler) validateCreatePRRequest(req dtos.CreatePRRequest) error {
 	return nil
 }
 
+func (h *PRHandler) validatePreviewReviewersRequest(req dtos.PreviewReviewersRequest) error {
+	if strings.TrimSpace(req.AuthorID) == "" {
+		return fmt.Errorf("author_id is required")
+	}
+	return nil
+}
+
 func (h *PRHandler) validateMergePRRequest(req dtos.MergePRRequest) error {
 	if strings.TrimSpace(req.PullRequestID) == "" {
 		return fmt.Errorf("pull_request_id is required")
@@ -130,8 +180,9 @@ func (h *PRHandler) validateReassignPRRequest(req dtos.ReassignPRRequest) error
 	return nil
 }
 
-func (h *PRHandler) handleDomainError(w http.ResponseWriter, err error) {
-	if domainErr, ok := err.(domain.DomainError); ok {
+func (h *PRHandler) handleDomainError(w http.ResponseWriter, r *http.Request, err error) {
+	var domainErr domain.DomainError
+	if errors.As(err, &domainErr) {
 		switch domainErr.Code() {
 		case string(domain.ErrPRExistsCode):
 			WriteAPIError(w, http.StatusConflict, domainErr.Code(), domainErr.Message())
@@ -143,10 +194,16 @@ func (h *PRHandler) handleDomainError(w http.ResponseWriter, err error) {
 			WriteAPIError(w, http.StatusConflict, domainErr.Code(), domainErr.Message())
 		case string(domain.ErrNotFoundCode):
 			WriteAPIError(w, http.StatusNotFound, domainErr.Code(), domainErr.Message())
+		case string(domain.ErrConflictCode):
+			WriteAPIError(w, http.StatusConflict, domainErr.Code(), domainErr.Message())
+		case string(domain.ErrPreconditionCode):
+			WriteAPIError(w, http.StatusPreconditionFailed, domainErr.Code(), domainErr.Message())
+		case string(domain.ErrForbiddenCode):
+			WriteAPIError(w, http.StatusForbidden, domainErr.Code(), domainErr.Message())
 		default:
-			WriteAPIError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Internal server error")
+			WriteInternalError(w, r, err)
 		}
 		return
 	}
-	WriteAPIError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Internal server error")
+	WriteInternalError(w, r, err)
 }
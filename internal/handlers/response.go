@@ -2,7 +2,10 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
+	"log/slog"
 	"net/http"
+	"pullrequests/internal/metrics"
 )
 
 type ErrorResponse struct {
@@ -21,6 +24,7 @@ func WriteJSON(w http.ResponseWriter, status int, data interface{}) {
 }
 
 func WriteAPIError(w http.ResponseWriter, status int, code, message string) {
+	metrics.IncError(code)
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
 
@@ -32,3 +36,14 @@ func WriteAPIError(w http.ResponseWriter, status int, code, message string) {
 		http.Error(w, message, status)
 	}
 }
+
+// WriteInternalError logs err, which clients never see, and writes
+// INTERNAL_ERROR.
+func WriteInternalError(w http.ResponseWriter, r *http.Request, err error) {
+	attrs := []any{"method", r.Method, "path", r.URL.Path, "error", err}
+	if cause := errors.Unwrap(err); cause != nil {
+		attrs = append(attrs, "cause", cause)
+	}
+	slog.ErrorContext(r.Context(), "internal error", attrs...)
+	WriteAPIError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Internal server error")
+}
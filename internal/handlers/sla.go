@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"pullrequests/internal/domain"
+	"pullrequests/internal/dtos"
+	"pullrequests/internal/usecases"
+	"strings"
+	"time"
+)
+
+type ReviewSLAHandler struct {
+	usecase *usecases.ReviewSLAUsecase
+}
+
+func NewReviewSLAHandler(usecase *usecases.ReviewSLAUsecase) *ReviewSLAHandler {
+	return &ReviewSLAHandler{usecase: usecase}
+}
+
+func (h *ReviewSLAHandler) GetSLA(w http.ResponseWriter, r *http.Request) {
+	teamName := r.URL.Query().Get("team_name")
+	if teamName == "" {
+		WriteAPIError(w, http.StatusBadRequest, "VALIDATION_ERROR", "team_name is required")
+		return
+	}
+
+	response, err := h.usecase.GetSLA(r.Context(), teamName)
+	if err != nil {
+		h.handleDomainError(w, r, err)
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, response)
+}
+
+func (h *ReviewSLAHandler) SetSLA(w http.ResponseWriter, r *http.Request) {
+	var req dtos.SetReviewSLARequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteAPIError(w, http.StatusBadRequest, "INVALID_REQUEST", "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	remindAfter, escalateAfter, err := h.parseSetSLARequest(req)
+	if err != nil {
+		WriteAPIError(w, http.StatusBadRequest, "VALIDATION_ERROR", err.Error())
+		return
+	}
+
+	response, err := h.usecase.SetSLA(r.Context(), req.TeamName, remindAfter, escalateAfter)
+	if err != nil {
+		h.handleDomainError(w, r, err)
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, response)
+}
+
+func (h *ReviewSLAHandler) parseSetSLARequest(req dtos.SetReviewSLARequest) (time.Duration, time.Duration, error) {
+	if strings.TrimSpace(req.TeamName) == "" {
+		return 0, 0, fmt.Errorf("team_name is required")
+	}
+	remindAfter, err := time.ParseDuration(req.RemindAfter)
+	if err != nil || remindAfter < 0 {
+		return 0, 0, fmt.Errorf("remind_after must be a non-negative duration like \"24h\"")
+	}
+	escalateAfter, err := time.ParseDuration(req.EscalateAfter)
+	if err != nil || escalateAfter < 0 {
+		return 0, 0, fmt.Errorf("escalate_after must be a non-negative duration like \"72h\"")
+	}
+	if remindAfter > 0 && escalateAfter > 0 && escalateAfter <= remindAfter {
+		return 0, 0, fmt.Errorf("escalate_after must be longer than remind_after")
+	}
+	return remindAfter.Truncate(time.Second), escalateAfter.Truncate(time.Second), nil
+}
+
+func (h *ReviewSLAHandler) handleDomainError(w http.ResponseWriter, r *http.Request, err error) {
+	var domainErr domain.DomainError
+	if errors.As(err, &domainErr) {
+		switch domainErr.Code() {
+		case string(domain.ErrNotFoundCode):
+			WriteAPIError(w, http.StatusNotFound, domainErr.Code(), domainErr.Message())
+		case string(domain.ErrForbiddenCode):
+			WriteAPIError(w, http.StatusForbidden, domainErr.Code(), domainErr.Message())
+		default:
+			WriteInternalError(w, r, err)
+		}
+		return
+	}
+	WriteInternalError(w, r, err)
+}
@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"pullrequests/internal/domain"
+	"pullrequests/internal/usecases"
+)
+
+type StatsHandler struct {
+	usecase *usecases.StatsUsecase
+}
+
+func NewStatsHandler(usecase *usecases.StatsUsecase) *StatsHandler {
+	return &StatsHandler{usecase: usecase}
+}
+
+func (h *StatsHandler) GetReviewerStats(w http.ResponseWriter, r *http.Request) {
+	teamName := r.URL.Query().Get("team_name")
+	if teamName == "" {
+		WriteAPIError(w, http.StatusBadRequest, "VALIDATION_ERROR", "team_name is required")
+		return
+	}
+
+	stats, err := h.usecase.GetReviewerStats(r.Context(), teamName)
+	if err != nil {
+		h.handleDomainError(w, r, err)
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, stats)
+}
+
+func (h *StatsHandler) handleDomainError(w http.ResponseWriter, r *http.Request, err error) {
+	var domainErr domain.DomainError
+	if errors.As(err, &domainErr) {
+		switch domainErr.Code() {
+		case string(domain.ErrNotFoundCode):
+			WriteAPIError(w, http.StatusNotFound, domainErr.Code(), domainErr.Message())
+		case string(domain.ErrForbiddenCode):
+			WriteAPIError(w, http.StatusForbidden, domainErr.Code(), domainErr.Message())
+		default:
+			WriteInternalError(w, r, err)
+		}
+		return
+	}
+	WriteInternalError(w, r, err)
+}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"pullrequests/internal/domain"
@@ -33,7 +34,7 @@ func (h *TeamHandler) AddTeam(w http.ResponseWriter, r *http.Request) {
 
 	result, err := h.usecase.AddTeam(r.Context(), req)
 	if err != nil {
-		h.handleDomainError(w, err)
+		h.handleDomainError(w, r, err)
 		return
 	}
 
@@ -49,15 +50,16 @@ func (h *TeamHandler) GetTeam(w http.ResponseWriter, r *http.Request) {
 
 	team, err := h.usecase.GetTeam(r.Context(), teamName)
 	if err != nil {
-		h.handleDomainError(w, err)
+		h.handleDomainError(w, r, err)
 		return
 	}
 
 	WriteJSON(w, http.StatusOK, team)
 }
 
-func (h *TeamHandler) handleDomainError(w http.ResponseWriter, err error) {
-	if domainErr, ok := err.(domain.DomainError); ok {
+func (h *TeamHandler) handleDomainError(w http.ResponseWriter, r *http.Request, err error) {
+	var domainErr domain.DomainError
+	if errors.As(err, &domainErr) {
 		switch domainErr.Code() {
 		case string(domain.ErrTeamExistsCode):
 			WriteAPIError(w, http.StatusBadRequest, domainErr.Code(), domainErr.Message())
@@ -65,12 +67,14 @@ func (h *TeamHandler) handleDomainError(w http.ResponseWriter, err error) {
 			WriteAPIError(w, http.StatusNotFound, domainErr.Code(), domainErr.Message())
 		case string(domain.ErrUserExistsCode):
 			WriteAPIError(w, http.StatusNotFound, domainErr.Code(), domainErr.Message())
+		case string(domain.ErrForbiddenCode):
+			WriteAPIError(w, http.StatusForbidden, domainErr.Code(), domainErr.Message())
 		default:
-			WriteAPIError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Internal server error")
+			WriteInternalError(w, r, err)
 		}
 		return
 	}
-	WriteAPIError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Internal server error")
+	WriteInternalError(w, r, err)
 }
 
 func (h *TeamHandler) validateTeamRequest(req dtos.TeamRequest) error {
@@ -91,6 +95,12 @@ func (h *TeamHandler) validateTeamRequest(req dtos.TeamRequest) error {
 			return fmt.Errorf("username is required for all members")
 		}
 
+		if member.Role != "" &&
+			member.Role != string(domain.TeamRoleMember) &&
+			member.Role != string(domain.TeamRoleLead) {
+			return fmt.Errorf("role must be one of MEMBER, LEAD for user_id: %s", member.UserID)
+		}
+
 		if userIDs[member.UserID] {
 			return fmt.Errorf("duplicate user_id: %s", member.UserID)
 		}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"pullrequests/internal/domain"
@@ -33,7 +34,7 @@ func (h *UserHandler) SetUserActive(w http.ResponseWriter, r *http.Request) {
 
 	user, err := h.usecase.SetUserActive(r.Context(), req)
 	if err != nil {
-		h.handleDomainError(w, err)
+		h.handleDomainError(w, r, err)
 		return
 	}
 
@@ -47,15 +48,18 @@ func (h *UserHandler) validateUserActiveRequest(req dtos.UserActiveRequest) erro
 	return nil
 }
 
-func (h *UserHandler) handleDomainError(w http.ResponseWriter, err error) {
-	if domainErr, ok := err.(domain.DomainError); ok {
+func (h *UserHandler) handleDomainError(w http.ResponseWriter, r *http.Request, err error) {
+	var domainErr domain.DomainError
+	if errors.As(err, &domainErr) {
 		switch domainErr.Code() {
 		case string(domain.ErrNotFoundCode):
 			WriteAPIError(w, http.StatusNotFound, domainErr.Code(), domainErr.Message())
+		case string(domain.ErrForbiddenCode):
+			WriteAPIError(w, http.StatusForbidden, domainErr.Code(), domainErr.Message())
 		default:
-			WriteAPIError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Internal server error")
+			WriteInternalError(w, r, err)
 		}
 		return
 	}
-	WriteAPIError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Internal server error")
+	WriteInternalError(w, r, err)
 }
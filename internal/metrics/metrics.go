@@ -0,0 +1,138 @@
+// Package metrics holds the Prometheus collectors exported on /metrics.
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "pullrequests"
+
+// gaugeTimeout bounds the storage queries made while serving a scrape.
+const gaugeTimeout = 5 * time.Second
+
+var registry = prometheus.NewRegistry()
+
+var (
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by method, chi route pattern and status code.",
+	}, []string{"method", "route", "status"})
+
+	httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by method and chi route pattern.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	txDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "db_transaction_duration_seconds",
+		Help:      "Duration of storage transactions; every retry attempt is observed separately.",
+		Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"storage"})
+
+	txRollbacks = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "db_transaction_rollbacks_total",
+		Help:      "Storage transactions rolled back because the work or the commit failed.",
+	}, []string{"storage"})
+
+	apiErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "errors_total",
+		Help:      "Error responses by error code.",
+	}, []string{"code"})
+
+	noCandidate = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "no_candidate_total",
+		Help:      "Reassignments that found no replacement reviewer, by team.",
+	}, []string{"team"})
+)
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequests,
+		httpDuration,
+		txDuration,
+		txRollbacks,
+		apiErrors,
+		noCandidate,
+	)
+}
+
+// Handler serves the registered metrics in the Prometheus text format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
+
+func ObserveHTTPRequest(method, route string, status int, duration time.Duration) {
+	httpRequests.WithLabelValues(method, route, strconv.Itoa(status)).Inc()
+	httpDuration.WithLabelValues(method, route).Observe(duration.Seconds())
+}
+
+func IncError(code string) {
+	apiErrors.WithLabelValues(code).Inc()
+}
+
+// Recorder is handed to storages and usecases as domain.TransactionObserver
+// and domain.AssignmentMetrics.
+type Recorder struct{}
+
+// ObserveTransaction records a finished top-level transaction attempt; nested
+// calls joining an outer transaction are not counted.
+func (Recorder) ObserveTransaction(storage string, duration time.Duration, rolledBack bool) {
+	txDuration.WithLabelValues(storage).Observe(duration.Seconds())
+	if rolledBack {
+		txRollbacks.WithLabelValues(storage).Inc()
+	}
+}
+
+func (Recorder) IncNoCandidate(team string) {
+	noCandidate.WithLabelValues(team).Inc()
+}
+
+// RegisterOpenPullRequests exports the number of OPEN pull requests per team,
+// computed by count on every scrape, so count must be a cheap aggregate query.
+func RegisterOpenPullRequests(count func(context.Context) (map[string]int, error)) {
+	registry.MustRegister(&openPullRequestsCollector{count: count})
+}
+
+type openPullRequestsCollector struct {
+	count func(context.Context) (map[string]int, error)
+}
+
+var openPullRequestsDesc = prometheus.NewDesc(
+	prometheus.BuildFQName(namespace, "", "open_pull_requests"),
+	"OPEN pull requests by author team.",
+	[]string{"team"}, nil,
+)
+
+func (c *openPullRequestsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- openPullRequestsDesc
+}
+
+func (c *openPullRequestsCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), gaugeTimeout)
+	defer cancel()
+
+	counts, err := c.count(ctx)
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(openPullRequestsDesc, err)
+		return
+	}
+	for team, open := range counts {
+		ch <- prometheus.MustNewConstMetric(openPullRequestsDesc, prometheus.GaugeValue, float64(open), team)
+	}
+}
@@ -0,0 +1,54 @@
+package migrate
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// advisoryLockKey identifies the migration lock among other advisory locks.
+const advisoryLockKey int64 = 7_283_019_455
+
+type Dialect struct {
+	CreateTable string
+	TableExists string
+	Lock        func(ctx context.Context, conn *sqlx.Conn) error
+	Unlock      func(ctx context.Context, conn *sqlx.Conn) error
+}
+
+var Postgres = Dialect{
+	CreateTable: `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version BIGINT PRIMARY KEY,
+			name TEXT NOT NULL,
+			applied_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		)
+	`,
+	TableExists: "SELECT to_regclass('schema_migrations') IS NOT NULL",
+	Lock: func(ctx context.Context, conn *sqlx.Conn) error {
+		_, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", advisoryLockKey)
+		return err
+	},
+	Unlock: func(ctx context.Context, conn *sqlx.Conn) error {
+		_, err := conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", advisoryLockKey)
+		return err
+	},
+}
+
+// SQLite needs no lock: the database file admits a single writer.
+var SQLite = Dialect{
+	CreateTable: `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INTEGER PRIMARY KEY,
+			name TEXT NOT NULL,
+			applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)
+	`,
+	TableExists: "SELECT COUNT(*) > 0 FROM sqlite_master WHERE type = 'table' AND name = 'schema_migrations'",
+	Lock: func(ctx context.Context, conn *sqlx.Conn) error {
+		return nil
+	},
+	Unlock: func(ctx context.Context, conn *sqlx.Conn) error {
+		return nil
+	},
+}
@@ -0,0 +1,173 @@
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type Status struct {
+	Migration
+	Applied   bool
+	AppliedAt *time.Time
+}
+
+type Runner struct {
+	db         *sqlx.DB
+	dialect    Dialect
+	migrations []Migration
+}
+
+func NewRunner(db *sqlx.DB, dialect Dialect, migrations []Migration) *Runner {
+	return &Runner{db: db, dialect: dialect, migrations: migrations}
+}
+
+func (r *Runner) Up(ctx context.Context) ([]Migration, error) {
+	var applied []Migration
+	err := r.withLock(ctx, func(conn *sqlx.Conn) error {
+		versions, err := r.appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for _, migration := range r.migrations {
+			if _, ok := versions[migration.Version]; ok {
+				continue
+			}
+			if err := r.apply(ctx, conn, migration, migration.Up, true); err != nil {
+				return err
+			}
+			applied = append(applied, migration)
+		}
+		return nil
+	})
+	return applied, err
+}
+
+func (r *Runner) Down(ctx context.Context, steps int) ([]Migration, error) {
+	var reverted []Migration
+	err := r.withLock(ctx, func(conn *sqlx.Conn) error {
+		versions, err := r.appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for i := len(r.migrations) - 1; i >= 0 && len(reverted) < steps; i-- {
+			migration := r.migrations[i]
+			if _, ok := versions[migration.Version]; !ok {
+				continue
+			}
+			if migration.Down == "" {
+				return fmt.Errorf("migration %d_%s has no down script", migration.Version, migration.Name)
+			}
+			if err := r.apply(ctx, conn, migration, migration.Down, false); err != nil {
+				return err
+			}
+			reverted = append(reverted, migration)
+		}
+		return nil
+	})
+	return reverted, err
+}
+
+// Status only reads schema_migrations: it takes no lock and creates nothing,
+// so it is cheap enough for readiness probes. A database without the table
+// reports every migration as pending.
+func (r *Runner) Status(ctx context.Context) ([]Status, error) {
+	var exists bool
+	if err := r.db.QueryRowxContext(ctx, r.dialect.TableExists).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("check schema_migrations: %w", err)
+	}
+	versions := make(map[int64]time.Time)
+	if exists {
+		var err error
+		if versions, err = r.appliedVersions(ctx, r.db); err != nil {
+			return nil, err
+		}
+	}
+
+	statuses := make([]Status, 0, len(r.migrations))
+	for _, migration := range r.migrations {
+		status := Status{Migration: migration}
+		if appliedAt, ok := versions[migration.Version]; ok {
+			status.Applied = true
+			status.AppliedAt = &appliedAt
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// Pending reports how many known migrations have not been applied yet.
+func (r *Runner) Pending(ctx context.Context) (int, error) {
+	statuses, err := r.Status(ctx)
+	if err != nil {
+		return 0, err
+	}
+	pending := 0
+	for _, status := range statuses {
+		if !status.Applied {
+			pending++
+		}
+	}
+	return pending, nil
+}
+
+func (r *Runner) withLock(ctx context.Context, fn func(conn *sqlx.Conn) error) error {
+	conn, err := r.db.Connx(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if err := r.dialect.Lock(ctx, conn); err != nil {
+		return fmt.Errorf("acquire migration lock: %w", err)
+	}
+	defer r.dialect.Unlock(context.WithoutCancel(ctx), conn)
+
+	if _, err := conn.ExecContext(ctx, r.dialect.CreateTable); err != nil {
+		return fmt.Errorf("create schema_migrations: %w", err)
+	}
+	return fn(conn)
+}
+
+func (r *Runner) appliedVersions(ctx context.Context, conn sqlx.QueryerContext) (map[int64]time.Time, error) {
+	rows, err := conn.QueryxContext(ctx, "SELECT version, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	versions := make(map[int64]time.Time)
+	for rows.Next() {
+		var version int64
+		var appliedAt time.Time
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+		versions[version] = appliedAt
+	}
+	return versions, rows.Err()
+}
+
+func (r *Runner) apply(ctx context.Context, conn *sqlx.Conn, migration Migration, script string, up bool) error {
+	tx, err := conn.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, script); err != nil {
+		return fmt.Errorf("migration %d_%s: %w", migration.Version, migration.Name, err)
+	}
+
+	if up {
+		_, err = tx.ExecContext(ctx, r.db.Rebind("INSERT INTO schema_migrations (version, name) VALUES (?, ?)"), migration.Version, migration.Name)
+	} else {
+		_, err = tx.ExecContext(ctx, r.db.Rebind("DELETE FROM schema_migrations WHERE version = ?"), migration.Version)
+	}
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,102 @@
+package tracing
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	semconv "go.opentelemetry.io/otel/semconv/v1.43.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Queries traces the statements of one repository method: every span is
+// named name, e.g. "PRRepo.GetPullRequestByID". ExecContext, Query and
+// QueryRow are traced; the promoted sqlx query methods are not, since their
+// spans could not cover reading the rows.
+type Queries struct {
+	sqlx.ExtContext
+	name   string
+	system string
+}
+
+func WrapQueries(ext sqlx.ExtContext, system, name string) *Queries {
+	return &Queries{ExtContext: ext, name: name, system: system}
+}
+
+func (q *Queries) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	ctx, span := q.start(ctx, query)
+	result, err := q.ExtContext.ExecContext(ctx, query, args...)
+	End(span, err)
+	return result, err
+}
+
+// Query runs query; its span ends when the rows are closed.
+func (q *Queries) Query(ctx context.Context, query string, args ...interface{}) (*Rows, error) {
+	ctx, span := q.start(ctx, query)
+	rows, err := q.ExtContext.QueryxContext(ctx, query, args...)
+	if err != nil {
+		End(span, err)
+		return nil, err
+	}
+	return &Rows{Rows: rows, span: span}, nil
+}
+
+// QueryRow runs query; its span ends when the row is scanned.
+func (q *Queries) QueryRow(ctx context.Context, query string, args ...interface{}) *Row {
+	ctx, span := q.start(ctx, query)
+	return &Row{Row: q.ExtContext.QueryRowxContext(ctx, query, args...), span: span}
+}
+
+func (q *Queries) start(ctx context.Context, query string) (context.Context, trace.Span) {
+	if !enabled.Load() {
+		return ctx, noopSpan
+	}
+	operation := ""
+	if fields := strings.Fields(query); len(fields) > 0 {
+		operation = strings.ToUpper(fields[0])
+	}
+	return Tracer().Start(ctx, q.name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemNameKey.String(q.system),
+			semconv.DBOperationName(operation),
+			semconv.DBQueryText(query),
+		),
+	)
+}
+
+// Rows ends the query span on Close, reporting iteration errors.
+type Rows struct {
+	*sqlx.Rows
+	span trace.Span
+}
+
+func (r *Rows) Close() error {
+	err := r.Rows.Close()
+	if r.span.IsRecording() {
+		spanErr := r.Rows.Err()
+		if spanErr == nil {
+			spanErr = err
+		}
+		End(r.span, spanErr)
+	}
+	return err
+}
+
+// Row ends the query span on Scan; sql.ErrNoRows is not recorded as an error.
+type Row struct {
+	*sqlx.Row
+	span trace.Span
+}
+
+func (r *Row) Scan(dest ...interface{}) error {
+	err := r.Row.Scan(dest...)
+	if errors.Is(err, sql.ErrNoRows) {
+		End(r.span, nil)
+	} else {
+		End(r.span, err)
+	}
+	return err
+}
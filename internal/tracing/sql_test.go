@@ -0,0 +1,91 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	_ "modernc.org/sqlite"
+)
+
+// keptSpans survives the provider shutdown, which resets an in-memory exporter.
+type keptSpans struct {
+	*tracetest.InMemoryExporter
+}
+
+func (keptSpans) Shutdown(context.Context) error {
+	return nil
+}
+
+func TestQuerySpans(t *testing.T) {
+	db, err := sqlx.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+
+	exporter := keptSpans{tracetest.NewInMemoryExporter()}
+	shutdown, err := Setup("test", exporter)
+	if err != nil {
+		t.Fatalf("setup tracing: %v", err)
+	}
+
+	ctx := context.Background()
+	queries := WrapQueries(db, "sqlite", "Repo.Method")
+	if _, err := queries.ExecContext(ctx, "CREATE TABLE items (id INTEGER PRIMARY KEY)"); err != nil {
+		t.Fatalf("create table: %v", err)
+	}
+	if _, err := queries.ExecContext(ctx, "INSERT INTO items (id) VALUES (1), (2)"); err != nil {
+		t.Fatalf("insert: %v", err)
+	}
+
+	rows, err := queries.Query(ctx, "SELECT id FROM items ORDER BY id")
+	if err != nil {
+		t.Fatalf("query: %v", err)
+	}
+	for rows.Next() {
+		if !rows.span.IsRecording() {
+			t.Fatal("query span ended before the rows were read")
+		}
+	}
+	if err := rows.Close(); err != nil {
+		t.Fatalf("close rows: %v", err)
+	}
+	if rows.span.IsRecording() {
+		t.Fatal("query span is still open after the rows were closed")
+	}
+	if err := rows.Close(); err != nil {
+		t.Fatalf("close rows twice: %v", err)
+	}
+
+	var id int
+	row := queries.QueryRow(ctx, "SELECT id FROM items WHERE id = 3")
+	if !row.span.IsRecording() {
+		t.Fatal("row span ended before the row was scanned")
+	}
+	if err := row.Scan(&id); err == nil {
+		t.Fatal("scan of a missing row succeeded")
+	}
+	if row.span.IsRecording() {
+		t.Fatal("row span is still open after the row was scanned")
+	}
+
+	if err := shutdown(ctx); err != nil {
+		t.Fatalf("flush spans: %v", err)
+	}
+	spans := exporter.GetSpans()
+	if len(spans) != 4 {
+		t.Fatalf("got %d spans, want 4", len(spans))
+	}
+	for _, span := range spans {
+		if span.Name != "Repo.Method" {
+			t.Errorf("got span %q, want Repo.Method", span.Name)
+		}
+		if span.Status.Code == codes.Error {
+			t.Errorf("span for %v has error status %q; a missing row is not an error", span.Attributes, span.Status.Description)
+		}
+	}
+}
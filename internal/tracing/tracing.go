@@ -0,0 +1,78 @@
+// Package tracing wires OpenTelemetry spans through handlers, transactions
+// and SQL queries.
+package tracing
+
+import (
+	"context"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.43.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "pullrequests"
+
+// enabled skips building span attributes while no exporter is installed.
+var enabled atomic.Bool
+
+// noopSpan is handed out while tracing is disabled; ending it is harmless.
+var noopSpan = trace.SpanFromContext(context.Background())
+
+// Setup installs a tracer provider exporting to exporter and the W3C trace
+// context propagator. The returned function flushes pending spans.
+func Setup(serviceName string, exporter sdktrace.SpanExporter) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(serviceName),
+	))
+	if err != nil {
+		return nil, err
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+	enabled.Store(true)
+
+	return func(ctx context.Context) error {
+		enabled.Store(false)
+		return provider.Shutdown(ctx)
+	}, nil
+}
+
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// StartTransaction starts the span of a TransactionManager.Do, named as
+// given by domain.WithName or "db.transaction" when the caller set no name.
+func StartTransaction(ctx context.Context, name string, nested bool) (context.Context, trace.Span) {
+	if !enabled.Load() {
+		return ctx, noopSpan
+	}
+	if name == "" {
+		name = "db.transaction"
+	}
+	return Tracer().Start(ctx, name, trace.WithAttributes(attribute.Bool("db.transaction.nested", nested)))
+}
+
+// End records err on span, if any, and ends it.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
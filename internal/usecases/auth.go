@@ -0,0 +1,182 @@
+package usecases
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"pullrequests/internal/domain"
+	"pullrequests/internal/dtos"
+	"strings"
+	"time"
+)
+
+const tokenSecretPrefix = "prt_"
+
+type AuthUsecase struct {
+	tokenRepo domain.APITokenRepo
+	userRepo  domain.UserRepo
+	verifier  domain.TokenVerifier
+	trm       domain.TransactionManager
+}
+
+// NewAuthUsecase accepts a nil verifier when bearer JWTs are not configured.
+func NewAuthUsecase(
+	tokenRepo domain.APITokenRepo,
+	userRepo domain.UserRepo,
+	verifier domain.TokenVerifier,
+	trm domain.TransactionManager) *AuthUsecase {
+	return &AuthUsecase{
+		tokenRepo: tokenRepo,
+		userRepo:  userRepo,
+		verifier:  verifier,
+		trm:       trm,
+	}
+}
+
+func (u *AuthUsecase) Authenticate(ctx context.Context, secret string) (*domain.Actor, error) {
+	if u.verifier != nil && strings.Count(secret, ".") == 2 {
+		return u.verifier.Verify(ctx, secret)
+	}
+
+	var actor *domain.Actor
+	err := u.trm.Do(ctx, func(ctx context.Context) error {
+		token, err := u.tokenRepo.GetTokenByHash(ctx, hashSecret(secret))
+		if err != nil {
+			return err
+		}
+		if token == nil || token.RevokedAt != nil {
+			return domain.NewDomainError(domain.ErrUnauthorizedCode)
+		}
+		actor = &domain.Actor{
+			UserID:  token.UserID,
+			Role:    token.Role,
+			TokenID: token.TokenID,
+		}
+		return nil
+	}, domain.WithName("AuthUsecase.Authenticate"), domain.WithReadOnly())
+	if err != nil {
+		return nil, err
+	}
+	return actor, nil
+}
+
+func (u *AuthUsecase) CreateToken(ctx context.Context, req dtos.CreateTokenRequest) (*dtos.CreateTokenResponse, error) {
+	secret, err := randomString(32)
+	if err != nil {
+		return nil, err
+	}
+	secret = tokenSecretPrefix + secret
+	tokenID, err := randomString(9)
+	if err != nil {
+		return nil, err
+	}
+
+	token := &domain.APIToken{
+		TokenID:   "tok_" + tokenID,
+		Name:      req.Name,
+		TokenHash: hashSecret(secret),
+		Role:      domain.APIRole(req.Role),
+		UserID:    req.UserID,
+		CreatedAt: time.Now(),
+	}
+
+	err = u.trm.Do(ctx, func(ctx context.Context) error {
+		if token.UserID != "" {
+			user, err := u.userRepo.GetUserByID(ctx, token.UserID)
+			if err != nil {
+				return err
+			}
+			if user == nil {
+				return domain.NewDomainError(domain.ErrNotFoundCode)
+			}
+		}
+		return u.tokenRepo.Add(ctx, token)
+	}, domain.WithName("AuthUsecase.CreateToken"))
+	if err != nil {
+		return nil, err
+	}
+
+	return &dtos.CreateTokenResponse{
+		Token:  toTokenDTO(token),
+		Secret: secret,
+	}, nil
+}
+
+// EnsureToken registers a token with a caller-provided secret, e.g. the
+// bootstrap admin token from the configuration, if it is not known yet.
+func (u *AuthUsecase) EnsureToken(ctx context.Context, name, secret string, role domain.APIRole) error {
+	return u.trm.Do(ctx, func(ctx context.Context) error {
+		existing, err := u.tokenRepo.GetTokenByHash(ctx, hashSecret(secret))
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			return nil
+		}
+		tokenID, err := randomString(9)
+		if err != nil {
+			return err
+		}
+		return u.tokenRepo.Add(ctx, &domain.APIToken{
+			TokenID:   "tok_" + tokenID,
+			Name:      name,
+			TokenHash: hashSecret(secret),
+			Role:      role,
+			CreatedAt: time.Now(),
+		})
+	}, domain.WithName("AuthUsecase.EnsureToken"))
+}
+
+func (u *AuthUsecase) GetTokens(ctx context.Context) (*dtos.TokensResponse, error) {
+	var response *dtos.TokensResponse
+	err := u.trm.Do(ctx, func(ctx context.Context) error {
+		tokens, err := u.tokenRepo.GetTokens(ctx)
+		if err != nil {
+			return err
+		}
+		response = &dtos.TokensResponse{Tokens: make([]dtos.APIToken, 0, len(tokens))}
+		for _, token := range tokens {
+			response.Tokens = append(response.Tokens, toTokenDTO(&token))
+		}
+		return nil
+	}, domain.WithName("AuthUsecase.GetTokens"), domain.WithReadOnly())
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (u *AuthUsecase) RevokeToken(ctx context.Context, req dtos.RevokeTokenRequest) error {
+	return u.trm.Do(ctx, func(ctx context.Context) error {
+		return u.tokenRepo.RevokeToken(ctx, req.TokenID, time.Now())
+	}, domain.WithName("AuthUsecase.RevokeToken"))
+}
+
+func toTokenDTO(token *domain.APIToken) dtos.APIToken {
+	out := dtos.APIToken{
+		TokenID:   token.TokenID,
+		Name:      token.Name,
+		Role:      string(token.Role),
+		UserID:    token.UserID,
+		CreatedAt: token.CreatedAt.Format(time.RFC3339),
+	}
+	if token.RevokedAt != nil {
+		out.RevokedAt = token.RevokedAt.Format(time.RFC3339)
+	}
+	return out
+}
+
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomString(size int) (string, error) {
+	buf := make([]byte, size)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
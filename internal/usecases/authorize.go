@@ -0,0 +1,27 @@
+package usecases
+
+import (
+	"context"
+	"pullrequests/internal/domain"
+)
+
+// isTeamLead reports whether the actor may act on behalf of members of the team.
+// Admins lead every team; other actors must be a LEAD member of the team or hold
+// a TEAM_LEAD token bound to one of its members.
+func isTeamLead(ctx context.Context, userRepo domain.UserRepo, actor *domain.Actor, teamName string) (bool, error) {
+	if actor.Role == domain.APIRoleAdmin {
+		return true, nil
+	}
+	if actor.UserID == "" {
+		return false, nil
+	}
+
+	user, err := userRepo.GetUserByID(ctx, actor.UserID)
+	if err != nil {
+		return false, err
+	}
+	if user == nil || user.TeamName != teamName {
+		return false, nil
+	}
+	return user.Role == domain.TeamRoleLead || actor.Role == domain.APIRoleTeamLead, nil
+}
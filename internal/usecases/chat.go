@@ -0,0 +1,236 @@
+package usecases
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"pullrequests/internal/domain"
+	"pullrequests/internal/dtos"
+	"text/template"
+)
+
+// ChatEvents are the event types that notify users in chat; templates are
+// named after them.
+var ChatEvents = []domain.EventType{
+	domain.EventReviewerAssigned,
+	domain.EventReviewerReassigned,
+	domain.EventPRMerged,
+	domain.EventReviewReminder,
+}
+
+const defaultChatTemplates = `
+{{- define "reviewer.assigned"}}{{.Mention}} please review *{{.PullRequestName}}* ({{.PullRequestID}}) by {{.Author}}.{{end}}
+{{- define "reviewer.reassigned"}}{{.Mention}} {{.NewReviewer}} took over your review of *{{.PullRequestName}}* ({{.PullRequestID}}) by {{.Author}}.{{end}}
+{{- define "review.reminder"}}{{.Mention}} *{{.PullRequestName}}* ({{.PullRequestID}}) by {{.Author}} is still waiting for your review.{{end}}
+{{- define "pr.merged"}}{{.Mention}} *{{.PullRequestName}}* ({{.PullRequestID}}) by {{.Author}} was merged.{{end}}
+`
+
+// ChatMessageData is passed to chat message templates. User fields hold
+// usernames, or user IDs for users that no longer exist.
+type ChatMessageData struct {
+	Event           string
+	PullRequestID   string
+	PullRequestName string
+	Author          string
+	Recipient       string
+	Mention         string
+	// OldReviewer and NewReviewer are only set for reviewer.reassigned.
+	OldReviewer string
+	NewReviewer string
+	Reviewers   []string
+}
+
+// NewChatTemplates parses the built-in templates followed by overrides, which
+// may redefine any of them with {{define "reviewer.assigned"}}...{{end}}.
+func NewChatTemplates(overrides string) (*template.Template, error) {
+	templates, err := template.New("chat").Option("missingkey=error").Parse(defaultChatTemplates)
+	if err != nil {
+		return nil, err
+	}
+	if overrides != "" {
+		if templates, err = templates.Parse(overrides); err != nil {
+			return nil, fmt.Errorf("parse chat templates: %w", err)
+		}
+	}
+	return templates, nil
+}
+
+// ChatNotifier posts a message to each user concerned by an event: the
+// reviewer on assignment and reminders, the replaced reviewer on reassignment
+// (the new one also gets reviewer.assigned), every reviewer on merge. Messages
+// go to the user's own channel, else to the team channel, else to the default
+// channel of the webhook.
+type ChatNotifier struct {
+	notificationRepo domain.NotificationRepo
+	userRepo         domain.UserRepo
+	pullrequestRepo  domain.PullRequestRepo
+	queue            jobQueue
+	sender           domain.ChatSender
+	templates        *template.Template
+	teamChannels     map[string]string
+}
+
+func NewChatNotifier(
+	notificationRepo domain.NotificationRepo,
+	userRepo domain.UserRepo,
+	pullrequestRepo domain.PullRequestRepo,
+	jobRepo domain.JobRepo,
+	trm domain.TransactionManager,
+	retry RetryPolicy,
+	sender domain.ChatSender,
+	templates *template.Template,
+	teamChannels map[string]string) *ChatNotifier {
+	return &ChatNotifier{
+		notificationRepo: notificationRepo,
+		userRepo:         userRepo,
+		pullrequestRepo:  pullrequestRepo,
+		queue:            newJobQueue(jobRepo, trm, domain.JobChatMessage, retry),
+		sender:           sender,
+		templates:        templates,
+		teamChannels:     teamChannels,
+	}
+}
+
+// HandleEvent is an EventHandler for ChatEvents. It renders the messages and
+// queues them as chat.message jobs inside the dispatcher transaction; SendDue
+// posts them.
+func (n *ChatNotifier) HandleEvent(ctx context.Context, event domain.Event) error {
+	data := ChatMessageData{Event: string(event.Type)}
+	var recipients []string
+
+	switch event.Type {
+	case domain.EventReviewerAssigned:
+		var payload dtos.ReviewerAssignedEvent
+		if err := decodeEventData(event, &payload); err != nil {
+			return err
+		}
+		if err := n.fillPullRequest(ctx, &data, payload.PullRequestID); err != nil {
+			return err
+		}
+		recipients = []string{payload.ReviewerID}
+	case domain.EventReviewReminder:
+		var payload dtos.ReviewReminderEvent
+		if err := decodeEventData(event, &payload); err != nil {
+			return err
+		}
+		if err := n.fillPullRequest(ctx, &data, payload.PullRequestID); err != nil {
+			return err
+		}
+		recipients = []string{payload.ReviewerID}
+	case domain.EventReviewerReassigned:
+		var payload dtos.ReviewerReassignedEvent
+		if err := decodeEventData(event, &payload); err != nil {
+			return err
+		}
+		if err := n.fillPullRequest(ctx, &data, payload.PullRequestID); err != nil {
+			return err
+		}
+		var err error
+		if data.OldReviewer, err = n.username(ctx, payload.OldReviewerID); err != nil {
+			return err
+		}
+		if data.NewReviewer, err = n.username(ctx, payload.NewReviewerID); err != nil {
+			return err
+		}
+		recipients = []string{payload.OldReviewerID}
+	case domain.EventPRMerged:
+		var payload dtos.PullRequest
+		if err := decodeEventData(event, &payload); err != nil {
+			return err
+		}
+		if err := n.fillPullRequest(ctx, &data, payload.PullRequestID); err != nil {
+			return err
+		}
+		recipients = payload.AssignedReviewers
+	default:
+		return nil
+	}
+
+	for _, userID := range recipients {
+		if err := n.notify(ctx, event.Type, userID, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (n *ChatNotifier) fillPullRequest(ctx context.Context, data *ChatMessageData, pullRequestID string) error {
+	data.PullRequestID = pullRequestID
+	pullrequest, err := n.pullrequestRepo.GetPullRequestByID(ctx, pullRequestID)
+	if err != nil || pullrequest == nil {
+		return err
+	}
+	data.PullRequestName = pullrequest.PullRequestName
+
+	if data.Author, err = n.username(ctx, pullrequest.AuthorID); err != nil {
+		return err
+	}
+	reviewers, err := n.pullrequestRepo.GetReviewers(ctx, pullRequestID)
+	if err != nil {
+		return err
+	}
+	for _, reviewer := range reviewers {
+		username, err := n.username(ctx, reviewer.UserID)
+		if err != nil {
+			return err
+		}
+		data.Reviewers = append(data.Reviewers, username)
+	}
+	return nil
+}
+
+func (n *ChatNotifier) notify(ctx context.Context, eventType domain.EventType, userID string, data ChatMessageData) error {
+	user, err := n.userRepo.GetUserByID(ctx, userID)
+	if err != nil || user == nil {
+		return err
+	}
+	preferences, err := loadPreferences(ctx, n.notificationRepo, userID)
+	if err != nil {
+		return err
+	}
+	if !preferences.WantsChat(eventType) {
+		return nil
+	}
+
+	data.Recipient = user.Username
+	data.Mention = "@" + user.Username
+	var text bytes.Buffer
+	if err := n.templates.ExecuteTemplate(&text, string(eventType), data); err != nil {
+		return fmt.Errorf("render %s chat message: %w", eventType, err)
+	}
+
+	channel := preferences.ChatChannel
+	if channel == "" {
+		channel = n.teamChannels[user.TeamName]
+	}
+	return n.queue.enqueue(ctx, chatMessageJob{Channel: channel, Text: text.String()})
+}
+
+// chatMessageJob is the payload of a chat.message job.
+type chatMessageJob struct {
+	Channel string `json:"channel,omitempty"`
+	Text    string `json:"text"`
+}
+
+// SendDue posts due chat.message jobs. It returns the number of jobs run.
+func (n *ChatNotifier) SendDue(ctx context.Context) (int, error) {
+	return n.queue.runDue(ctx, func(ctx context.Context, job *domain.Job) error {
+		var payload chatMessageJob
+		if err := json.Unmarshal(job.Payload, &payload); err != nil {
+			return &permanentError{err: err}
+		}
+		return n.sender.Send(ctx, &domain.ChatMessage{Channel: payload.Channel, Text: payload.Text})
+	})
+}
+
+func (n *ChatNotifier) username(ctx context.Context, userID string) (string, error) {
+	user, err := n.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	if user == nil {
+		return userID, nil
+	}
+	return user.Username, nil
+}
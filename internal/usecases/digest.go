@@ -0,0 +1,140 @@
+package usecases
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"pullrequests/internal/domain"
+	"text/template"
+	"time"
+)
+
+var digestTemplate = template.Must(template.New("digest").Parse(`Hi {{.Username}},
+
+{{len .PullRequests}} pull request(s) are waiting for your review:
+{{range .PullRequests}}
+- {{.PullRequestName}} ({{.PullRequestID}}), opened {{.CreatedAt.Format "2006-01-02"}}
+{{- end}}
+`))
+
+type digestData struct {
+	Username     string
+	PullRequests []domain.PullRequest
+}
+
+// DigestUsecase emails users a daily list of the OPEN pull requests they are
+// assigned to review, once their delivery hour has passed in their timezone.
+type DigestUsecase struct {
+	notificationRepo domain.NotificationRepo
+	pullrequestRepo  domain.PullRequestRepo
+	userRepo         domain.UserRepo
+	sender           domain.EmailSender
+	trm              domain.TransactionManager
+}
+
+func NewDigestUsecase(
+	notificationRepo domain.NotificationRepo,
+	pullrequestRepo domain.PullRequestRepo,
+	userRepo domain.UserRepo,
+	sender domain.EmailSender,
+	trm domain.TransactionManager) *DigestUsecase {
+	return &DigestUsecase{
+		notificationRepo: notificationRepo,
+		pullrequestRepo:  pullrequestRepo,
+		userRepo:         userRepo,
+		sender:           sender,
+		trm:              trm,
+	}
+}
+
+// SendDue sends every due digest and returns how many were sent. Failures are
+// joined into the returned error; those digests are retried on the next run.
+func (u *DigestUsecase) SendDue(ctx context.Context) (int, error) {
+	var recipients []domain.NotificationPreferences
+	err := u.trm.Do(ctx, func(ctx context.Context) error {
+		var err error
+		recipients, err = u.notificationRepo.GetDigestRecipients(ctx)
+		return err
+	}, domain.WithName("DigestUsecase.SendDue"))
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	sent := 0
+	var errs []error
+	for i := range recipients {
+		if !recipients[i].DigestDue(now) {
+			continue
+		}
+		delivered, err := u.send(ctx, recipients[i].UserID, now)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("digest for %s: %w", recipients[i].UserID, err))
+			continue
+		}
+		if delivered {
+			sent++
+		}
+	}
+	return sent, errors.Join(errs...)
+}
+
+// send marks the digest as sent before emailing it, so concurrent runs do not
+// send it twice, and clears the mark again when the email fails. Users with
+// nothing to review get no email.
+func (u *DigestUsecase) send(ctx context.Context, userID string, now time.Time) (bool, error) {
+	var preferences *domain.NotificationPreferences
+	var previous *time.Time
+	data := digestData{}
+	err := u.trm.Do(ctx, func(ctx context.Context) error {
+		stored, err := u.notificationRepo.GetPreferences(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if stored == nil || !stored.DigestDue(now) {
+			return nil
+		}
+		user, err := u.userRepo.GetUserByID(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if user == nil {
+			return nil
+		}
+		assigned, err := u.pullrequestRepo.GetUserAssignedPRs(ctx, userID)
+		if err != nil {
+			return err
+		}
+		for _, pullrequest := range assigned {
+			if pullrequest.Status == domain.PRStatusOpen {
+				data.PullRequests = append(data.PullRequests, pullrequest)
+			}
+		}
+		data.Username = user.Username
+		preferences, previous = stored, stored.DigestSentAt
+		return u.notificationRepo.SetDigestSentAt(ctx, userID, &now)
+	}, domain.WithName("DigestUsecase.send"), domain.WithIsolation(domain.IsolationSerializable))
+	if err != nil || preferences == nil || len(data.PullRequests) == 0 {
+		return false, err
+	}
+
+	var body bytes.Buffer
+	if err := digestTemplate.Execute(&body, data); err != nil {
+		return false, err
+	}
+	err = u.sender.Send(ctx, &domain.EmailMessage{
+		To:      preferences.Email,
+		Subject: fmt.Sprintf("%d pull request(s) waiting for your review", len(data.PullRequests)),
+		Body:    body.String(),
+	})
+	if err != nil {
+		if resetErr := u.trm.Do(ctx, func(ctx context.Context) error {
+			return u.notificationRepo.SetDigestSentAt(ctx, userID, previous)
+		}, domain.WithName("DigestUsecase.send")); resetErr != nil {
+			return false, errors.Join(err, resetErr)
+		}
+		return false, err
+	}
+	return true, nil
+}
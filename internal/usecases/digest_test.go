@@ -0,0 +1,158 @@
+package usecases_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/mail"
+	"pullrequests/internal/adapters/email"
+	"pullrequests/internal/adapters/email/smtptest"
+	"pullrequests/internal/adapters/memory"
+	"pullrequests/internal/domain"
+	"pullrequests/internal/dtos"
+	"pullrequests/internal/metrics"
+	"pullrequests/internal/usecases"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+type digestEnv struct {
+	digests       *usecases.DigestUsecase
+	notifications *memory.NotificationRepo
+	server        *smtptest.Server
+}
+
+// newDigestEnv sends digests over SMTP to a stand-in server. Bob reviews an
+// open and a merged pull request by Alice; both have an email and a digest
+// hour of 0, so their digests are due at once.
+func newDigestEnv(t *testing.T) *digestEnv {
+	t.Helper()
+	ctx := context.Background()
+	store := memory.NewStore()
+	trm := memory.NewTransactionManager(store)
+	historyRepo := memory.NewReviewHistoryRepo(store)
+	notificationRepo := memory.NewNotificationRepo(store)
+
+	teams := usecases.NewTeamUsecase(memory.NewTeamRepo(store), memory.NewUserRepo(store), trm)
+	_, err := teams.AddTeam(ctx, dtos.TeamRequest{Team: dtos.Team{
+		TeamName: "backend",
+		Members: []dtos.TeamMember{
+			{UserID: "u1", Username: "alice", IsActive: true},
+			{UserID: "u2", Username: "bob", IsActive: true},
+		},
+	}})
+	if err != nil {
+		t.Fatalf("add team: %v", err)
+	}
+	pullrequests := usecases.NewPRUsecase(memory.NewPRRepo(store), memory.NewUserRepo(store), historyRepo,
+		usecases.NewFirstNStrategy(), usecases.NewOutboxPublisher(memory.NewOutboxRepo(store)), metrics.Recorder{}, trm)
+	for _, req := range []dtos.CreatePRRequest{
+		{PullRequestID: "pr-1", PullRequestName: "Add search", AuthorID: "u1"},
+		{PullRequestID: "pr-2", PullRequestName: "Fix typo", AuthorID: "u1"},
+	} {
+		if _, err := pullrequests.CreatePR(ctx, req); err != nil {
+			t.Fatalf("create %s: %v", req.PullRequestID, err)
+		}
+	}
+	if _, err := pullrequests.MergePR(ctx, dtos.MergePRRequest{PullRequestID: "pr-2"}); err != nil {
+		t.Fatalf("merge: %v", err)
+	}
+
+	for userID, address := range map[string]string{"u1": "alice@example.com", "u2": "bob@example.com"} {
+		preferences := domain.DefaultNotificationPreferences(userID)
+		preferences.Email = address
+		preferences.DigestHour = 0
+		if err := notificationRepo.SetPreferences(ctx, preferences); err != nil {
+			t.Fatalf("set preferences: %v", err)
+		}
+	}
+
+	server := smtptest.NewServer(t)
+	sender := email.NewSMTPSender(email.SMTPConfig{
+		Host:    server.Host,
+		Port:    server.Port,
+		From:    "reviews@example.com",
+		Timeout: 5 * time.Second,
+	})
+	return &digestEnv{
+		digests:       usecases.NewDigestUsecase(notificationRepo, memory.NewPRRepo(store), memory.NewUserRepo(store), sender, trm),
+		notifications: notificationRepo,
+		server:        server,
+	}
+}
+
+func (env *digestEnv) sentAt(t *testing.T, userID string) *time.Time {
+	t.Helper()
+	preferences, err := env.notifications.GetPreferences(context.Background(), userID)
+	if err != nil {
+		t.Fatalf("get preferences: %v", err)
+	}
+	return preferences.DigestSentAt
+}
+
+func TestDigestEmail(t *testing.T) {
+	ctx := context.Background()
+	env := newDigestEnv(t)
+
+	sent, err := env.digests.SendDue(ctx)
+	if err != nil || sent != 1 {
+		t.Fatalf("SendDue: sent %d (err %v), want 1", sent, err)
+	}
+
+	// Alice reviews nothing and gets no email.
+	messages := env.server.Messages()
+	if len(messages) != 1 {
+		t.Fatalf("server got %d messages, want 1", len(messages))
+	}
+	if !slices.Equal(messages[0].To, []string{"bob@example.com"}) {
+		t.Errorf("digest sent to %v, want bob@example.com", messages[0].To)
+	}
+	message, err := mail.ReadMessage(bytes.NewReader(messages[0].Data))
+	if err != nil {
+		t.Fatalf("parse message: %v", err)
+	}
+	if got := message.Header.Get("Subject"); got != "1 pull request(s) waiting for your review" {
+		t.Errorf("got subject %q", got)
+	}
+	body, _ := io.ReadAll(message.Body)
+	if !strings.Contains(string(body), "Hi bob,") || !strings.Contains(string(body), "- Add search (pr-1), opened ") {
+		t.Errorf("digest does not list the open pull request:\n%s", body)
+	}
+	if strings.Contains(string(body), "pr-2") {
+		t.Errorf("digest lists the merged pull request:\n%s", body)
+	}
+	if env.sentAt(t, "u2") == nil {
+		t.Error("digest_sent_at is not set after sending")
+	}
+
+	// The digest goes out once a day.
+	sent, err = env.digests.SendDue(ctx)
+	if err != nil || sent != 0 {
+		t.Fatalf("second SendDue: sent %d (err %v), want 0", sent, err)
+	}
+	if got := len(env.server.Messages()); got != 1 {
+		t.Errorf("server got %d messages after the second run, want 1", got)
+	}
+}
+
+func TestDigestEmailRetriedAfterFailure(t *testing.T) {
+	ctx := context.Background()
+	env := newDigestEnv(t)
+	env.server.RejectRecipient("bob@example.com")
+
+	for run := 1; run <= 2; run++ {
+		sent, err := env.digests.SendDue(ctx)
+		if err == nil || sent != 0 {
+			t.Fatalf("run %d: sent %d (err %v), want the rejected recipient reported", run, sent, err)
+		}
+		// The sent mark is cleared again, so the next run retries.
+		if got := env.sentAt(t, "u2"); got != nil {
+			t.Fatalf("run %d: digest_sent_at = %v after a failed send, want unset", run, got)
+		}
+	}
+	if got := len(env.server.Messages()); got != 0 {
+		t.Errorf("server got %d messages, want 0", got)
+	}
+}
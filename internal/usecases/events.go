@@ -0,0 +1,37 @@
+package usecases
+
+import (
+	"context"
+	"pullrequests/internal/domain"
+	"pullrequests/internal/dtos"
+	"time"
+)
+
+func publishEvent(ctx context.Context, publisher domain.EventPublisher, eventType domain.EventType, data interface{}) error {
+	eventID, err := randomString(12)
+	if err != nil {
+		return err
+	}
+	return publisher.Publish(ctx, domain.Event{
+		EventID:    "evt_" + eventID,
+		Type:       eventType,
+		OccurredAt: time.Now(),
+		Data:       data,
+	})
+}
+
+func toPullRequestDTO(pullrequest *domain.PullRequest, reviewers []string) dtos.PullRequest {
+	out := dtos.PullRequest{
+		PullRequestID:     pullrequest.PullRequestID,
+		PullRequestName:   pullrequest.PullRequestName,
+		AuthorID:          pullrequest.AuthorID,
+		Status:            string(pullrequest.Status),
+		AssignedReviewers: reviewers,
+		CreatedAt:         pullrequest.CreatedAt.Format(time.RFC3339),
+		Version:           pullrequest.Version,
+	}
+	if pullrequest.MergedAt != nil {
+		out.MergedAt = pullrequest.MergedAt.Format(time.RFC3339)
+	}
+	return out
+}
@@ -0,0 +1,66 @@
+package usecases
+
+import (
+	"context"
+	"log/slog"
+	"pullrequests/internal/dtos"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	HealthStatusOK          = "ok"
+	HealthStatusUnavailable = "unavailable"
+	HealthStatusDraining    = "draining"
+)
+
+// HealthCheck is a dependency that must be available for the service to
+// accept traffic.
+type HealthCheck struct {
+	Name  string
+	Check func(ctx context.Context) error
+}
+
+type HealthUsecase struct {
+	checks   []HealthCheck
+	timeout  time.Duration
+	draining atomic.Bool
+}
+
+func NewHealthUsecase(timeout time.Duration, checks ...HealthCheck) *HealthUsecase {
+	return &HealthUsecase{checks: checks, timeout: timeout}
+}
+
+// StartDraining makes the service report itself not ready for good, so that
+// load balancers stop routing to it before the server shuts down.
+func (u *HealthUsecase) StartDraining() {
+	u.draining.Store(true)
+}
+
+// Readiness runs every check within the timeout. The response is ready only
+// when all checks pass and the service is not draining. Check errors are only
+// logged: the endpoint is public and errors may describe the infrastructure.
+func (u *HealthUsecase) Readiness(ctx context.Context) (*dtos.ReadinessResponse, bool) {
+	if u.draining.Load() {
+		return &dtos.ReadinessResponse{Status: HealthStatusDraining}, false
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, u.timeout)
+	defer cancel()
+
+	response := &dtos.ReadinessResponse{Status: HealthStatusOK, Checks: make(map[string]string, len(u.checks))}
+	ready := true
+	for _, check := range u.checks {
+		if err := check.Check(ctx); err != nil {
+			slog.WarnContext(ctx, "readiness check failed", "check", check.Name, "error", err)
+			response.Checks[check.Name] = HealthStatusUnavailable
+			ready = false
+			continue
+		}
+		response.Checks[check.Name] = HealthStatusOK
+	}
+	if !ready {
+		response.Status = HealthStatusUnavailable
+	}
+	return response, ready
+}
@@ -0,0 +1,87 @@
+package usecases
+
+import (
+	"context"
+	"pullrequests/internal/domain"
+	"time"
+)
+
+type IdempotencyUsecase struct {
+	idempotencyRepo domain.IdempotencyRepo
+	trm             domain.TransactionManager
+	ttl             time.Duration
+}
+
+func NewIdempotencyUsecase(
+	idempotencyRepo domain.IdempotencyRepo,
+	trm domain.TransactionManager,
+	ttl time.Duration) *IdempotencyUsecase {
+	return &IdempotencyUsecase{
+		idempotencyRepo: idempotencyRepo,
+		trm:             trm,
+		ttl:             ttl,
+	}
+}
+
+// Begin returns the stored response for a completed request, or nil once the
+// key has been reserved and the request should be executed.
+func (u *IdempotencyUsecase) Begin(ctx context.Context, actorID, key, route, requestHash string) (*domain.IdempotencyRecord, error) {
+	var stored *domain.IdempotencyRecord
+
+	err := u.trm.Do(ctx, func(ctx context.Context) error {
+		now := time.Now()
+		record, err := u.idempotencyRepo.Get(ctx, actorID, key, route)
+		if err != nil {
+			return err
+		}
+		if record != nil && record.ExpiresAt.Before(now) {
+			if err := u.idempotencyRepo.Delete(ctx, actorID, key, route); err != nil {
+				return err
+			}
+			record = nil
+		}
+
+		if record == nil {
+			reserved, err := u.idempotencyRepo.Reserve(ctx, &domain.IdempotencyRecord{
+				ActorID:     actorID,
+				Key:         key,
+				Route:       route,
+				RequestHash: requestHash,
+				CreatedAt:   now,
+				ExpiresAt:   now.Add(u.ttl),
+			})
+			if err != nil {
+				return err
+			}
+			if !reserved {
+				return domain.NewDomainError(domain.ErrIdempotencyInProgressCode)
+			}
+			return nil
+		}
+
+		if record.RequestHash != requestHash {
+			return domain.NewDomainError(domain.ErrIdempotencyMismatchCode)
+		}
+		if !record.IsCompleted() {
+			return domain.NewDomainError(domain.ErrIdempotencyInProgressCode)
+		}
+		stored = record
+		return nil
+	}, domain.WithName("IdempotencyUsecase.Begin"))
+	if err != nil {
+		return nil, err
+	}
+	return stored, nil
+}
+
+func (u *IdempotencyUsecase) Complete(ctx context.Context, record *domain.IdempotencyRecord) error {
+	return u.trm.Do(ctx, func(ctx context.Context) error {
+		return u.idempotencyRepo.Complete(ctx, record)
+	}, domain.WithName("IdempotencyUsecase.Complete"))
+}
+
+func (u *IdempotencyUsecase) Release(ctx context.Context, actorID, key, route string) error {
+	return u.trm.Do(ctx, func(ctx context.Context) error {
+		return u.idempotencyRepo.Delete(ctx, actorID, key, route)
+	}, domain.WithName("IdempotencyUsecase.Release"))
+}
@@ -0,0 +1,156 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"pullrequests/internal/domain"
+	"pullrequests/internal/dtos"
+	"strings"
+	"time"
+)
+
+const (
+	IntegrationStatusCreated   = "created"
+	IntegrationStatusMerged    = "merged"
+	IntegrationStatusUnchanged = "unchanged"
+	IntegrationStatusIgnored   = "ignored"
+)
+
+type IntegrationUsecase struct {
+	identityRepo domain.IdentityRepo
+	prUsecase    *PRUsecase
+	trm          domain.TransactionManager
+}
+
+func NewIntegrationUsecase(
+	identityRepo domain.IdentityRepo,
+	prUsecase *PRUsecase,
+	trm domain.TransactionManager) *IntegrationUsecase {
+	return &IntegrationUsecase{
+		identityRepo: identityRepo,
+		prUsecase:    prUsecase,
+		trm:          trm,
+	}
+}
+
+func (u *IntegrationUsecase) SetIdentity(ctx context.Context, req dtos.SetIdentityRequest) (*dtos.IdentityResponse, error) {
+	identity := &domain.ExternalIdentity{
+		Provider:  req.Provider,
+		Login:     strings.ToLower(req.Login),
+		UserID:    req.UserID,
+		CreatedAt: time.Now(),
+	}
+
+	err := u.trm.Do(ctx, func(ctx context.Context) error {
+		if err := u.identityRepo.SetIdentity(ctx, identity); err != nil {
+			return err
+		}
+		stored, err := u.identityRepo.GetIdentity(ctx, identity.Provider, identity.Login)
+		if err != nil {
+			return err
+		}
+		identity = stored
+		return nil
+	}, domain.WithName("IntegrationUsecase.SetIdentity"))
+	if err != nil {
+		return nil, err
+	}
+	return &dtos.IdentityResponse{Identity: toIdentityDTO(identity)}, nil
+}
+
+func (u *IntegrationUsecase) GetIdentities(ctx context.Context, provider string) (*dtos.IdentitiesResponse, error) {
+	var response *dtos.IdentitiesResponse
+	err := u.trm.Do(ctx, func(ctx context.Context) error {
+		identities, err := u.identityRepo.GetIdentities(ctx, provider)
+		if err != nil {
+			return err
+		}
+		response = &dtos.IdentitiesResponse{Identities: make([]dtos.Identity, 0, len(identities))}
+		for _, identity := range identities {
+			response.Identities = append(response.Identities, toIdentityDTO(&identity))
+		}
+		return nil
+	}, domain.WithName("IntegrationUsecase.GetIdentities"), domain.WithReadOnly())
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (u *IntegrationUsecase) DeleteIdentity(ctx context.Context, req dtos.DeleteIdentityRequest) error {
+	return u.trm.Do(ctx, func(ctx context.Context) error {
+		return u.identityRepo.DeleteIdentity(ctx, req.Provider, strings.ToLower(req.Login))
+	}, domain.WithName("IntegrationUsecase.DeleteIdentity"))
+}
+
+// HandlePullRequestEvent applies a pull request change reported by a code
+// hosting provider. Replays are harmless: opening a known PR and merging a
+// merged one leave it unchanged.
+func (u *IntegrationUsecase) HandlePullRequestEvent(ctx context.Context, event dtos.VCSPullRequestEvent) (*dtos.IntegrationResult, error) {
+	switch event.Action {
+	case dtos.VCSActionOpen:
+		authorID, err := u.resolveUserID(ctx, event.Provider, event.AuthorLogin)
+		if err != nil {
+			return nil, err
+		}
+		_, err = u.prUsecase.CreatePR(ctx, dtos.CreatePRRequest{
+			PullRequestID:   event.PullRequestID,
+			PullRequestName: event.PullRequestName,
+			AuthorID:        authorID,
+		})
+		if hasErrorCode(err, domain.ErrPRExistsCode) {
+			return &dtos.IntegrationResult{Status: IntegrationStatusUnchanged, PullRequestID: event.PullRequestID}, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		return &dtos.IntegrationResult{Status: IntegrationStatusCreated, PullRequestID: event.PullRequestID}, nil
+	case dtos.VCSActionMerge:
+		_, err := u.prUsecase.MergePR(ctx, dtos.MergePRRequest{PullRequestID: event.PullRequestID})
+		if hasErrorCode(err, domain.ErrNotFoundCode) {
+			return &dtos.IntegrationResult{
+				Status:        IntegrationStatusIgnored,
+				PullRequestID: event.PullRequestID,
+				Reason:        "pull request is not tracked",
+			}, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		return &dtos.IntegrationResult{Status: IntegrationStatusMerged, PullRequestID: event.PullRequestID}, nil
+	default:
+		return nil, fmt.Errorf("unsupported action %q", event.Action)
+	}
+}
+
+func (u *IntegrationUsecase) resolveUserID(ctx context.Context, provider, login string) (string, error) {
+	var userID string
+	err := u.trm.Do(ctx, func(ctx context.Context) error {
+		identity, err := u.identityRepo.GetIdentity(ctx, provider, strings.ToLower(login))
+		if err != nil {
+			return err
+		}
+		if identity == nil {
+			return domain.NewDomainError(domain.ErrUnknownIdentityCode).
+				WithCause(fmt.Errorf("%s login %q", provider, login))
+		}
+		userID = identity.UserID
+		return nil
+	}, domain.WithName("IntegrationUsecase.resolveUserID"), domain.WithReadOnly())
+	return userID, err
+}
+
+func hasErrorCode(err error, code domain.ErrCode) bool {
+	var domainErr domain.DomainError
+	return errors.As(err, &domainErr) && domainErr.Code() == string(code)
+}
+
+func toIdentityDTO(identity *domain.ExternalIdentity) dtos.Identity {
+	return dtos.Identity{
+		Provider:  identity.Provider,
+		Login:     identity.Login,
+		UserID:    identity.UserID,
+		CreatedAt: identity.CreatedAt.Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,128 @@
+package usecases
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"pullrequests/internal/domain"
+	"time"
+)
+
+// jobLease is how long a claimed job stays invisible to other workers; it
+// must exceed the time a job may spend calling an external service.
+const jobLease = 5 * time.Minute
+
+// jobQueue queues jobs of one kind from outbox sinks and runs them outside
+// the dispatcher transaction, the way webhook deliveries are sent.
+type jobQueue struct {
+	jobRepo   domain.JobRepo
+	trm       domain.TransactionManager
+	kind      domain.JobKind
+	retry     RetryPolicy
+	batchSize int
+}
+
+func newJobQueue(jobRepo domain.JobRepo, trm domain.TransactionManager, kind domain.JobKind, retry RetryPolicy) jobQueue {
+	return jobQueue{
+		jobRepo:   jobRepo,
+		trm:       trm,
+		kind:      kind,
+		retry:     retry,
+		batchSize: 50,
+	}
+}
+
+// enqueue stores a job with the JSON encoded payload through the transaction
+// bound to ctx.
+func (q jobQueue) enqueue(ctx context.Context, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	jobID, err := randomString(12)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	return q.jobRepo.AddJob(ctx, &domain.Job{
+		JobID:         "job_" + jobID,
+		Kind:          q.kind,
+		Payload:       data,
+		Status:        domain.JobStatusPending,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+	})
+}
+
+// permanentError marks a job failure that retrying cannot fix.
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e *permanentError) Unwrap() error {
+	return e.err
+}
+
+// runDue claims due jobs, runs them without a transaction and records the
+// outcome. It returns the number of jobs run.
+func (q jobQueue) runDue(ctx context.Context, run func(ctx context.Context, job *domain.Job) error) (int, error) {
+	var claimed []domain.Job
+	now := time.Now()
+	err := q.trm.Do(ctx, func(ctx context.Context) error {
+		due, err := q.jobRepo.GetDueJobs(ctx, q.kind, now, q.batchSize)
+		if err != nil {
+			return err
+		}
+		for i := range due {
+			due[i].NextAttemptAt = now.Add(jobLease)
+			if err := q.jobRepo.UpdateJob(ctx, &due[i]); err != nil {
+				return err
+			}
+		}
+		claimed = due
+		return nil
+	}, domain.WithName("jobQueue.runDue"))
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range claimed {
+		if err := q.finish(ctx, &claimed[i], run(ctx, &claimed[i])); err != nil {
+			return i, err
+		}
+	}
+	return len(claimed), nil
+}
+
+func (q jobQueue) finish(ctx context.Context, job *domain.Job, runErr error) error {
+	now := time.Now()
+	job.Attempts++
+
+	var permanent *permanentError
+	switch {
+	case runErr == nil:
+		job.Status = domain.JobStatusDone
+		job.FinishedAt = &now
+		job.LastError = ""
+	case errors.As(runErr, &permanent) || job.Attempts >= q.retry.MaxAttempts:
+		job.Status = domain.JobStatusFailed
+		job.FinishedAt = &now
+		job.LastError = runErr.Error()
+	default:
+		job.LastError = runErr.Error()
+		delay := q.retry.Delay(job.Attempts)
+		var unavailable *domain.VCSUnavailableError
+		if errors.As(runErr, &unavailable) && unavailable.RetryAfter > delay {
+			delay = unavailable.RetryAfter
+		}
+		job.NextAttemptAt = now.Add(delay)
+	}
+
+	return q.trm.Do(ctx, func(ctx context.Context) error {
+		return q.jobRepo.UpdateJob(ctx, job)
+	}, domain.WithName("jobQueue.finish"))
+}
@@ -0,0 +1,147 @@
+package usecases
+
+import (
+	"context"
+	"pullrequests/internal/domain"
+	"pullrequests/internal/dtos"
+	"time"
+)
+
+type NotificationUsecase struct {
+	notificationRepo domain.NotificationRepo
+	userRepo         domain.UserRepo
+	trm              domain.TransactionManager
+}
+
+func NewNotificationUsecase(
+	notificationRepo domain.NotificationRepo,
+	userRepo domain.UserRepo,
+	trm domain.TransactionManager) *NotificationUsecase {
+	return &NotificationUsecase{
+		notificationRepo: notificationRepo,
+		userRepo:         userRepo,
+		trm:              trm,
+	}
+}
+
+// GetPreferences is allowed to the user and to admins: preferences hold the
+// user's email and chat channel.
+func (u *NotificationUsecase) GetPreferences(ctx context.Context, userID string) (*dtos.NotificationPreferencesResponse, error) {
+	var preferences *domain.NotificationPreferences
+	err := u.trm.Do(ctx, func(ctx context.Context) error {
+		if !canManagePreferences(ctx, userID) {
+			return domain.NewDomainError(domain.ErrForbiddenCode)
+		}
+
+		user, err := u.userRepo.GetUserByID(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if user == nil {
+			return domain.NewDomainError(domain.ErrNotFoundCode)
+		}
+		preferences, err = loadPreferences(ctx, u.notificationRepo, userID)
+		return err
+	}, domain.WithName("NotificationUsecase.GetPreferences"))
+	if err != nil {
+		return nil, err
+	}
+	return &dtos.NotificationPreferencesResponse{Preferences: toPreferencesDTO(preferences)}, nil
+}
+
+// SetPreferences has the same rule as GetPreferences: team leads must not
+// redirect a member's notifications to an email or channel they control.
+func (u *NotificationUsecase) SetPreferences(ctx context.Context, req dtos.SetNotificationPreferencesRequest) (*dtos.NotificationPreferencesResponse, error) {
+	var preferences *domain.NotificationPreferences
+	err := u.trm.Do(ctx, func(ctx context.Context) error {
+		if !canManagePreferences(ctx, req.UserID) {
+			return domain.NewDomainError(domain.ErrForbiddenCode)
+		}
+
+		user, err := u.userRepo.GetUserByID(ctx, req.UserID)
+		if err != nil {
+			return err
+		}
+		if user == nil {
+			return domain.NewDomainError(domain.ErrNotFoundCode)
+		}
+
+		preferences, err = loadPreferences(ctx, u.notificationRepo, user.UserID)
+		if err != nil {
+			return err
+		}
+		applyPreferences(preferences, req)
+		preferences.UpdatedAt = time.Now()
+		return u.notificationRepo.SetPreferences(ctx, preferences)
+	}, domain.WithName("NotificationUsecase.SetPreferences"))
+	if err != nil {
+		return nil, err
+	}
+	return &dtos.NotificationPreferencesResponse{Preferences: toPreferencesDTO(preferences)}, nil
+}
+
+func canManagePreferences(ctx context.Context, userID string) bool {
+	actor := domain.ActorFromContext(ctx)
+	return actor == nil || actor.UserID == userID || actor.Role == domain.APIRoleAdmin
+}
+
+func applyPreferences(preferences *domain.NotificationPreferences, req dtos.SetNotificationPreferencesRequest) {
+	if req.ChatEnabled != nil {
+		preferences.ChatEnabled = *req.ChatEnabled
+	}
+	if req.ChatChannel != nil {
+		preferences.ChatChannel = *req.ChatChannel
+	}
+	if req.ChatEvents != nil {
+		preferences.ChatEvents = make([]domain.EventType, 0, len(req.ChatEvents))
+		for _, event := range req.ChatEvents {
+			preferences.ChatEvents = append(preferences.ChatEvents, domain.EventType(event))
+		}
+	}
+	if req.Email != nil {
+		preferences.Email = *req.Email
+	}
+	if req.DigestEnabled != nil {
+		preferences.DigestEnabled = *req.DigestEnabled
+	}
+	if req.DigestHour != nil {
+		preferences.DigestHour = *req.DigestHour
+	}
+	if req.Timezone != nil {
+		preferences.Timezone = *req.Timezone
+	}
+}
+
+func loadPreferences(ctx context.Context, notificationRepo domain.NotificationRepo, userID string) (*domain.NotificationPreferences, error) {
+	preferences, err := notificationRepo.GetPreferences(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if preferences == nil {
+		return domain.DefaultNotificationPreferences(userID), nil
+	}
+	return preferences, nil
+}
+
+func toPreferencesDTO(preferences *domain.NotificationPreferences) dtos.NotificationPreferences {
+	out := dtos.NotificationPreferences{
+		UserID:        preferences.UserID,
+		ChatEnabled:   preferences.ChatEnabled,
+		ChatChannel:   preferences.ChatChannel,
+		ChatEvents:    []string{},
+		Email:         preferences.Email,
+		DigestEnabled: preferences.DigestEnabled,
+		DigestHour:    preferences.DigestHour,
+		Timezone:      preferences.Timezone,
+	}
+	for _, event := range preferences.ChatEvents {
+		out.ChatEvents = append(out.ChatEvents, string(event))
+	}
+	if preferences.DigestSentAt != nil {
+		out.DigestSentAt = preferences.DigestSentAt.Format(time.RFC3339)
+	}
+	if !preferences.UpdatedAt.IsZero() {
+		out.UpdatedAt = preferences.UpdatedAt.Format(time.RFC3339)
+	}
+	return out
+}
@@ -0,0 +1,66 @@
+package usecases_test
+
+import (
+	"context"
+	"errors"
+	"pullrequests/internal/adapters/memory"
+	"pullrequests/internal/domain"
+	"pullrequests/internal/dtos"
+	"pullrequests/internal/usecases"
+	"testing"
+)
+
+func TestSetPreferencesAccess(t *testing.T) {
+	store := memory.NewStore()
+	trm := memory.NewTransactionManager(store)
+	teams := usecases.NewTeamUsecase(memory.NewTeamRepo(store), memory.NewUserRepo(store), trm)
+	notifications := usecases.NewNotificationUsecase(memory.NewNotificationRepo(store), memory.NewUserRepo(store), trm)
+
+	_, err := teams.AddTeam(context.Background(), dtos.TeamRequest{Team: dtos.Team{
+		TeamName: "backend",
+		Members: []dtos.TeamMember{
+			{UserID: "u1", Username: "alice", IsActive: true, Role: string(domain.TeamRoleLead)},
+			{UserID: "u2", Username: "bob", IsActive: true},
+		},
+	}})
+	if err != nil {
+		t.Fatalf("add team: %v", err)
+	}
+
+	tests := []struct {
+		name          string
+		actor         *domain.Actor
+		wantForbidden bool
+	}{
+		{name: "self", actor: &domain.Actor{UserID: "u2", Role: domain.APIRoleUser}},
+		{name: "admin", actor: &domain.Actor{Role: domain.APIRoleAdmin}},
+		{name: "team lead member", actor: &domain.Actor{UserID: "u1", Role: domain.APIRoleUser}, wantForbidden: true},
+		{name: "team lead token", actor: &domain.Actor{UserID: "u1", Role: domain.APIRoleTeamLead}, wantForbidden: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := domain.WithActor(context.Background(), test.actor)
+			address, channel := test.name+"@example.com", "@"+test.name
+			_, err := notifications.SetPreferences(ctx, dtos.SetNotificationPreferencesRequest{
+				UserID:      "u2",
+				Email:       &address,
+				ChatChannel: &channel,
+			})
+
+			var domainErr domain.DomainError
+			forbidden := errors.As(err, &domainErr) && domainErr.Code() == string(domain.ErrForbiddenCode)
+			if forbidden != test.wantForbidden || (err != nil && !forbidden) {
+				t.Fatalf("SetPreferences: got %v, want forbidden %v", err, test.wantForbidden)
+			}
+
+			stored, err := notifications.GetPreferences(context.Background(), "u2")
+			if err != nil {
+				t.Fatalf("get preferences: %v", err)
+			}
+			changed := stored.Preferences.Email == address && stored.Preferences.ChatChannel == channel
+			if changed == test.wantForbidden {
+				t.Errorf("got email %q and channel %q after the request", stored.Preferences.Email, stored.Preferences.ChatChannel)
+			}
+		})
+	}
+}
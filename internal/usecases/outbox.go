@@ -0,0 +1,189 @@
+package usecases
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"pullrequests/internal/domain"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// OutboxPublisher stores events in the outbox table of the caller's
+// transaction instead of handing them to sinks directly.
+type OutboxPublisher struct {
+	outboxRepo domain.OutboxRepo
+}
+
+func NewOutboxPublisher(outboxRepo domain.OutboxRepo) *OutboxPublisher {
+	return &OutboxPublisher{outboxRepo: outboxRepo}
+}
+
+func (p *OutboxPublisher) Publish(ctx context.Context, event domain.Event) error {
+	payload, err := json.Marshal(event.Data)
+	if err != nil {
+		return err
+	}
+	return p.outboxRepo.Add(ctx, &domain.OutboxMessage{
+		EventID:       event.EventID,
+		EventType:     event.Type,
+		Payload:       payload,
+		OccurredAt:    event.OccurredAt,
+		Status:        domain.OutboxStatusPending,
+		NextAttemptAt: event.OccurredAt,
+	})
+}
+
+type OutboxDispatcher struct {
+	outboxRepo domain.OutboxRepo
+	trm        domain.TransactionManager
+	sinks      []domain.EventSink
+	retry      RetryPolicy
+	batchSize  int
+}
+
+func NewOutboxDispatcher(
+	outboxRepo domain.OutboxRepo,
+	trm domain.TransactionManager,
+	retry RetryPolicy,
+	sinks ...domain.EventSink) *OutboxDispatcher {
+	return &OutboxDispatcher{
+		outboxRepo: outboxRepo,
+		trm:        trm,
+		sinks:      sinks,
+		retry:      retry,
+		batchSize:  100,
+	}
+}
+
+// Dispatch hands pending messages to the sinks in sequence order, one
+// transaction per message, so sink writes such as queued webhook deliveries
+// commit together with the delivery state. The message stays locked until
+// that state is stored. It returns the number of messages attempted.
+func (d *OutboxDispatcher) Dispatch(ctx context.Context) (int, error) {
+	attempted := 0
+	for attempted < d.batchSize {
+		found := false
+		now := time.Now()
+
+		err := d.trm.Do(ctx, func(ctx context.Context) error {
+			pending, err := d.outboxRepo.GetPending(ctx, now, 1)
+			found = len(pending) > 0
+			if err != nil || !found {
+				return err
+			}
+			message := &pending[0]
+			d.deliver(ctx, message, now)
+			return d.outboxRepo.Update(ctx, message)
+		}, domain.WithName("OutboxDispatcher.Dispatch"))
+		if err != nil || !found {
+			return attempted, err
+		}
+		attempted++
+	}
+	return attempted, nil
+}
+
+// deliver hands message to the sinks that have not handled it yet. Each sink
+// runs in a savepoint, so a failing sink rolls back only its own writes, and
+// only the failed sinks are retried; the message fails once they have used
+// up the retry policy.
+func (d *OutboxDispatcher) deliver(ctx context.Context, message *domain.OutboxMessage, now time.Time) {
+	event := domain.Event{
+		EventID:    message.EventID,
+		Type:       message.EventType,
+		OccurredAt: message.OccurredAt,
+		Data:       json.RawMessage(message.Payload),
+	}
+	var failures []string
+	for _, sink := range d.sinks {
+		if slices.Contains(message.DeliveredSinks, sink.Name()) {
+			continue
+		}
+		err := d.trm.Do(ctx, func(ctx context.Context) error {
+			return sink.Handle(ctx, event)
+		}, domain.WithName("OutboxDispatcher.deliver"), domain.WithSavepoint())
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("sink %s: %v", sink.Name(), err))
+			continue
+		}
+		message.DeliveredSinks = append(message.DeliveredSinks, sink.Name())
+	}
+
+	message.Attempts++
+	if len(failures) == 0 {
+		message.Status = domain.OutboxStatusProcessed
+		message.LastError = ""
+		message.ProcessedAt = &now
+		return
+	}
+	message.LastError = strings.Join(failures, "; ")
+	if message.Attempts >= d.retry.MaxAttempts {
+		message.Status = domain.OutboxStatusFailed
+	} else {
+		message.NextAttemptAt = now.Add(d.retry.Delay(message.Attempts))
+	}
+}
+
+// LogSink writes every dispatched event to the default logger.
+type LogSink struct{}
+
+func (LogSink) Name() string {
+	return "log"
+}
+
+func (LogSink) Handle(ctx context.Context, event domain.Event) error {
+	slog.InfoContext(ctx, "event", "event_id", event.EventID, "type", event.Type, "data", event.Data)
+	return nil
+}
+
+type EventHandler func(ctx context.Context, event domain.Event) error
+
+type subscriber struct {
+	handler EventHandler
+	types   []domain.EventType
+}
+
+// EventBus fans dispatched events out to in-process subscribers. Handlers run
+// inside the dispatcher transaction, so usecase calls they make join it. The
+// bus is one sink: when a handler fails, the writes of every handler are
+// rolled back and all of them see the event again.
+type EventBus struct {
+	mu          sync.RWMutex
+	subscribers []subscriber
+}
+
+func NewEventBus() *EventBus {
+	return &EventBus{}
+}
+
+// Subscribe registers handler for the given event types, or for every event
+// when none are given.
+func (b *EventBus) Subscribe(handler EventHandler, types ...domain.EventType) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.subscribers = append(b.subscribers, subscriber{handler: handler, types: types})
+}
+
+func (b *EventBus) Name() string {
+	return "in-process"
+}
+
+func (b *EventBus) Handle(ctx context.Context, event domain.Event) error {
+	b.mu.RLock()
+	subscribers := slices.Clone(b.subscribers)
+	b.mu.RUnlock()
+
+	for _, subscriber := range subscribers {
+		if len(subscriber.types) > 0 && !slices.Contains(subscriber.types, event.Type) {
+			continue
+		}
+		if err := subscriber.handler(ctx, event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
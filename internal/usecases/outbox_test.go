@@ -0,0 +1,184 @@
+package usecases_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"pullrequests/internal/adapters/memory"
+	"pullrequests/internal/domain"
+	"pullrequests/internal/usecases"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// recordingSink adds a team per event it handles, so a test can tell which
+// of its writes were committed, and fails its first failures calls after
+// writing.
+type recordingSink struct {
+	name     string
+	teamRepo domain.TeamRepo
+	failures int
+	events   []string
+}
+
+func (s *recordingSink) Name() string {
+	return s.name
+}
+
+func (s *recordingSink) Handle(ctx context.Context, event domain.Event) error {
+	s.events = append(s.events, event.EventID)
+	if err := s.teamRepo.Add(ctx, &domain.Team{Name: fmt.Sprintf("%s-%s-%d", s.name, event.EventID, len(s.events))}); err != nil {
+		return err
+	}
+	if s.failures > 0 {
+		s.failures--
+		return errors.New("unavailable")
+	}
+	return nil
+}
+
+type outboxEnv struct {
+	outbox   *memory.OutboxRepo
+	teams    *memory.TeamRepo
+	stable   *recordingSink
+	flaky    *recordingSink
+	dispatch *usecases.OutboxDispatcher
+}
+
+// newOutboxEnv dispatches to a stable sink and one that fails flakyFailures
+// times. Retries wait an hour, so each Dispatch makes one attempt.
+func newOutboxEnv(maxAttempts, flakyFailures int) *outboxEnv {
+	store := memory.NewStore()
+	env := &outboxEnv{
+		outbox: memory.NewOutboxRepo(store),
+		teams:  memory.NewTeamRepo(store),
+	}
+	env.stable = &recordingSink{name: "stable", teamRepo: env.teams}
+	env.flaky = &recordingSink{name: "flaky", teamRepo: env.teams, failures: flakyFailures}
+	env.dispatch = usecases.NewOutboxDispatcher(env.outbox, memory.NewTransactionManager(store),
+		usecases.RetryPolicy{MaxAttempts: maxAttempts, BaseDelay: time.Hour, MaxDelay: time.Hour}, env.stable, env.flaky)
+	return env
+}
+
+func (env *outboxEnv) publish(t *testing.T, eventIDs ...string) {
+	t.Helper()
+	publisher := usecases.NewOutboxPublisher(env.outbox)
+	occurredAt := time.Now().Add(-time.Minute)
+	for _, eventID := range eventIDs {
+		err := publisher.Publish(context.Background(), domain.Event{EventID: eventID, Type: domain.EventPRCreated, OccurredAt: occurredAt})
+		if err != nil {
+			t.Fatalf("publish %s: %v", eventID, err)
+		}
+	}
+}
+
+func (env *outboxEnv) run(t *testing.T, want int) {
+	t.Helper()
+	attempted, err := env.dispatch.Dispatch(context.Background())
+	if err != nil || attempted != want {
+		t.Fatalf("Dispatch attempted %d (err %v), want %d", attempted, err, want)
+	}
+}
+
+// pending returns the messages left to dispatch, scheduled retries included,
+// and makes them due.
+func (env *outboxEnv) pending(t *testing.T) []domain.OutboxMessage {
+	t.Helper()
+	ctx := context.Background()
+	messages, err := env.outbox.GetPending(ctx, time.Now().Add(2*time.Hour), 100)
+	if err != nil {
+		t.Fatalf("get pending: %v", err)
+	}
+	for _, message := range messages {
+		message.NextAttemptAt = time.Now().Add(-time.Second)
+		if err := env.outbox.Update(ctx, &message); err != nil {
+			t.Fatalf("update %s: %v", message.EventID, err)
+		}
+	}
+	return messages
+}
+
+func (env *outboxEnv) teamExists(t *testing.T, teamName string) bool {
+	t.Helper()
+	team, err := env.teams.GetTeamByTeamName(context.Background(), teamName)
+	if err != nil {
+		t.Fatalf("get team: %v", err)
+	}
+	return team != nil
+}
+
+func TestOutboxRetriesOnlyFailedSink(t *testing.T) {
+	env := newOutboxEnv(3, 2)
+	env.publish(t, "e1")
+
+	env.run(t, 1)
+	pending := env.pending(t)
+	if len(pending) != 1 {
+		t.Fatalf("got %d pending messages, want e1 scheduled for a retry", len(pending))
+	}
+	message := pending[0]
+	if message.Attempts != 1 || !slices.Equal(message.DeliveredSinks, []string{"stable"}) ||
+		!strings.Contains(message.LastError, "sink flaky: unavailable") {
+		t.Errorf("got message %+v, want the stable sink delivered and the flaky one failed", message)
+	}
+	// The failing sink's write is rolled back, the other sink's is not.
+	if !env.teamExists(t, "stable-e1-1") || env.teamExists(t, "flaky-e1-1") {
+		t.Error("got the writes of the failed sink committed or of the stable sink lost")
+	}
+
+	env.run(t, 1)
+	env.pending(t)
+	env.run(t, 1)
+	if pending := env.pending(t); len(pending) != 0 {
+		t.Fatalf("got %d pending messages after the flaky sink succeeded, want 0", len(pending))
+	}
+	if !slices.Equal(env.stable.events, []string{"e1"}) {
+		t.Errorf("stable sink handled %v, want e1 once", env.stable.events)
+	}
+	if !slices.Equal(env.flaky.events, []string{"e1", "e1", "e1"}) {
+		t.Errorf("flaky sink handled %v, want e1 three times", env.flaky.events)
+	}
+	if !env.teamExists(t, "flaky-e1-3") || env.teamExists(t, "flaky-e1-2") {
+		t.Error("got the writes of the flaky sink's failed attempts committed")
+	}
+}
+
+func TestOutboxGivesUpOnFailedSinkOnly(t *testing.T) {
+	env := newOutboxEnv(2, 100)
+	env.publish(t, "e1")
+
+	env.run(t, 1)
+	env.pending(t)
+	env.run(t, 1)
+	if pending := env.pending(t); len(pending) != 0 {
+		t.Fatalf("got %d pending messages after the last attempt, want 0", len(pending))
+	}
+	env.run(t, 0)
+	if !slices.Equal(env.stable.events, []string{"e1"}) || len(env.flaky.events) != 2 {
+		t.Errorf("sinks handled %v and %v, want e1 once by the stable sink and twice by the flaky one",
+			env.stable.events, env.flaky.events)
+	}
+}
+
+func TestOutboxDispatchesInPublishOrder(t *testing.T) {
+	env := newOutboxEnv(3, 1)
+	// Equal timestamps, and event IDs out of publish order.
+	env.publish(t, "e3", "e1", "e2")
+
+	env.run(t, 3)
+	if !slices.Equal(env.stable.events, []string{"e3", "e1", "e2"}) {
+		t.Errorf("stable sink handled %v, want [e3 e1 e2]", env.stable.events)
+	}
+	// A retried message keeps its place ahead of later ones.
+	pending := env.pending(t)
+	if len(pending) != 1 || pending[0].EventID != "e3" {
+		t.Fatalf("got pending %v, want e3 to retry", pending)
+	}
+	env.publish(t, "e0")
+	env.run(t, 2)
+	if !slices.Equal(env.flaky.events, []string{"e3", "e1", "e2", "e3", "e0"}) {
+		t.Errorf("flaky sink handled %v, want the retry of e3 before e0", env.flaky.events)
+	}
+}
@@ -4,22 +4,35 @@ import (
 	"context"
 	"pullrequests/internal/domain"
 	"pullrequests/internal/dtos"
+	"slices"
 	"time"
 )
 
 type PRUsecase struct {
 	pullrequestRepo domain.PullRequestRepo
 	userRepo        domain.UserRepo
+	historyRepo     domain.ReviewHistoryRepo
+	strategy        domain.ReviewerStrategy
+	publisher       domain.EventPublisher
+	metrics         domain.AssignmentMetrics
 	trm             domain.TransactionManager
 }
 
 func NewPRUsecase(
 	pullrequestRepo domain.PullRequestRepo,
 	userRepo domain.UserRepo,
+	historyRepo domain.ReviewHistoryRepo,
+	strategy domain.ReviewerStrategy,
+	publisher domain.EventPublisher,
+	metrics domain.AssignmentMetrics,
 	trm domain.TransactionManager) *PRUsecase {
 	return &PRUsecase{
 		pullrequestRepo: pullrequestRepo,
 		userRepo:        userRepo,
+		historyRepo:     historyRepo,
+		strategy:        strategy,
+		publisher:       publisher,
+		metrics:         metrics,
 		trm:             trm,
 	}
 }
@@ -61,34 +74,70 @@ func (u *PRUsecase) CreatePR(ctx context.Context, req dtos.CreatePRRequest) (*dt
 			return err
 		}
 
-		reviewersAssigned := 0
-		for _, member := range activeUsers {
-			if member.UserID != author.UserID && reviewersAssigned < 2 {
-				if err := u.pullrequestRepo.AddReviewer(ctx, pullrequest.PullRequestID, member.UserID); err != nil {
-					return err
-				}
-				reviewers = append(reviewers, member.UserID)
-				reviewersAssigned++
+		selected, escalated, err := u.selectInitialReviewers(ctx, activeUsers, author.UserID)
+		if err != nil {
+			return err
+		}
+
+		reviewers = make([]string, 0, len(selected))
+		for _, member := range selected {
+			if err := u.addReviewer(ctx, pullrequest, member.UserID, escalated); err != nil {
+				return err
+			}
+			reviewers = append(reviewers, member.UserID)
+		}
+
+		// Consumers must see the pull request before its reviewers.
+		if err := publishEvent(ctx, u.publisher, domain.EventPRCreated, toPullRequestDTO(pullrequest, reviewers)); err != nil {
+			return err
+		}
+		for _, reviewerID := range reviewers {
+			if err := u.publishReviewerAssigned(ctx, pullrequest, reviewerID); err != nil {
+				return err
 			}
 		}
 		return nil
-	})
+	}, domain.WithName("PRUsecase.CreatePR"), domain.WithIsolation(domain.IsolationSerializable))
 	if err != nil {
 		return nil, err
 	}
-	response := &dtos.PRResponse{
-		PR: dtos.PullRequest{
-			PullRequestID:     pullrequest.PullRequestID,
-			PullRequestName:   pullrequest.PullRequestName,
-			AuthorID:          pullrequest.AuthorID,
-			Status:            string(pullrequest.Status),
-			AssignedReviewers: reviewers,
-			CreatedAt:         pullrequest.CreatedAt.Format(time.RFC3339),
-		},
-	}
+	return &dtos.PRResponse{PR: toPullRequestDTO(pullrequest, reviewers)}, nil
+}
+
+func (u *PRUsecase) PreviewReviewers(ctx context.Context, req dtos.PreviewReviewersRequest) (*dtos.PreviewReviewersResponse, error) {
+	var response *dtos.PreviewReviewersResponse
+
+	err := u.trm.Do(ctx, func(ctx context.Context) error {
+		author, err := u.userRepo.GetUserByID(ctx, req.AuthorID)
+		if err != nil {
+			return err
+		}
+		if author == nil {
+			return domain.NewDomainError(domain.ErrNotFoundCode)
+		}
 
-	if pullrequest.MergedAt != nil {
-		response.PR.MergedAt = pullrequest.MergedAt.Format(time.RFC3339)
+		activeUsers, err := u.userRepo.GetActiveUsersByTeamName(ctx, author.TeamName)
+		if err != nil {
+			return err
+		}
+
+		selected, _, err := u.selectInitialReviewers(ctx, activeUsers, author.UserID)
+		if err != nil {
+			return err
+		}
+
+		response = &dtos.PreviewReviewersResponse{
+			AuthorID:          author.UserID,
+			TeamName:          author.TeamName,
+			AssignedReviewers: make([]string, 0, len(selected)),
+		}
+		for _, member := range selected {
+			response.AssignedReviewers = append(response.AssignedReviewers, member.UserID)
+		}
+		return nil
+	}, domain.WithName("PRUsecase.PreviewReviewers"), domain.WithReadOnly())
+	if err != nil {
+		return nil, err
 	}
 	return response, nil
 }
@@ -116,6 +165,14 @@ func (u *PRUsecase) MergePR(ctx context.Context, req dtos.MergePRRequest) (*dtos
 			reviewers = append(reviewers, reviewerEntity.UserID)
 		}
 
+		if err := u.authorizeMerge(ctx, pullrequest, reviewers); err != nil {
+			return err
+		}
+
+		if req.ExpectedVersion != nil && *req.ExpectedVersion != existingPR.Version {
+			return domain.NewDomainError(domain.ErrPreconditionCode)
+		}
+
 		if existingPR.Status == domain.PRStatusMerged {
 			return nil
 		}
@@ -127,34 +184,32 @@ func (u *PRUsecase) MergePR(ctx context.Context, req dtos.MergePRRequest) (*dtos
 		if err := u.pullrequestRepo.UpdatePullRequest(ctx, pullrequest); err != nil {
 			return err
 		}
-		return nil
-	})
+		return publishEvent(ctx, u.publisher, domain.EventPRMerged, toPullRequestDTO(pullrequest, reviewers))
+	}, domain.WithName("PRUsecase.MergePR"))
 
 	if err != nil {
 		return nil, err
 	}
+	return &dtos.PRResponse{PR: toPullRequestDTO(pullrequest, reviewers)}, nil
+}
 
-	response := &dtos.PRResponse{
-		PR: dtos.PullRequest{
-			PullRequestID:     pullrequest.PullRequestID,
-			PullRequestName:   pullrequest.PullRequestName,
-			AuthorID:          pullrequest.AuthorID,
-			Status:            string(pullrequest.Status),
-			AssignedReviewers: reviewers,
-			CreatedAt:         pullrequest.CreatedAt.Format(time.RFC3339),
-		},
-	}
-
-	if pullrequest.MergedAt != nil {
-		response.PR.MergedAt = pullrequest.MergedAt.Format(time.RFC3339)
+func (u *PRUsecase) ReassignReviewer(ctx context.Context, req dtos.ReassignPRRequest) (*dtos.ReassignResponse, error) {
+	response, noCandidateTeam, err := u.reassignReviewer(ctx, req)
+	if hasErrorCode(err, domain.ErrNoCandidateCode) {
+		u.metrics.IncNoCandidate(noCandidateTeam)
 	}
-	return response, nil
+	return response, err
 }
 
-func (u *PRUsecase) ReassignReviewer(ctx context.Context, req dtos.ReassignPRRequest) (*dtos.ReassignResponse, error) {
+// reassignReviewer returns the author's team when no candidate was found.
+// Callers record the NO_CANDIDATE metric once their own transaction is over:
+// when this runs nested, the outer transaction may still be retried.
+func (u *PRUsecase) reassignReviewer(ctx context.Context, req dtos.ReassignPRRequest) (*dtos.ReassignResponse, string, error) {
 	var pullrequest *domain.PullRequest
 	var reviewers []string
 	var newReviewerID string
+	var escalated bool
+	var teamName string
 
 	err := u.trm.Do(ctx, func(ctx context.Context) error {
 		existingPR, err := u.pullrequestRepo.GetPullRequestByID(ctx, req.PullRequestID)
@@ -164,6 +219,28 @@ func (u *PRUsecase) ReassignReviewer(ctx context.Context, req dtos.ReassignPRReq
 		if existingPR == nil {
 			return domain.NewDomainError(domain.ErrNotFoundCode)
 		}
+
+		author, err := u.userRepo.GetUserByID(ctx, existingPR.AuthorID)
+		if err != nil {
+			return err
+		}
+		if author == nil {
+			return domain.NewDomainError(domain.ErrNotFoundCode)
+		}
+
+		if actor := domain.ActorFromContext(ctx); actor != nil && actor.UserID != req.OldUserID {
+			allowed, err := isTeamLead(ctx, u.userRepo, actor, author.TeamName)
+			if err != nil {
+				return err
+			}
+			if !allowed {
+				return domain.NewDomainError(domain.ErrForbiddenCode)
+			}
+		}
+
+		if req.ExpectedVersion != nil && *req.ExpectedVersion != existingPR.Version {
+			return domain.NewDomainError(domain.ErrPreconditionCode)
+		}
 		pullrequest = existingPR
 		if pullrequest.Status == domain.PRStatusMerged {
 			return domain.NewDomainError(domain.ErrPRMergedCode)
@@ -186,38 +263,34 @@ func (u *PRUsecase) ReassignReviewer(ctx context.Context, req dtos.ReassignPRReq
 			return domain.NewDomainError(domain.ErrNotAssignedCode)
 		}
 
-		author, err := u.userRepo.GetUserByID(ctx, pullrequest.AuthorID)
+		activeUsers, err := u.userRepo.GetActiveUsersByTeamName(ctx, author.TeamName)
 		if err != nil {
 			return err
 		}
-		if author == nil {
-			return domain.NewDomainError(domain.ErrNotFoundCode)
-		}
 
-		activeUsers, err := u.userRepo.GetActiveUsersByTeamName(ctx, author.TeamName)
+		candidate, err := u.selectReplacement(ctx, activeUsers, domain.TeamRoleMember, author.UserID, req.OldUserID, reviewerEntities, req.ExcludeUserIDs)
 		if err != nil {
 			return err
 		}
-
-		var candidate *domain.User
-		for _, m := range activeUsers {
-			if m.UserID != author.UserID &&
-				m.UserID != req.OldUserID &&
-				!u.isUserAssigned(m.UserID, reviewerEntities) {
-				candidate = &m
-				break
+		escalated = false
+		if candidate == nil && req.AllowEscalation {
+			candidate, err = u.selectReplacement(ctx, activeUsers, domain.TeamRoleLead, author.UserID, req.OldUserID, reviewerEntities, req.ExcludeUserIDs)
+			if err != nil {
+				return err
 			}
+			escalated = candidate != nil
 		}
 
 		if candidate == nil {
+			teamName = author.TeamName
 			return domain.NewDomainError(domain.ErrNoCandidateCode)
 		}
 
-		if err := u.pullrequestRepo.RemoveReviewer(ctx, req.PullRequestID, req.OldUserID); err != nil {
+		if err := u.pullrequestRepo.RemoveReviewer(ctx, pullrequest, req.OldUserID); err != nil {
 			return err
 		}
 
-		if err := u.pullrequestRepo.AddReviewer(ctx, req.PullRequestID, candidate.UserID); err != nil {
+		if err := u.assignReviewer(ctx, pullrequest, candidate.UserID, escalated); err != nil {
 			return err
 		}
 
@@ -230,29 +303,22 @@ func (u *PRUsecase) ReassignReviewer(ctx context.Context, req dtos.ReassignPRReq
 			reviewers = append(reviewers, reviewerEntity.UserID)
 		}
 		newReviewerID = candidate.UserID
-		return nil
-	})
-
+		return publishEvent(ctx, u.publisher, domain.EventReviewerReassigned, dtos.ReviewerReassignedEvent{
+			PullRequestID: pullrequest.PullRequestID,
+			OldReviewerID: req.OldUserID,
+			NewReviewerID: newReviewerID,
+			Escalated:     escalated,
+		})
+	}, domain.WithName("PRUsecase.ReassignReviewer"), domain.WithIsolation(domain.IsolationSerializable))
 	if err != nil {
-		return nil, err
+		return nil, teamName, err
 	}
 
-	response := &dtos.ReassignResponse{
-		PR: dtos.PullRequest{
-			PullRequestID:     pullrequest.PullRequestID,
-			PullRequestName:   pullrequest.PullRequestName,
-			AuthorID:          pullrequest.AuthorID,
-			Status:            string(pullrequest.Status),
-			AssignedReviewers: reviewers,
-			CreatedAt:         pullrequest.CreatedAt.Format(time.RFC3339),
-		},
+	return &dtos.ReassignResponse{
+		PR:         toPullRequestDTO(pullrequest, reviewers),
 		ReplacedBy: newReviewerID,
-	}
-
-	if pullrequest.MergedAt != nil {
-		response.PR.MergedAt = pullrequest.MergedAt.Format(time.RFC3339)
-	}
-	return response, nil
+		Escalated:  escalated,
+	}, "", nil
 }
 
 func (u *PRUsecase) GetUserReviewPRs(ctx context.Context, userID string) (*dtos.UserReviewResponse, error) {
@@ -283,7 +349,7 @@ func (u *PRUsecase) GetUserReviewPRs(ctx context.Context, userID string) (*dtos.
 		}
 
 		return nil
-	})
+	}, domain.WithName("PRUsecase.GetUserReviewPRs"), domain.WithReadOnly())
 
 	if err != nil {
 		return nil, err
@@ -295,6 +361,118 @@ func (u *PRUsecase) GetUserReviewPRs(ctx context.Context, userID string) (*dtos.
 	return response, nil
 }
 
+func (u *PRUsecase) assignReviewer(ctx context.Context, pullrequest *domain.PullRequest, userID string, escalated bool) error {
+	if err := u.addReviewer(ctx, pullrequest, userID, escalated); err != nil {
+		return err
+	}
+	return u.publishReviewerAssigned(ctx, pullrequest, userID)
+}
+
+// addReviewer stores the assignment and its history record without
+// publishing reviewer.assigned.
+func (u *PRUsecase) addReviewer(ctx context.Context, pullrequest *domain.PullRequest, userID string, escalated bool) error {
+	if err := u.pullrequestRepo.AddReviewer(ctx, pullrequest, userID); err != nil {
+		return err
+	}
+	err := u.historyRepo.Add(ctx, &domain.ReviewAssignment{
+		PullRequestID: pullrequest.PullRequestID,
+		AuthorID:      pullrequest.AuthorID,
+		ReviewerID:    userID,
+		AssignedAt:    time.Now(),
+		Escalated:     escalated,
+	})
+	return err
+}
+
+func (u *PRUsecase) publishReviewerAssigned(ctx context.Context, pullrequest *domain.PullRequest, userID string) error {
+	return publishEvent(ctx, u.publisher, domain.EventReviewerAssigned, dtos.ReviewerAssignedEvent{
+		PullRequestID: pullrequest.PullRequestID,
+		ReviewerID:    userID,
+	})
+}
+
+// authorizeMerge allows the author, an assigned reviewer or a lead of the author's team.
+func (u *PRUsecase) authorizeMerge(ctx context.Context, pullrequest *domain.PullRequest, reviewers []string) error {
+	actor := domain.ActorFromContext(ctx)
+	if actor == nil {
+		return nil
+	}
+	if actor.UserID != "" && (actor.UserID == pullrequest.AuthorID || slices.Contains(reviewers, actor.UserID)) {
+		return nil
+	}
+
+	author, err := u.userRepo.GetUserByID(ctx, pullrequest.AuthorID)
+	if err != nil {
+		return err
+	}
+	if author == nil {
+		return domain.NewDomainError(domain.ErrNotFoundCode)
+	}
+
+	allowed, err := isTeamLead(ctx, u.userRepo, actor, author.TeamName)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return domain.NewDomainError(domain.ErrForbiddenCode)
+	}
+	return nil
+}
+
+// selectInitialReviewers picks up to two members of the author's team and
+// falls back to a lead, reported as escalated, only when no member is available.
+func (u *PRUsecase) selectInitialReviewers(ctx context.Context, activeUsers []domain.User, authorID string) ([]domain.User, bool, error) {
+	candidates := u.filterCandidates(activeUsers, domain.TeamRoleMember, authorID, "", nil, nil)
+	selected, err := u.strategy.SelectReviewers(ctx, authorID, candidates, 2)
+	if err != nil || len(selected) > 0 {
+		return selected, false, err
+	}
+
+	leads := u.filterCandidates(activeUsers, domain.TeamRoleLead, authorID, "", nil, nil)
+	selected, err = u.strategy.SelectReviewers(ctx, authorID, leads, 1)
+	if err != nil {
+		return nil, false, err
+	}
+	return selected, len(selected) > 0, nil
+}
+
+func (u *PRUsecase) selectReplacement(
+	ctx context.Context,
+	activeUsers []domain.User,
+	role domain.TeamRole,
+	authorID, oldUserID string,
+	reviewers []domain.PullRequestReviewer,
+	excluded []string) (*domain.User, error) {
+	candidates := u.filterCandidates(activeUsers, role, authorID, oldUserID, reviewers, excluded)
+	selected, err := u.strategy.SelectReviewers(ctx, authorID, candidates, 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(selected) == 0 {
+		return nil, nil
+	}
+	return &selected[0], nil
+}
+
+func (u *PRUsecase) filterCandidates(
+	activeUsers []domain.User,
+	role domain.TeamRole,
+	authorID, oldUserID string,
+	reviewers []domain.PullRequestReviewer,
+	excluded []string) []domain.User {
+	candidates := make([]domain.User, 0, len(activeUsers))
+	for _, m := range activeUsers {
+		if m.Role == role &&
+			m.UserID != authorID &&
+			m.UserID != oldUserID &&
+			!u.isUserAssigned(m.UserID, reviewers) &&
+			!slices.Contains(excluded, m.UserID) {
+			candidates = append(candidates, m)
+		}
+	}
+	return candidates
+}
+
 func (u *PRUsecase) isUserAssigned(userID string, reviewers []domain.PullRequestReviewer) bool {
 	for _, reviewer := range reviewers {
 		if reviewer.UserID == userID {
@@ -0,0 +1,143 @@
+package usecases
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"pullrequests/internal/domain"
+	"pullrequests/internal/dtos"
+)
+
+// ReviewerSyncUsecase mirrors reviewer assignments on the code host that owns
+// the pull request. Reviewers without a mapped identity are skipped.
+type ReviewerSyncUsecase struct {
+	identityRepo domain.IdentityRepo
+	queue        jobQueue
+	clients      []domain.VCSClient
+}
+
+func NewReviewerSyncUsecase(
+	identityRepo domain.IdentityRepo,
+	jobRepo domain.JobRepo,
+	trm domain.TransactionManager,
+	retry RetryPolicy,
+	clients ...domain.VCSClient) *ReviewerSyncUsecase {
+	return &ReviewerSyncUsecase{
+		identityRepo: identityRepo,
+		queue:        newJobQueue(jobRepo, trm, domain.JobReviewerSync, retry),
+		clients:      clients,
+	}
+}
+
+// reviewerSyncJob is the payload of a reviewer.sync job.
+type reviewerSyncJob struct {
+	PullRequestID string `json:"pull_request_id"`
+	AddedUserID   string `json:"added_user_id,omitempty"`
+	RemovedUserID string `json:"removed_user_id,omitempty"`
+}
+
+// HandleEvent is an EventHandler for reviewer.assigned and reviewer.reassigned.
+// It only queues a reviewer.sync job inside the dispatcher transaction;
+// SyncDue calls the code host.
+func (u *ReviewerSyncUsecase) HandleEvent(ctx context.Context, event domain.Event) error {
+	var job reviewerSyncJob
+	switch event.Type {
+	case domain.EventReviewerAssigned:
+		var data dtos.ReviewerAssignedEvent
+		if err := decodeEventData(event, &data); err != nil {
+			return err
+		}
+		job = reviewerSyncJob{PullRequestID: data.PullRequestID, AddedUserID: data.ReviewerID}
+	case domain.EventReviewerReassigned:
+		var data dtos.ReviewerReassignedEvent
+		if err := decodeEventData(event, &data); err != nil {
+			return err
+		}
+		// The new reviewer is requested by its own reviewer.assigned event.
+		job = reviewerSyncJob{PullRequestID: data.PullRequestID, RemovedUserID: data.OldReviewerID}
+	default:
+		return nil
+	}
+
+	if u.clientFor(job.PullRequestID) == nil {
+		return nil
+	}
+	return u.queue.enqueue(ctx, job)
+}
+
+// SyncDue runs due reviewer.sync jobs. Requests the host refuses are logged
+// and not retried. It returns the number of jobs run.
+func (u *ReviewerSyncUsecase) SyncDue(ctx context.Context) (int, error) {
+	return u.queue.runDue(ctx, func(ctx context.Context, job *domain.Job) error {
+		var payload reviewerSyncJob
+		if err := json.Unmarshal(job.Payload, &payload); err != nil {
+			return &permanentError{err: err}
+		}
+
+		err := u.sync(ctx, payload.PullRequestID, payload.AddedUserID, payload.RemovedUserID)
+		var rejected *domain.VCSRejectedError
+		if errors.As(err, &rejected) {
+			slog.WarnContext(ctx, "reviewer sync dropped", "job_id", job.JobID, "error", err)
+			return &permanentError{err: err}
+		}
+		return err
+	})
+}
+
+func (u *ReviewerSyncUsecase) sync(ctx context.Context, pullRequestID, addedUserID, removedUserID string) error {
+	client := u.clientFor(pullRequestID)
+	if client == nil {
+		return nil
+	}
+
+	if removedUserID != "" {
+		login, err := u.loginFor(ctx, client.Provider(), removedUserID)
+		if err != nil {
+			return err
+		}
+		if login != "" {
+			if err := client.RemoveReviewers(ctx, pullRequestID, []string{login}); err != nil {
+				return err
+			}
+		}
+	}
+
+	if addedUserID != "" {
+		login, err := u.loginFor(ctx, client.Provider(), addedUserID)
+		if err != nil {
+			return err
+		}
+		if login != "" {
+			return client.RequestReviewers(ctx, pullRequestID, []string{login})
+		}
+	}
+	return nil
+}
+
+func (u *ReviewerSyncUsecase) clientFor(pullRequestID string) domain.VCSClient {
+	for _, client := range u.clients {
+		if client.Supports(pullRequestID) {
+			return client
+		}
+	}
+	return nil
+}
+
+func (u *ReviewerSyncUsecase) loginFor(ctx context.Context, provider, userID string) (string, error) {
+	identity, err := u.identityRepo.GetIdentityByUserID(ctx, provider, userID)
+	if err != nil || identity == nil {
+		return "", err
+	}
+	return identity.Login, nil
+}
+
+// decodeEventData converts event.Data, which is a json.RawMessage once the
+// event has passed through the outbox, into out.
+func decodeEventData(event domain.Event, out interface{}) error {
+	payload, err := json.Marshal(event.Data)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(payload, out)
+}
@@ -0,0 +1,220 @@
+package usecases_test
+
+import (
+	"context"
+	"net/http"
+	"pullrequests/internal/adapters/github"
+	"pullrequests/internal/adapters/github/githubtest"
+	"pullrequests/internal/adapters/memory"
+	"pullrequests/internal/domain"
+	"pullrequests/internal/dtos"
+	"pullrequests/internal/metrics"
+	"pullrequests/internal/usecases"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+type syncEnv struct {
+	server       *githubtest.Server
+	sync         *usecases.ReviewerSyncUsecase
+	dispatcher   *usecases.OutboxDispatcher
+	pullrequests *usecases.PRUsecase
+	jobs         *memory.JobRepo
+}
+
+// newSyncEnv wires the reviewer sync the way the server does, against a fake
+// GitHub. Alice authors; Bob and Dave have GitHub logins, Carol has none.
+// Failed jobs are due again at once unless GitHub sends Retry-After.
+func newSyncEnv(t *testing.T) *syncEnv {
+	t.Helper()
+	ctx := context.Background()
+	store := memory.NewStore()
+	trm := memory.NewTransactionManager(store)
+	historyRepo := memory.NewReviewHistoryRepo(store)
+	identityRepo := memory.NewIdentityRepo(store)
+	jobRepo := memory.NewJobRepo(store)
+	outboxRepo := memory.NewOutboxRepo(store)
+
+	teams := usecases.NewTeamUsecase(memory.NewTeamRepo(store), memory.NewUserRepo(store), trm)
+	_, err := teams.AddTeam(ctx, dtos.TeamRequest{Team: dtos.Team{
+		TeamName: "billing",
+		Members: []dtos.TeamMember{
+			{UserID: "u1", Username: "alice", IsActive: true},
+			{UserID: "u2", Username: "bob", IsActive: true},
+			{UserID: "u3", Username: "carol", IsActive: true},
+			{UserID: "u4", Username: "dave", IsActive: true},
+		},
+	}})
+	if err != nil {
+		t.Fatalf("add team: %v", err)
+	}
+	for userID, login := range map[string]string{"u2": "octo-bob", "u4": "octo-dave"} {
+		err := identityRepo.SetIdentity(ctx, &domain.ExternalIdentity{Provider: domain.ProviderGitHub, Login: login, UserID: userID, CreatedAt: time.Now()})
+		if err != nil {
+			t.Fatalf("set identity: %v", err)
+		}
+	}
+
+	server := githubtest.NewServer(t)
+	client := github.NewClient(server.URL, "ghp_test", &http.Client{Timeout: 5 * time.Second})
+	sync := usecases.NewReviewerSyncUsecase(identityRepo, jobRepo, trm, usecases.RetryPolicy{MaxAttempts: 5}, client)
+	eventBus := usecases.NewEventBus()
+	eventBus.Subscribe(sync.HandleEvent, domain.EventReviewerAssigned, domain.EventReviewerReassigned)
+
+	return &syncEnv{
+		server:     server,
+		sync:       sync,
+		dispatcher: usecases.NewOutboxDispatcher(outboxRepo, trm, usecases.RetryPolicy{MaxAttempts: 1}, eventBus),
+		pullrequests: usecases.NewPRUsecase(memory.NewPRRepo(store), memory.NewUserRepo(store), historyRepo,
+			usecases.NewFirstNStrategy(), usecases.NewOutboxPublisher(outboxRepo), metrics.Recorder{}, trm),
+		jobs: jobRepo,
+	}
+}
+
+// dispatch turns the published events into reviewer.sync jobs.
+func (env *syncEnv) dispatch(t *testing.T) {
+	t.Helper()
+	if _, err := env.dispatcher.Dispatch(context.Background()); err != nil {
+		t.Fatalf("dispatch outbox: %v", err)
+	}
+}
+
+func (env *syncEnv) syncDue(t *testing.T, wantJobs int) {
+	t.Helper()
+	if ran, err := env.sync.SyncDue(context.Background()); err != nil || ran != wantJobs {
+		t.Fatalf("SyncDue: ran %d jobs (err %v), want %d", ran, err, wantJobs)
+	}
+}
+
+// pendingJobs returns the reviewer.sync jobs due by at.
+func (env *syncEnv) pendingJobs(t *testing.T, at time.Time) []domain.Job {
+	t.Helper()
+	jobs, err := env.jobs.GetDueJobs(context.Background(), domain.JobReviewerSync, at, 100)
+	if err != nil {
+		t.Fatalf("get jobs: %v", err)
+	}
+	return jobs
+}
+
+func (env *syncEnv) createPR(t *testing.T) {
+	t.Helper()
+	_, err := env.pullrequests.CreatePR(context.Background(), dtos.CreatePRRequest{
+		PullRequestID:   "acme/billing#42",
+		PullRequestName: "Retry failed invoice exports",
+		AuthorID:        "u1",
+	})
+	if err != nil {
+		t.Fatalf("create pull request: %v", err)
+	}
+	env.dispatch(t)
+}
+
+type call struct {
+	method    string
+	reviewers []string
+}
+
+func assertCalls(t *testing.T, requests []githubtest.Request, want ...call) {
+	t.Helper()
+	if len(requests) != len(want) {
+		t.Fatalf("github got %d requests, want %d", len(requests), len(want))
+	}
+	for i, request := range requests {
+		if request.Method != want[i].method || !slices.Equal(request.Reviewers, want[i].reviewers) ||
+			request.Path != "/repos/acme/billing/pulls/42/requested_reviewers" {
+			t.Errorf("request %d: got %s %s %v, want %s %v", i, request.Method, request.Path, request.Reviewers, want[i].method, want[i].reviewers)
+		}
+	}
+}
+
+func TestReviewerSync(t *testing.T) {
+	ctx := context.Background()
+	env := newSyncEnv(t)
+
+	// Bob and Carol are assigned; Carol has no GitHub login and is skipped.
+	env.createPR(t)
+	env.syncDue(t, 2)
+	assertCalls(t, env.server.Requests(), call{http.MethodPost, []string{"octo-bob"}})
+
+	// Replacing Bob with Dave removes Bob's review request on GitHub.
+	response, err := env.pullrequests.ReassignReviewer(ctx, dtos.ReassignPRRequest{PullRequestID: "acme/billing#42", OldUserID: "u2"})
+	if err != nil || response.ReplacedBy != "u4" {
+		t.Fatalf("reassign: got %+v (err %v), want u4", response, err)
+	}
+	env.dispatch(t)
+	env.syncDue(t, 2)
+	// The two jobs run in either order.
+	requests := env.server.Requests()[1:]
+	slices.SortFunc(requests, func(a, b githubtest.Request) int { return strings.Compare(a.Method, b.Method) })
+	assertCalls(t, requests,
+		call{http.MethodDelete, []string{"octo-bob"}},
+		call{http.MethodPost, []string{"octo-dave"}})
+
+	// Pull requests that are not on GitHub queue nothing.
+	if _, err := env.pullrequests.CreatePR(ctx, dtos.CreatePRRequest{PullRequestID: "pr-7", PullRequestName: "Local", AuthorID: "u1"}); err != nil {
+		t.Fatalf("create pull request: %v", err)
+	}
+	env.dispatch(t)
+	env.syncDue(t, 0)
+	if got := len(env.server.Requests()); got != 3 {
+		t.Errorf("github got %d requests, want 3", got)
+	}
+}
+
+func TestReviewerSyncRetries(t *testing.T) {
+	ctx := context.Background()
+	env := newSyncEnv(t)
+	env.server.Respond(
+		githubtest.Response{Status: http.StatusBadGateway, Message: "Server Error"},
+		githubtest.Response{Status: http.StatusTooManyRequests, RetryAfter: "60", Message: "API rate limit exceeded"},
+	)
+
+	env.createPR(t)
+	env.syncDue(t, 2)
+	// The job is due again at once after the 502.
+	pending := env.pendingJobs(t, time.Now())
+	if len(pending) != 1 || pending[0].Attempts != 1 || !strings.Contains(pending[0].LastError, "502") {
+		t.Fatalf("got pending jobs %+v, want one after a 502", pending)
+	}
+
+	// After the 429 it waits for Retry-After.
+	env.syncDue(t, 1)
+	if pending := env.pendingJobs(t, time.Now().Add(30*time.Second)); len(pending) != 0 {
+		t.Fatalf("job is due within the Retry-After of 60s: %+v", pending)
+	}
+	pending = env.pendingJobs(t, time.Now().Add(61*time.Second))
+	if len(pending) != 1 || pending[0].Attempts != 2 || !strings.Contains(pending[0].LastError, "429") {
+		t.Fatalf("got pending jobs %+v, want one after a 429", pending)
+	}
+
+	job := pending[0]
+	job.NextAttemptAt = time.Now()
+	if err := env.jobs.UpdateJob(ctx, &job); err != nil {
+		t.Fatalf("update job: %v", err)
+	}
+	env.syncDue(t, 1)
+	if pending := env.pendingJobs(t, time.Now().Add(24*time.Hour)); len(pending) != 0 {
+		t.Errorf("jobs still pending after a successful attempt: %+v", pending)
+	}
+	// One request per attempt: the client does not retry on its own.
+	assertCalls(t, env.server.Requests(),
+		call{http.MethodPost, []string{"octo-bob"}},
+		call{http.MethodPost, []string{"octo-bob"}},
+		call{http.MethodPost, []string{"octo-bob"}})
+}
+
+func TestReviewerSyncRejected(t *testing.T) {
+	env := newSyncEnv(t)
+	env.server.Respond(githubtest.Response{Status: http.StatusUnprocessableEntity, Message: "Reviews may only be requested from collaborators."})
+
+	env.createPR(t)
+	env.syncDue(t, 2)
+	// The refused job is failed for good and never sent again.
+	if pending := env.pendingJobs(t, time.Now().Add(24*time.Hour)); len(pending) != 0 {
+		t.Fatalf("a rejected job is still pending: %+v", pending)
+	}
+	env.syncDue(t, 0)
+	assertCalls(t, env.server.Requests(), call{http.MethodPost, []string{"octo-bob"}})
+}
@@ -0,0 +1,268 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"pullrequests/internal/domain"
+	"pullrequests/internal/dtos"
+	"time"
+)
+
+// ReviewSLAUsecase enforces review SLAs: once an OPEN pull request is older
+// than the team's RemindAfter its reviewers get a review.reminder event, and
+// once it is older than EscalateAfter every reviewer is replaced through the
+// reassignment logic, falling back to the team lead.
+type ReviewSLAUsecase struct {
+	slaRepo         domain.ReviewSLARepo
+	teamRepo        domain.TeamRepo
+	userRepo        domain.UserRepo
+	pullrequestRepo domain.PullRequestRepo
+	prUsecase       *PRUsecase
+	publisher       domain.EventPublisher
+	metrics         domain.AssignmentMetrics
+	defaults        domain.ReviewSLA
+	trm             domain.TransactionManager
+}
+
+func NewReviewSLAUsecase(
+	slaRepo domain.ReviewSLARepo,
+	teamRepo domain.TeamRepo,
+	userRepo domain.UserRepo,
+	pullrequestRepo domain.PullRequestRepo,
+	prUsecase *PRUsecase,
+	publisher domain.EventPublisher,
+	metrics domain.AssignmentMetrics,
+	defaults domain.ReviewSLA,
+	trm domain.TransactionManager) *ReviewSLAUsecase {
+	return &ReviewSLAUsecase{
+		slaRepo:         slaRepo,
+		teamRepo:        teamRepo,
+		userRepo:        userRepo,
+		pullrequestRepo: pullrequestRepo,
+		prUsecase:       prUsecase,
+		publisher:       publisher,
+		metrics:         metrics,
+		defaults:        defaults,
+		trm:             trm,
+	}
+}
+
+func (u *ReviewSLAUsecase) GetSLA(ctx context.Context, teamName string) (*dtos.ReviewSLAResponse, error) {
+	var response *dtos.ReviewSLAResponse
+	err := u.trm.Do(ctx, func(ctx context.Context) error {
+		team, err := u.teamRepo.GetTeamByTeamName(ctx, teamName)
+		if err != nil {
+			return err
+		}
+		if team == nil {
+			return domain.NewDomainError(domain.ErrNotFoundCode)
+		}
+
+		sla, err := u.slaRepo.GetSLA(ctx, teamName)
+		if err != nil {
+			return err
+		}
+		isDefault := sla == nil
+		if isDefault {
+			sla = u.defaultSLA(teamName)
+		}
+		response = &dtos.ReviewSLAResponse{SLA: toReviewSLADTO(sla)}
+		response.SLA.IsDefault = isDefault
+		return nil
+	}, domain.WithName("ReviewSLAUsecase.GetSLA"))
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// SetSLA is allowed to admins and leads of the team.
+func (u *ReviewSLAUsecase) SetSLA(ctx context.Context, teamName string, remindAfter, escalateAfter time.Duration) (*dtos.ReviewSLAResponse, error) {
+	sla := &domain.ReviewSLA{
+		TeamName:      teamName,
+		RemindAfter:   remindAfter,
+		EscalateAfter: escalateAfter,
+		UpdatedAt:     time.Now(),
+	}
+
+	err := u.trm.Do(ctx, func(ctx context.Context) error {
+		team, err := u.teamRepo.GetTeamByTeamName(ctx, teamName)
+		if err != nil {
+			return err
+		}
+		if team == nil {
+			return domain.NewDomainError(domain.ErrNotFoundCode)
+		}
+
+		if actor := domain.ActorFromContext(ctx); actor != nil {
+			allowed, err := isTeamLead(ctx, u.userRepo, actor, teamName)
+			if err != nil {
+				return err
+			}
+			if !allowed {
+				return domain.NewDomainError(domain.ErrForbiddenCode)
+			}
+		}
+
+		return u.slaRepo.SetSLA(ctx, sla)
+	}, domain.WithName("ReviewSLAUsecase.SetSLA"))
+	if err != nil {
+		return nil, err
+	}
+	return &dtos.ReviewSLAResponse{SLA: toReviewSLADTO(sla)}, nil
+}
+
+// Run takes the due SLA step for every overdue pull request and returns how
+// many pull requests it acted on. Each step runs in its own transaction and
+// is recorded, so a step is taken at most once per pull request. A failing
+// pull request is logged and skipped so that it does not hold up the others;
+// the error returned then only reports how many failed.
+func (u *ReviewSLAUsecase) Run(ctx context.Context) (int, error) {
+	now := time.Now()
+	slas := make(map[string]domain.ReviewSLA)
+	var reviews []domain.OpenReview
+	err := u.trm.Do(ctx, func(ctx context.Context) error {
+		stored, err := u.slaRepo.GetSLAs(ctx)
+		if err != nil {
+			return err
+		}
+
+		earliest := minThreshold(0, u.defaults)
+		for _, sla := range stored {
+			slas[sla.TeamName] = sla
+			earliest = minThreshold(earliest, sla)
+		}
+		if earliest == 0 {
+			return nil
+		}
+
+		reviews, err = u.slaRepo.GetOpenReviews(ctx, now.Add(-earliest))
+		return err
+	}, domain.WithName("ReviewSLAUsecase.Run"))
+	if err != nil {
+		return 0, err
+	}
+
+	processed, failed := 0, 0
+	for _, review := range reviews {
+		sla, ok := slas[review.TeamName]
+		if !ok {
+			sla = u.defaults
+		}
+		age := now.Sub(review.CreatedAt)
+
+		switch {
+		case sla.EscalateAfter > 0 && review.EscalatedAt == nil && age >= sla.EscalateAfter:
+			err = u.escalate(ctx, review.PullRequestID, now)
+		case sla.RemindAfter > 0 && review.RemindedAt == nil && review.EscalatedAt == nil && age >= sla.RemindAfter:
+			err = u.remind(ctx, review, now)
+		default:
+			continue
+		}
+		if err != nil {
+			slog.ErrorContext(ctx, "review sla step failed", "pull_request_id", review.PullRequestID, "error", err)
+			failed++
+			continue
+		}
+		processed++
+	}
+	if failed > 0 {
+		return processed, fmt.Errorf("review sla: %d of %d pull requests failed", failed, processed+failed)
+	}
+	return processed, nil
+}
+
+func (u *ReviewSLAUsecase) remind(ctx context.Context, review domain.OpenReview, now time.Time) error {
+	return u.trm.Do(ctx, func(ctx context.Context) error {
+		reviewers, err := u.pullrequestRepo.GetReviewers(ctx, review.PullRequestID)
+		if err != nil {
+			return err
+		}
+		for _, reviewer := range reviewers {
+			err := publishEvent(ctx, u.publisher, domain.EventReviewReminder, dtos.ReviewReminderEvent{
+				PullRequestID: review.PullRequestID,
+				ReviewerID:    reviewer.UserID,
+				OpenSince:     review.CreatedAt.Format(time.RFC3339),
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return u.slaRepo.MarkReminded(ctx, review.PullRequestID, now)
+	}, domain.WithName("ReviewSLAUsecase.remind"))
+}
+
+// escalate replaces every reviewer, never picking one of the current
+// reviewers again. Reviewers without a replacement candidate keep the review;
+// they are counted as NO_CANDIDATE once the escalation is committed.
+func (u *ReviewSLAUsecase) escalate(ctx context.Context, pullRequestID string, now time.Time) error {
+	var noCandidateTeams []string
+	err := u.trm.Do(ctx, func(ctx context.Context) error {
+		noCandidateTeams = noCandidateTeams[:0]
+		reviewers, err := u.pullrequestRepo.GetReviewers(ctx, pullRequestID)
+		if err != nil {
+			return err
+		}
+		stale := make([]string, 0, len(reviewers))
+		for _, reviewer := range reviewers {
+			stale = append(stale, reviewer.UserID)
+		}
+
+		for _, reviewerID := range stale {
+			_, teamName, err := u.prUsecase.reassignReviewer(ctx, dtos.ReassignPRRequest{
+				PullRequestID:   pullRequestID,
+				OldUserID:       reviewerID,
+				AllowEscalation: true,
+				ExcludeUserIDs:  stale,
+			})
+			switch {
+			case err == nil:
+			case hasErrorCode(err, domain.ErrNoCandidateCode):
+				noCandidateTeams = append(noCandidateTeams, teamName)
+				slog.WarnContext(ctx, "review sla: no replacement reviewer", "pull_request_id", pullRequestID, "reviewer_id", reviewerID)
+			case hasErrorCode(err, domain.ErrPRMergedCode):
+				return nil
+			default:
+				return err
+			}
+		}
+		return u.slaRepo.MarkEscalated(ctx, pullRequestID, now)
+	}, domain.WithName("ReviewSLAUsecase.escalate"), domain.WithIsolation(domain.IsolationSerializable))
+	if err != nil {
+		return err
+	}
+	for _, teamName := range noCandidateTeams {
+		u.metrics.IncNoCandidate(teamName)
+	}
+	return nil
+}
+
+func (u *ReviewSLAUsecase) defaultSLA(teamName string) *domain.ReviewSLA {
+	sla := u.defaults
+	sla.TeamName = teamName
+	return &sla
+}
+
+// minThreshold returns the smallest non-zero duration among current and the
+// steps of sla, or zero when all of them are disabled.
+func minThreshold(current time.Duration, sla domain.ReviewSLA) time.Duration {
+	for _, threshold := range []time.Duration{sla.RemindAfter, sla.EscalateAfter} {
+		if threshold > 0 && (current == 0 || threshold < current) {
+			current = threshold
+		}
+	}
+	return current
+}
+
+func toReviewSLADTO(sla *domain.ReviewSLA) dtos.ReviewSLA {
+	out := dtos.ReviewSLA{
+		TeamName:      sla.TeamName,
+		RemindAfter:   sla.RemindAfter.String(),
+		EscalateAfter: sla.EscalateAfter.String(),
+	}
+	if !sla.UpdatedAt.IsZero() {
+		out.UpdatedAt = sla.UpdatedAt.Format(time.RFC3339)
+	}
+	return out
+}
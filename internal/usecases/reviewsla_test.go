@@ -0,0 +1,102 @@
+package usecases_test
+
+import (
+	"context"
+	"errors"
+	"pullrequests/internal/adapters/memory"
+	"pullrequests/internal/domain"
+	"pullrequests/internal/dtos"
+	"pullrequests/internal/usecases"
+	"testing"
+	"time"
+)
+
+var errSerialization = errors.New("serialization failure")
+
+// retryingTransactions rolls back the first attempt of every top-level
+// transaction, as a serialization failure would, and runs it again. Nested
+// calls join the outer transaction.
+type retryingTransactions struct {
+	domain.TransactionManager
+	retries int
+}
+
+type retryingKey struct{}
+
+func (m *retryingTransactions) Do(ctx context.Context, fn func(context.Context) error, opts ...domain.TxOption) error {
+	if ctx.Value(retryingKey{}) != nil {
+		return m.TransactionManager.Do(ctx, fn, opts...)
+	}
+	ctx = context.WithValue(ctx, retryingKey{}, true)
+	err := m.TransactionManager.Do(ctx, func(ctx context.Context) error {
+		if err := fn(ctx); err != nil {
+			return err
+		}
+		return errSerialization
+	}, opts...)
+	if !errors.Is(err, errSerialization) {
+		return err
+	}
+	m.retries++
+	return m.TransactionManager.Do(ctx, fn, opts...)
+}
+
+type assignmentMetrics struct {
+	noCandidate map[string]int
+}
+
+func (m *assignmentMetrics) IncNoCandidate(teamName string) {
+	m.noCandidate[teamName]++
+}
+
+func TestNoCandidateCountedOnceWhenRetried(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewStore()
+	trm := &retryingTransactions{TransactionManager: memory.NewTransactionManager(store)}
+	historyRepo := memory.NewReviewHistoryRepo(store)
+	recorder := &assignmentMetrics{noCandidate: make(map[string]int)}
+	publisher := usecases.NewOutboxPublisher(memory.NewOutboxRepo(store))
+
+	teams := usecases.NewTeamUsecase(memory.NewTeamRepo(store), memory.NewUserRepo(store), trm)
+	_, err := teams.AddTeam(ctx, dtos.TeamRequest{Team: dtos.Team{
+		TeamName: "backend",
+		Members: []dtos.TeamMember{
+			{UserID: "u1", Username: "alice", IsActive: true},
+			{UserID: "u2", Username: "bob", IsActive: true},
+		},
+	}})
+	if err != nil {
+		t.Fatalf("add team: %v", err)
+	}
+	pullrequests := usecases.NewPRUsecase(memory.NewPRRepo(store), memory.NewUserRepo(store), historyRepo,
+		usecases.NewFirstNStrategy(), publisher, recorder, trm)
+	if _, err := pullrequests.CreatePR(ctx, dtos.CreatePRRequest{PullRequestID: "pr-1", PullRequestName: "Add search", AuthorID: "u1"}); err != nil {
+		t.Fatalf("create pull request: %v", err)
+	}
+
+	// Bob is the only possible reviewer, so he cannot be replaced.
+	_, err = pullrequests.ReassignReviewer(ctx, dtos.ReassignPRRequest{PullRequestID: "pr-1", OldUserID: "u2"})
+	var domainErr domain.DomainError
+	if !errors.As(err, &domainErr) || domainErr.Code() != string(domain.ErrNoCandidateCode) {
+		t.Fatalf("reassign: got %v, want NO_CANDIDATE", err)
+	}
+	if got := recorder.noCandidate["backend"]; got != 1 {
+		t.Fatalf("after reassign: counted %d NO_CANDIDATE, want 1", got)
+	}
+
+	// The escalation reassigns inside its own serializable transaction,
+	// which is retried once.
+	sla := usecases.NewReviewSLAUsecase(memory.NewReviewSLARepo(store), memory.NewTeamRepo(store), memory.NewUserRepo(store),
+		memory.NewPRRepo(store), pullrequests, publisher, recorder, domain.ReviewSLA{EscalateAfter: time.Nanosecond}, trm)
+	trm.retries = 0
+	processed, err := sla.Run(ctx)
+	if err != nil || processed != 1 {
+		t.Fatalf("sla run: processed %d (err %v), want 1", processed, err)
+	}
+	if trm.retries == 0 {
+		t.Fatal("the escalation was not retried")
+	}
+	if got := recorder.noCandidate["backend"]; got != 2 {
+		t.Errorf("after escalation: counted %d NO_CANDIDATE, want 2 (one per reassignment, not per attempt)", got)
+	}
+}
@@ -0,0 +1,64 @@
+package usecases
+
+import (
+	"context"
+	"pullrequests/internal/domain"
+	"pullrequests/internal/dtos"
+)
+
+type StatsUsecase struct {
+	teamRepo        domain.TeamRepo
+	pullrequestRepo domain.PullRequestRepo
+	trm             domain.TransactionManager
+}
+
+func NewStatsUsecase(
+	teamRepo domain.TeamRepo,
+	pullrequestRepo domain.PullRequestRepo,
+	trm domain.TransactionManager) *StatsUsecase {
+	return &StatsUsecase{
+		teamRepo:        teamRepo,
+		pullrequestRepo: pullrequestRepo,
+		trm:             trm,
+	}
+}
+
+func (u *StatsUsecase) GetReviewerStats(ctx context.Context, teamName string) (*dtos.ReviewerStatsResponse, error) {
+	var response *dtos.ReviewerStatsResponse
+
+	err := u.trm.Do(ctx, func(ctx context.Context) error {
+		team, err := u.teamRepo.GetTeamByTeamName(ctx, teamName)
+		if err != nil {
+			return err
+		}
+		if team == nil {
+			return domain.NewDomainError(domain.ErrNotFoundCode)
+		}
+
+		stats, err := u.pullrequestRepo.GetReviewerStatsByTeamName(ctx, teamName)
+		if err != nil {
+			return err
+		}
+
+		response = &dtos.ReviewerStatsResponse{
+			TeamName:  team.Name,
+			Reviewers: make([]dtos.ReviewerStat, 0, len(stats)),
+		}
+		for _, stat := range stats {
+			response.TotalAssignments += stat.Assignments
+			response.LeadAssignments += stat.Escalations
+			response.Reviewers = append(response.Reviewers, dtos.ReviewerStat{
+				UserID:          stat.UserID,
+				Username:        stat.Username,
+				Role:            string(stat.Role),
+				Assignments:     stat.Assignments,
+				OpenAssignments: stat.OpenAssignments,
+			})
+		}
+		return nil
+	}, domain.WithName("StatsUsecase.GetReviewerStats"), domain.WithReadOnly())
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
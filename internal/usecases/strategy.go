@@ -0,0 +1,80 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"pullrequests/internal/domain"
+	"sort"
+	"strings"
+)
+
+const (
+	StrategyFirstN          = "first_n"
+	StrategyLeastRecentPair = "least_recent_pair"
+)
+
+// ReviewerStrategies are the names accepted by NewReviewerStrategy.
+var ReviewerStrategies = []string{StrategyFirstN, StrategyLeastRecentPair}
+
+type FirstNStrategy struct{}
+
+func NewFirstNStrategy() *FirstNStrategy {
+	return &FirstNStrategy{}
+}
+
+func (s *FirstNStrategy) SelectReviewers(
+	ctx context.Context,
+	authorID string,
+	candidates []domain.User,
+	count int) ([]domain.User, error) {
+	if len(candidates) < count {
+		count = len(candidates)
+	}
+	return candidates[:count], nil
+}
+
+type LeastRecentPairStrategy struct {
+	historyRepo domain.ReviewHistoryRepo
+}
+
+func NewLeastRecentPairStrategy(historyRepo domain.ReviewHistoryRepo) *LeastRecentPairStrategy {
+	return &LeastRecentPairStrategy{historyRepo: historyRepo}
+}
+
+func (s *LeastRecentPairStrategy) SelectReviewers(
+	ctx context.Context,
+	authorID string,
+	candidates []domain.User,
+	count int) ([]domain.User, error) {
+	lastAssignedAt, err := s.historyRepo.GetLastAssignedAtByAuthor(ctx, authorID)
+	if err != nil {
+		return nil, err
+	}
+
+	ordered := make([]domain.User, len(candidates))
+	copy(ordered, candidates)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		left, leftReviewed := lastAssignedAt[ordered[i].UserID]
+		right, rightReviewed := lastAssignedAt[ordered[j].UserID]
+		if leftReviewed != rightReviewed {
+			return !leftReviewed
+		}
+		return left.Before(right)
+	})
+
+	if len(ordered) < count {
+		count = len(ordered)
+	}
+	return ordered[:count], nil
+}
+
+func NewReviewerStrategy(name string, historyRepo domain.ReviewHistoryRepo) (domain.ReviewerStrategy, error) {
+	switch name {
+	case StrategyFirstN:
+		return NewFirstNStrategy(), nil
+	case StrategyLeastRecentPair:
+		return NewLeastRecentPairStrategy(historyRepo), nil
+	default:
+		return nil, fmt.Errorf("unknown reviewer strategy %q, valid strategies: %s", name, strings.Join(ReviewerStrategies, ", "))
+	}
+}
@@ -0,0 +1,28 @@
+package usecases_test
+
+import (
+	"pullrequests/internal/adapters/memory"
+	"pullrequests/internal/usecases"
+	"strings"
+	"testing"
+)
+
+func TestNewReviewerStrategy(t *testing.T) {
+	historyRepo := memory.NewReviewHistoryRepo(memory.NewStore())
+	for _, name := range usecases.ReviewerStrategies {
+		if strategy, err := usecases.NewReviewerStrategy(name, historyRepo); err != nil || strategy == nil {
+			t.Errorf("NewReviewerStrategy(%q): got %v, %v", name, strategy, err)
+		}
+	}
+
+	for _, name := range []string{"", "First_N", "least-recent-pair", "random"} {
+		_, err := usecases.NewReviewerStrategy(name, historyRepo)
+		if err == nil {
+			t.Errorf("NewReviewerStrategy(%q) succeeded, want an error", name)
+			continue
+		}
+		if !strings.Contains(err.Error(), "first_n, least_recent_pair") {
+			t.Errorf("NewReviewerStrategy(%q): error %q does not list the valid strategies", name, err)
+		}
+	}
+}
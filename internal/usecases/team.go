@@ -20,6 +20,14 @@ func NewTeamUsecase(
 }
 
 func (u *TeamUsecase) AddTeam(ctx context.Context, in dtos.TeamRequest) (*dtos.TeamResponse, error) {
+	members := make([]dtos.TeamMember, 0, len(in.Members))
+	for _, m := range in.Members {
+		if m.Role == "" {
+			m.Role = string(domain.TeamRoleMember)
+		}
+		members = append(members, m)
+	}
+
 	err := u.trm.Do(ctx, func(ctx context.Context) error {
 		team, err := u.teamRepo.GetTeamByTeamName(ctx, in.TeamName)
 		if err != nil {
@@ -31,7 +39,7 @@ func (u *TeamUsecase) AddTeam(ctx context.Context, in dtos.TeamRequest) (*dtos.T
 		if err := u.teamRepo.Add(ctx, &domain.Team{Name: in.TeamName}); err != nil {
 			return err
 		}
-		for _, m := range in.Members {
+		for _, m := range members {
 			existingUser, err := u.userRepo.GetUserByID(ctx, m.UserID)
 			if err != nil {
 				return err
@@ -42,19 +50,20 @@ func (u *TeamUsecase) AddTeam(ctx context.Context, in dtos.TeamRequest) (*dtos.T
 			if err := u.teamRepo.AddTeamMember(ctx, in.TeamName, &domain.TeamMember{
 				UserID:   m.UserID,
 				Username: m.Username,
-				IsActive: m.IsActive}); err != nil {
+				IsActive: m.IsActive,
+				Role:     domain.TeamRole(m.Role)}); err != nil {
 				return err
 			}
 		}
 		return nil
-	})
+	}, domain.WithName("TeamUsecase.AddTeam"))
 	if err != nil {
 		return nil, err
 	}
 	response := &dtos.TeamResponse{
 		Team: dtos.Team{
 			TeamName: in.TeamName,
-			Members:  in.Members,
+			Members:  members,
 		},
 	}
 	return response, nil
@@ -83,10 +92,11 @@ func (u *TeamUsecase) GetTeam(ctx context.Context, teamName string) (*dtos.Team,
 				UserID:   m.UserID,
 				Username: m.Username,
 				IsActive: m.IsActive,
+				Role:     string(m.Role),
 			})
 		}
 		return nil
-	})
+	}, domain.WithName("TeamUsecase.GetTeam"), domain.WithReadOnly())
 	if err != nil {
 		return nil, err
 	}
@@ -7,14 +7,16 @@ import (
 )
 
 type UserUsecase struct {
-	userRepo domain.UserRepo
-	trm      domain.TransactionManager
+	userRepo  domain.UserRepo
+	publisher domain.EventPublisher
+	trm       domain.TransactionManager
 }
 
-func NewUserUsecase(userRepo domain.UserRepo, trm domain.TransactionManager) *UserUsecase {
+func NewUserUsecase(userRepo domain.UserRepo, publisher domain.EventPublisher, trm domain.TransactionManager) *UserUsecase {
 	return &UserUsecase{
-		userRepo: userRepo,
-		trm:      trm,
+		userRepo:  userRepo,
+		publisher: publisher,
+		trm:       trm,
 	}
 }
 
@@ -29,6 +31,17 @@ func (u *UserUsecase) SetUserActive(ctx context.Context, req dtos.UserActiveRequ
 			return domain.NewDomainError(domain.ErrNotFoundCode)
 		}
 
+		if actor := domain.ActorFromContext(ctx); actor != nil && actor.UserID != existingUser.UserID {
+			allowed, err := isTeamLead(ctx, u.userRepo, actor, existingUser.TeamName)
+			if err != nil {
+				return err
+			}
+			if !allowed {
+				return domain.NewDomainError(domain.ErrForbiddenCode)
+			}
+		}
+
+		deactivated := existingUser.IsActive && !req.IsActive
 		existingUser.IsActive = req.IsActive
 
 		if err := u.userRepo.UpdateUser(ctx, existingUser); err != nil {
@@ -37,20 +50,24 @@ func (u *UserUsecase) SetUserActive(ctx context.Context, req dtos.UserActiveRequ
 
 		user = existingUser
 
+		if deactivated {
+			return publishEvent(ctx, u.publisher, domain.EventUserDeactivated, toUserDTO(user))
+		}
 		return nil
-	})
+	}, domain.WithName("UserUsecase.SetUserActive"))
 
 	if err != nil {
 		return nil, err
 	}
 
-	response := &dtos.UserResponse{
-		User: dtos.User{
-			UserID:   user.UserID,
-			Username: user.Username,
-			TeamName: user.TeamName,
-			IsActive: user.IsActive,
-		},
+	return &dtos.UserResponse{User: toUserDTO(user)}, nil
+}
+
+func toUserDTO(user *domain.User) dtos.User {
+	return dtos.User{
+		UserID:   user.UserID,
+		Username: user.Username,
+		TeamName: user.TeamName,
+		IsActive: user.IsActive,
 	}
-	return response, nil
 }
@@ -0,0 +1,295 @@
+package usecases
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"pullrequests/internal/domain"
+	"pullrequests/internal/dtos"
+	"time"
+)
+
+// deliveryLease is how long a claimed delivery stays invisible to other
+// workers; it must exceed the sender's HTTP timeout.
+const deliveryLease = time.Minute
+
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+// Delay returns the backoff before the next attempt after the given number of
+// failed attempts: BaseDelay doubled per attempt, capped at MaxDelay.
+func (p RetryPolicy) Delay(attempts int) time.Duration {
+	delay := p.BaseDelay
+	for i := 1; i < attempts && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, p.MaxDelay)
+}
+
+type WebhookUsecase struct {
+	webhookRepo domain.WebhookRepo
+	sender      domain.WebhookSender
+	trm         domain.TransactionManager
+	retry       RetryPolicy
+	batchSize   int
+}
+
+func NewWebhookUsecase(
+	webhookRepo domain.WebhookRepo,
+	sender domain.WebhookSender,
+	trm domain.TransactionManager,
+	retry RetryPolicy) *WebhookUsecase {
+	return &WebhookUsecase{
+		webhookRepo: webhookRepo,
+		sender:      sender,
+		trm:         trm,
+		retry:       retry,
+		batchSize:   50,
+	}
+}
+
+func (u *WebhookUsecase) CreateSubscription(ctx context.Context, req dtos.CreateWebhookRequest) (*dtos.CreateWebhookResponse, error) {
+	secret := req.Secret
+	if secret == "" {
+		generated, err := randomString(32)
+		if err != nil {
+			return nil, err
+		}
+		secret = "whsec_" + generated
+	}
+	subscriptionID, err := randomString(9)
+	if err != nil {
+		return nil, err
+	}
+
+	subscription := &domain.WebhookSubscription{
+		SubscriptionID: "wh_" + subscriptionID,
+		URL:            req.URL,
+		Secret:         secret,
+		CreatedAt:      time.Now(),
+	}
+	for _, event := range req.Events {
+		subscription.Events = append(subscription.Events, domain.EventType(event))
+	}
+
+	err = u.trm.Do(ctx, func(ctx context.Context) error {
+		return u.webhookRepo.AddSubscription(ctx, subscription)
+	}, domain.WithName("WebhookUsecase.CreateSubscription"))
+	if err != nil {
+		return nil, err
+	}
+
+	return &dtos.CreateWebhookResponse{
+		Subscription: toSubscriptionDTO(subscription),
+		Secret:       secret,
+	}, nil
+}
+
+func (u *WebhookUsecase) GetSubscriptions(ctx context.Context) (*dtos.WebhooksResponse, error) {
+	var response *dtos.WebhooksResponse
+	err := u.trm.Do(ctx, func(ctx context.Context) error {
+		subscriptions, err := u.webhookRepo.GetSubscriptions(ctx)
+		if err != nil {
+			return err
+		}
+		response = &dtos.WebhooksResponse{Subscriptions: make([]dtos.WebhookSubscription, 0, len(subscriptions))}
+		for _, subscription := range subscriptions {
+			response.Subscriptions = append(response.Subscriptions, toSubscriptionDTO(&subscription))
+		}
+		return nil
+	}, domain.WithName("WebhookUsecase.GetSubscriptions"), domain.WithReadOnly())
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (u *WebhookUsecase) DeleteSubscription(ctx context.Context, req dtos.DeleteWebhookRequest) error {
+	return u.trm.Do(ctx, func(ctx context.Context) error {
+		return u.webhookRepo.DeleteSubscription(ctx, req.SubscriptionID)
+	}, domain.WithName("WebhookUsecase.DeleteSubscription"))
+}
+
+func (u *WebhookUsecase) GetDeliveries(ctx context.Context, subscriptionID string, limit int) (*dtos.WebhookDeliveriesResponse, error) {
+	var response *dtos.WebhookDeliveriesResponse
+	err := u.trm.Do(ctx, func(ctx context.Context) error {
+		if subscriptionID != "" {
+			subscription, err := u.webhookRepo.GetSubscription(ctx, subscriptionID)
+			if err != nil {
+				return err
+			}
+			if subscription == nil {
+				return domain.NewDomainError(domain.ErrNotFoundCode)
+			}
+		}
+
+		deliveries, err := u.webhookRepo.GetDeliveries(ctx, subscriptionID, limit)
+		if err != nil {
+			return err
+		}
+		response = &dtos.WebhookDeliveriesResponse{Deliveries: make([]dtos.WebhookDelivery, 0, len(deliveries))}
+		for _, delivery := range deliveries {
+			response.Deliveries = append(response.Deliveries, toDeliveryDTO(&delivery))
+		}
+		return nil
+	}, domain.WithName("WebhookUsecase.GetDeliveries"), domain.WithReadOnly())
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (u *WebhookUsecase) Name() string {
+	return "webhook"
+}
+
+// Handle queues a delivery for every matching subscription. It runs inside
+// the outbox dispatcher transaction, so deliveries are queued exactly once.
+func (u *WebhookUsecase) Handle(ctx context.Context, event domain.Event) error {
+	subscriptions, err := u.webhookRepo.GetSubscriptions(ctx)
+	if err != nil {
+		return err
+	}
+
+	var payload []byte
+	for _, subscription := range subscriptions {
+		if !subscription.Matches(event.Type) {
+			continue
+		}
+		if payload == nil {
+			payload, err = json.Marshal(dtos.WebhookEvent{
+				EventID:    event.EventID,
+				Event:      string(event.Type),
+				OccurredAt: event.OccurredAt.Format(time.RFC3339),
+				Data:       event.Data,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		deliveryID, err := randomString(12)
+		if err != nil {
+			return err
+		}
+		err = u.webhookRepo.AddDelivery(ctx, &domain.WebhookDelivery{
+			DeliveryID:     "dlv_" + deliveryID,
+			SubscriptionID: subscription.SubscriptionID,
+			EventID:        event.EventID,
+			EventType:      event.Type,
+			Payload:        payload,
+			Status:         domain.DeliveryStatusPending,
+			NextAttemptAt:  event.OccurredAt,
+			CreatedAt:      event.OccurredAt,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeliverDue claims due deliveries, posts them and records the outcome. It
+// returns the number of deliveries attempted.
+func (u *WebhookUsecase) DeliverDue(ctx context.Context) (int, error) {
+	var claimed []domain.WebhookDelivery
+	now := time.Now()
+	err := u.trm.Do(ctx, func(ctx context.Context) error {
+		due, err := u.webhookRepo.GetDueDeliveries(ctx, now, u.batchSize)
+		if err != nil {
+			return err
+		}
+		for i := range due {
+			due[i].NextAttemptAt = now.Add(deliveryLease)
+			if err := u.webhookRepo.UpdateDelivery(ctx, &due[i]); err != nil {
+				return err
+			}
+		}
+		claimed = due
+		return nil
+	}, domain.WithName("WebhookUsecase.DeliverDue"))
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range claimed {
+		if err := u.deliver(ctx, &claimed[i]); err != nil {
+			return i, err
+		}
+	}
+	return len(claimed), nil
+}
+
+func (u *WebhookUsecase) deliver(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	subscription, err := u.webhookRepo.GetSubscription(ctx, delivery.SubscriptionID)
+	if err != nil {
+		return err
+	}
+	if subscription == nil {
+		return nil
+	}
+
+	status, sendErr := u.sender.Send(ctx, subscription, delivery)
+	now := time.Now()
+	delivery.Attempts++
+	delivery.ResponseStatus = status
+
+	switch {
+	case sendErr == nil && status >= 200 && status < 300:
+		delivery.Status = domain.DeliveryStatusDelivered
+		delivery.DeliveredAt = &now
+		delivery.LastError = ""
+	default:
+		if sendErr != nil {
+			delivery.LastError = sendErr.Error()
+		} else {
+			delivery.LastError = fmt.Sprintf("unexpected response status %d", status)
+		}
+		if delivery.Attempts >= u.retry.MaxAttempts {
+			delivery.Status = domain.DeliveryStatusFailed
+		} else {
+			delivery.NextAttemptAt = now.Add(u.retry.Delay(delivery.Attempts))
+		}
+	}
+
+	return u.trm.Do(ctx, func(ctx context.Context) error {
+		return u.webhookRepo.UpdateDelivery(ctx, delivery)
+	}, domain.WithName("WebhookUsecase.deliver"))
+}
+
+func toSubscriptionDTO(subscription *domain.WebhookSubscription) dtos.WebhookSubscription {
+	events := make([]string, 0, len(subscription.Events))
+	for _, event := range subscription.Events {
+		events = append(events, string(event))
+	}
+	return dtos.WebhookSubscription{
+		SubscriptionID: subscription.SubscriptionID,
+		URL:            subscription.URL,
+		Events:         events,
+		CreatedAt:      subscription.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+func toDeliveryDTO(delivery *domain.WebhookDelivery) dtos.WebhookDelivery {
+	out := dtos.WebhookDelivery{
+		DeliveryID:     delivery.DeliveryID,
+		SubscriptionID: delivery.SubscriptionID,
+		EventID:        delivery.EventID,
+		Event:          string(delivery.EventType),
+		Status:         string(delivery.Status),
+		Attempts:       delivery.Attempts,
+		LastError:      delivery.LastError,
+		ResponseStatus: delivery.ResponseStatus,
+		CreatedAt:      delivery.CreatedAt.Format(time.RFC3339),
+	}
+	if delivery.Status == domain.DeliveryStatusPending {
+		out.NextAttemptAt = delivery.NextAttemptAt.Format(time.RFC3339)
+	}
+	if delivery.DeliveredAt != nil {
+		out.DeliveredAt = delivery.DeliveredAt.Format(time.RFC3339)
+	}
+	return out
+}
@@ -0,0 +1,276 @@
+package usecases_test
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"pullrequests/internal/adapters/memory"
+	"pullrequests/internal/adapters/webhook"
+	"pullrequests/internal/domain"
+	"pullrequests/internal/dtos"
+	"pullrequests/internal/metrics"
+	"pullrequests/internal/usecases"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// receivedHook is one request seen by the test receiver.
+type receivedHook struct {
+	header http.Header
+	body   []byte
+}
+
+// receiver is an httptest subscriber answering with the queued statuses,
+// then 204.
+type receiver struct {
+	*httptest.Server
+	mu       sync.Mutex
+	requests []receivedHook
+	statuses []int
+}
+
+func newReceiver(t *testing.T, statuses ...int) *receiver {
+	t.Helper()
+	r := &receiver{statuses: statuses}
+	r.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.requests = append(r.requests, receivedHook{header: req.Header.Clone(), body: body})
+		status := http.StatusNoContent
+		if len(r.statuses) > 0 {
+			status, r.statuses = r.statuses[0], r.statuses[1:]
+		}
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(r.Close)
+	return r
+}
+
+func (r *receiver) received() []receivedHook {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.requests)
+}
+
+type webhookEnv struct {
+	webhooks     *usecases.WebhookUsecase
+	dispatcher   *usecases.OutboxDispatcher
+	teams        *usecases.TeamUsecase
+	pullrequests *usecases.PRUsecase
+}
+
+// newWebhookEnv wires the webhook path the way the server does: usecases
+// publish to the outbox, the dispatcher queues deliveries and DeliverDue
+// posts them. Retries are due immediately.
+func newWebhookEnv(t *testing.T, maxAttempts int) *webhookEnv {
+	t.Helper()
+	store := memory.NewStore()
+	trm := memory.NewTransactionManager(store)
+	webhookRepo := memory.NewWebhookRepo(store)
+	outboxRepo := memory.NewOutboxRepo(store)
+	historyRepo := memory.NewReviewHistoryRepo(store)
+
+	webhooks := usecases.NewWebhookUsecase(webhookRepo, webhook.NewHTTPSender(&http.Client{Timeout: 5 * time.Second}), trm, usecases.RetryPolicy{
+		MaxAttempts: maxAttempts,
+	})
+	publisher := usecases.NewOutboxPublisher(outboxRepo)
+	return &webhookEnv{
+		webhooks:   webhooks,
+		dispatcher: usecases.NewOutboxDispatcher(outboxRepo, trm, usecases.RetryPolicy{MaxAttempts: 1}, webhooks),
+		teams:      usecases.NewTeamUsecase(memory.NewTeamRepo(store), memory.NewUserRepo(store), trm),
+		pullrequests: usecases.NewPRUsecase(memory.NewPRRepo(store), memory.NewUserRepo(store), historyRepo,
+			usecases.NewFirstNStrategy(), publisher, metrics.Recorder{}, trm),
+	}
+}
+
+func (env *webhookEnv) createPR(t *testing.T, ctx context.Context) {
+	t.Helper()
+	_, err := env.teams.AddTeam(ctx, dtos.TeamRequest{Team: dtos.Team{
+		TeamName: "backend",
+		Members: []dtos.TeamMember{
+			{UserID: "u1", Username: "alice", IsActive: true},
+			{UserID: "u2", Username: "bob", IsActive: true},
+		},
+	}})
+	if err != nil {
+		t.Fatalf("add team: %v", err)
+	}
+	_, err = env.pullrequests.CreatePR(ctx, dtos.CreatePRRequest{PullRequestID: "pr-1", PullRequestName: "Add search", AuthorID: "u1"})
+	if err != nil {
+		t.Fatalf("create pull request: %v", err)
+	}
+	if _, err := env.dispatcher.Dispatch(ctx); err != nil {
+		t.Fatalf("dispatch outbox: %v", err)
+	}
+}
+
+func (env *webhookEnv) deliveries(t *testing.T, ctx context.Context, subscriptionID string) []dtos.WebhookDelivery {
+	t.Helper()
+	response, err := env.webhooks.GetDeliveries(ctx, subscriptionID, 100)
+	if err != nil {
+		t.Fatalf("get deliveries: %v", err)
+	}
+	return response.Deliveries
+}
+
+func TestWebhookDelivery(t *testing.T) {
+	ctx := context.Background()
+	env := newWebhookEnv(t, 3)
+	hooks := newReceiver(t)
+	const secret = "whsec_test"
+
+	created, err := env.webhooks.CreateSubscription(ctx, dtos.CreateWebhookRequest{
+		URL:    hooks.URL,
+		Secret: secret,
+		Events: []string{string(domain.EventPRCreated)},
+	})
+	if err != nil {
+		t.Fatalf("create subscription: %v", err)
+	}
+	// A subscription to other events receives nothing.
+	other := newReceiver(t)
+	_, err = env.webhooks.CreateSubscription(ctx, dtos.CreateWebhookRequest{URL: other.URL, Events: []string{string(domain.EventPRMerged)}})
+	if err != nil {
+		t.Fatalf("create subscription: %v", err)
+	}
+
+	env.createPR(t, ctx)
+	if _, err := env.webhooks.DeliverDue(ctx); err != nil {
+		t.Fatalf("deliver: %v", err)
+	}
+
+	requests := hooks.received()
+	if len(requests) != 1 {
+		t.Fatalf("receiver got %d requests, want 1", len(requests))
+	}
+	if got := other.received(); len(got) != 0 {
+		t.Errorf("unsubscribed receiver got %d requests", len(got))
+	}
+
+	request := requests[0]
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(request.body)
+	want := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	if got := request.header.Get(webhook.SignatureHeader); !hmac.Equal([]byte(got), []byte(want)) {
+		t.Errorf("signature = %q, want %q", got, want)
+	}
+	if got := request.header.Get(webhook.EventHeader); got != string(domain.EventPRCreated) {
+		t.Errorf("event header = %q, want %s", got, domain.EventPRCreated)
+	}
+	if got := request.header.Get("Content-Type"); got != "application/json" {
+		t.Errorf("content type = %q", got)
+	}
+
+	var event struct {
+		EventID string `json:"event_id"`
+		Event   string `json:"event"`
+		Data    struct {
+			PullRequestID     string   `json:"pull_request_id"`
+			AssignedReviewers []string `json:"assigned_reviewers"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(request.body, &event); err != nil {
+		t.Fatalf("decode payload: %v", err)
+	}
+	if event.EventID == "" || event.Event != string(domain.EventPRCreated) || event.Data.PullRequestID != "pr-1" ||
+		!slices.Equal(event.Data.AssignedReviewers, []string{"u2"}) {
+		t.Errorf("got payload %s", request.body)
+	}
+
+	deliveries := env.deliveries(t, ctx, created.Subscription.SubscriptionID)
+	if len(deliveries) != 1 {
+		t.Fatalf("got %d deliveries, want 1", len(deliveries))
+	}
+	delivery := deliveries[0]
+	if delivery.Status != string(domain.DeliveryStatusDelivered) || delivery.Attempts != 1 || delivery.ResponseStatus != http.StatusNoContent {
+		t.Errorf("got delivery %+v, want DELIVERED after one attempt", delivery)
+	}
+	if got := request.header.Get(webhook.DeliveryHeader); got != delivery.DeliveryID {
+		t.Errorf("delivery header = %q, want %s", got, delivery.DeliveryID)
+	}
+
+	// Delivered events are not sent again.
+	if attempted, err := env.webhooks.DeliverDue(ctx); err != nil || attempted != 0 {
+		t.Fatalf("second run attempted %d deliveries (err %v), want 0", attempted, err)
+	}
+}
+
+func TestWebhookRetries(t *testing.T) {
+	ctx := context.Background()
+	env := newWebhookEnv(t, 3)
+	hooks := newReceiver(t, http.StatusInternalServerError, http.StatusBadGateway)
+
+	created, err := env.webhooks.CreateSubscription(ctx, dtos.CreateWebhookRequest{URL: hooks.URL, Events: []string{string(domain.EventPRCreated)}})
+	if err != nil {
+		t.Fatalf("create subscription: %v", err)
+	}
+	env.createPR(t, ctx)
+
+	wantStatuses := []string{string(domain.DeliveryStatusPending), string(domain.DeliveryStatusPending), string(domain.DeliveryStatusDelivered)}
+	for attempt, wantStatus := range wantStatuses {
+		if _, err := env.webhooks.DeliverDue(ctx); err != nil {
+			t.Fatalf("attempt %d: deliver: %v", attempt+1, err)
+		}
+		delivery := env.deliveries(t, ctx, created.Subscription.SubscriptionID)[0]
+		if delivery.Status != wantStatus || delivery.Attempts != attempt+1 {
+			t.Fatalf("attempt %d: got delivery %+v, want %s", attempt+1, delivery, wantStatus)
+		}
+		if wantStatus == string(domain.DeliveryStatusPending) && delivery.LastError == "" {
+			t.Errorf("attempt %d: failed delivery has no last_error", attempt+1)
+		}
+	}
+
+	// Every attempt carries the same delivery ID and signed body.
+	requests := hooks.received()
+	if len(requests) != len(wantStatuses) {
+		t.Fatalf("receiver got %d requests, want %d", len(requests), len(wantStatuses))
+	}
+	for _, request := range requests[1:] {
+		if request.header.Get(webhook.DeliveryHeader) != requests[0].header.Get(webhook.DeliveryHeader) ||
+			request.header.Get(webhook.SignatureHeader) != requests[0].header.Get(webhook.SignatureHeader) {
+			t.Errorf("retry differs from the first attempt: %v", request.header)
+		}
+	}
+}
+
+func TestWebhookGivesUp(t *testing.T) {
+	ctx := context.Background()
+	env := newWebhookEnv(t, 2)
+	hooks := newReceiver(t, http.StatusInternalServerError, http.StatusInternalServerError, http.StatusInternalServerError, http.StatusInternalServerError)
+
+	created, err := env.webhooks.CreateSubscription(ctx, dtos.CreateWebhookRequest{URL: hooks.URL})
+	if err != nil {
+		t.Fatalf("create subscription: %v", err)
+	}
+	env.createPR(t, ctx)
+
+	for range 3 {
+		if _, err := env.webhooks.DeliverDue(ctx); err != nil {
+			t.Fatalf("deliver: %v", err)
+		}
+	}
+
+	// Without an event filter the subscription gets pr.created and the
+	// reviewer.assigned event; each is given up after two attempts.
+	deliveries := env.deliveries(t, ctx, created.Subscription.SubscriptionID)
+	if len(deliveries) != 2 {
+		t.Fatalf("got %d deliveries, want 2", len(deliveries))
+	}
+	for _, delivery := range deliveries {
+		if delivery.Status != string(domain.DeliveryStatusFailed) || delivery.Attempts != 2 ||
+			delivery.ResponseStatus != http.StatusInternalServerError || delivery.LastError == "" {
+			t.Errorf("got delivery %+v, want FAILED after two attempts", delivery)
+		}
+	}
+	if got := len(hooks.received()); got != 4 {
+		t.Errorf("receiver got %d requests, want 4", got)
+	}
+}
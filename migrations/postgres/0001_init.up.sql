@@ -1,17 +1,17 @@
-CREATE TABLE teams (
+CREATE TABLE IF NOT EXISTS teams (
     team_name TEXT PRIMARY KEY COLLATE "C",
     created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
 );
 
-CREATE TABLE users (
+CREATE TABLE IF NOT EXISTS users (
     user_id TEXT PRIMARY KEY COLLATE "C",
     username TEXT NOT NULL,
     team_name TEXT NOT NULL REFERENCES teams(team_name) ON DELETE CASCADE,
     is_active BOOLEAN NOT NULL DEFAULT TRUE
 );
-CREATE INDEX idx_users_team_name ON users(team_name);
+CREATE INDEX IF NOT EXISTS idx_users_team_name ON users(team_name);
 
-CREATE TABLE pull_requests (
+CREATE TABLE IF NOT EXISTS pull_requests (
     pull_request_id TEXT PRIMARY KEY COLLATE "C",
     pull_request_name TEXT NOT NULL,
     author_id TEXT NOT NULL REFERENCES users(user_id),
@@ -20,9 +20,9 @@ CREATE TABLE pull_requests (
     merged_at TIMESTAMPTZ
 );
 
-CREATE TABLE pull_request_reviewers (
+CREATE TABLE IF NOT EXISTS pull_request_reviewers (
     pull_request_id TEXT NOT NULL REFERENCES pull_requests(pull_request_id) ON DELETE CASCADE,
     user_id TEXT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
     PRIMARY KEY (pull_request_id, user_id)
 );
-CREATE INDEX idx_pr_reviewers_user_id ON pull_request_reviewers(user_id);
+CREATE INDEX IF NOT EXISTS idx_pr_reviewers_user_id ON pull_request_reviewers(user_id);
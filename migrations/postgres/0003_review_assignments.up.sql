@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS review_assignments (
+    id BIGSERIAL PRIMARY KEY,
+    pull_request_id TEXT NOT NULL REFERENCES pull_requests(pull_request_id) ON DELETE CASCADE,
+    author_id TEXT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    reviewer_id TEXT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    assigned_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_review_assignments_author_reviewer ON review_assignments(author_id, reviewer_id, assigned_at);
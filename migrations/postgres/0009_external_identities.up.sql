@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS external_identities (
+    provider TEXT NOT NULL,
+    login TEXT NOT NULL,
+    user_id TEXT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (provider, login)
+);
+CREATE INDEX IF NOT EXISTS idx_external_identities_user_id ON external_identities(user_id);
@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS notification_preferences (
+    user_id TEXT PRIMARY KEY REFERENCES users(user_id) ON DELETE CASCADE,
+    chat_enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    chat_channel TEXT NOT NULL DEFAULT '',
+    chat_events TEXT NOT NULL DEFAULT '',
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,6 @@
+ALTER TABLE notification_preferences
+    DROP COLUMN IF EXISTS email,
+    DROP COLUMN IF EXISTS digest_enabled,
+    DROP COLUMN IF EXISTS digest_hour,
+    DROP COLUMN IF EXISTS timezone,
+    DROP COLUMN IF EXISTS digest_sent_at;
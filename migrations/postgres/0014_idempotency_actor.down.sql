@@ -0,0 +1,4 @@
+DELETE FROM idempotency_keys WHERE actor_id <> '';
+ALTER TABLE idempotency_keys DROP CONSTRAINT IF EXISTS idempotency_keys_pkey;
+ALTER TABLE idempotency_keys ADD PRIMARY KEY (idempotency_key, route);
+ALTER TABLE idempotency_keys DROP COLUMN IF EXISTS actor_id;
@@ -0,0 +1 @@
+ALTER TABLE review_assignments ADD COLUMN IF NOT EXISTS escalated BOOLEAN NOT NULL DEFAULT FALSE;
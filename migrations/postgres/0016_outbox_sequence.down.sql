@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_outbox_events_pending;
+DROP INDEX IF EXISTS idx_outbox_events_sequence;
+ALTER TABLE outbox_events DROP COLUMN IF EXISTS delivered_sinks;
+ALTER TABLE outbox_events DROP COLUMN IF EXISTS sequence;
+CREATE INDEX IF NOT EXISTS idx_outbox_events_pending ON outbox_events(next_attempt_at) WHERE status = 'PENDING';
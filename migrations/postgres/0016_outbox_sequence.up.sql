@@ -0,0 +1,13 @@
+CREATE SEQUENCE IF NOT EXISTS outbox_events_sequence_seq;
+ALTER TABLE outbox_events ADD COLUMN IF NOT EXISTS sequence BIGINT;
+ALTER TABLE outbox_events ADD COLUMN IF NOT EXISTS delivered_sinks TEXT NOT NULL DEFAULT '';
+UPDATE outbox_events SET sequence = numbered.sequence
+FROM (SELECT event_id, nextval('outbox_events_sequence_seq') AS sequence
+      FROM (SELECT event_id FROM outbox_events WHERE sequence IS NULL ORDER BY occurred_at, event_id) ordered) numbered
+WHERE outbox_events.event_id = numbered.event_id;
+ALTER TABLE outbox_events ALTER COLUMN sequence SET DEFAULT nextval('outbox_events_sequence_seq');
+ALTER TABLE outbox_events ALTER COLUMN sequence SET NOT NULL;
+ALTER SEQUENCE outbox_events_sequence_seq OWNED BY outbox_events.sequence;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_outbox_events_sequence ON outbox_events(sequence);
+DROP INDEX IF EXISTS idx_outbox_events_pending;
+CREATE INDEX idx_outbox_events_pending ON outbox_events(sequence) WHERE status = 'PENDING';
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS idempotency_keys;
+DROP TABLE IF EXISTS review_assignments;
+DROP TABLE IF EXISTS pull_request_reviewers;
+DROP TABLE IF EXISTS pull_requests;
+DROP TABLE IF EXISTS users;
+DROP TABLE IF EXISTS teams;
@@ -0,0 +1,5 @@
+ALTER TABLE notification_preferences DROP COLUMN digest_sent_at;
+ALTER TABLE notification_preferences DROP COLUMN timezone;
+ALTER TABLE notification_preferences DROP COLUMN digest_hour;
+ALTER TABLE notification_preferences DROP COLUMN digest_enabled;
+ALTER TABLE notification_preferences DROP COLUMN email;
@@ -0,0 +1,5 @@
+ALTER TABLE notification_preferences ADD COLUMN email TEXT NOT NULL DEFAULT '';
+ALTER TABLE notification_preferences ADD COLUMN digest_enabled BOOLEAN NOT NULL DEFAULT TRUE;
+ALTER TABLE notification_preferences ADD COLUMN digest_hour INTEGER NOT NULL DEFAULT 9 CHECK (digest_hour BETWEEN 0 AND 23);
+ALTER TABLE notification_preferences ADD COLUMN timezone TEXT NOT NULL DEFAULT 'UTC';
+ALTER TABLE notification_preferences ADD COLUMN digest_sent_at TIMESTAMP;
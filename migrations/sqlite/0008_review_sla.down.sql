@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_pull_requests_open_created;
+DROP TABLE IF EXISTS pull_request_sla;
+DROP TABLE IF EXISTS team_review_slas;
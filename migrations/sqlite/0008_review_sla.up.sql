@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS team_review_slas (
+    team_name TEXT PRIMARY KEY REFERENCES teams(team_name) ON DELETE CASCADE,
+    remind_after_seconds INTEGER NOT NULL CHECK (remind_after_seconds >= 0),
+    escalate_after_seconds INTEGER NOT NULL CHECK (escalate_after_seconds >= 0),
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS pull_request_sla (
+    pull_request_id TEXT PRIMARY KEY REFERENCES pull_requests(pull_request_id) ON DELETE CASCADE,
+    reminded_at TIMESTAMP,
+    escalated_at TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_pull_requests_open_created ON pull_requests(created_at) WHERE status = 'OPEN';
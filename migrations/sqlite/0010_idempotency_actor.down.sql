@@ -0,0 +1,16 @@
+CREATE TABLE idempotency_keys_old (
+    idempotency_key TEXT NOT NULL,
+    route TEXT NOT NULL,
+    request_hash TEXT NOT NULL,
+    status_code INTEGER,
+    response_headers TEXT,
+    response_body BLOB,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (idempotency_key, route)
+);
+INSERT INTO idempotency_keys_old (idempotency_key, route, request_hash, status_code, response_headers, response_body, created_at, expires_at)
+SELECT idempotency_key, route, request_hash, status_code, response_headers, response_body, created_at, expires_at FROM idempotency_keys WHERE actor_id = '';
+DROP TABLE idempotency_keys;
+ALTER TABLE idempotency_keys_old RENAME TO idempotency_keys;
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_expires_at ON idempotency_keys(expires_at);
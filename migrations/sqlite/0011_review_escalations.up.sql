@@ -0,0 +1 @@
+ALTER TABLE review_assignments ADD COLUMN escalated BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,16 @@
+CREATE TABLE outbox_events_old (
+    event_id TEXT PRIMARY KEY,
+    event_type TEXT NOT NULL,
+    payload BLOB NOT NULL,
+    occurred_at TIMESTAMP NOT NULL,
+    status TEXT NOT NULL CHECK (status IN ('PENDING','PROCESSED','FAILED')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP NOT NULL,
+    last_error TEXT NOT NULL DEFAULT '',
+    processed_at TIMESTAMP
+);
+INSERT INTO outbox_events_old (event_id, event_type, payload, occurred_at, status, attempts, next_attempt_at, last_error, processed_at)
+SELECT event_id, event_type, payload, occurred_at, status, attempts, next_attempt_at, last_error, processed_at FROM outbox_events;
+DROP TABLE outbox_events;
+ALTER TABLE outbox_events_old RENAME TO outbox_events;
+CREATE INDEX IF NOT EXISTS idx_outbox_events_pending ON outbox_events(next_attempt_at) WHERE status = 'PENDING';
@@ -0,0 +1,18 @@
+CREATE TABLE outbox_events_new (
+    sequence INTEGER PRIMARY KEY AUTOINCREMENT,
+    event_id TEXT NOT NULL UNIQUE,
+    event_type TEXT NOT NULL,
+    payload BLOB NOT NULL,
+    occurred_at TIMESTAMP NOT NULL,
+    status TEXT NOT NULL CHECK (status IN ('PENDING','PROCESSED','FAILED')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP NOT NULL,
+    last_error TEXT NOT NULL DEFAULT '',
+    processed_at TIMESTAMP,
+    delivered_sinks TEXT NOT NULL DEFAULT ''
+);
+INSERT INTO outbox_events_new (event_id, event_type, payload, occurred_at, status, attempts, next_attempt_at, last_error, processed_at)
+SELECT event_id, event_type, payload, occurred_at, status, attempts, next_attempt_at, last_error, processed_at FROM outbox_events ORDER BY occurred_at, event_id;
+DROP TABLE outbox_events;
+ALTER TABLE outbox_events_new RENAME TO outbox_events;
+CREATE INDEX IF NOT EXISTS idx_outbox_events_pending ON outbox_events(sequence) WHERE status = 'PENDING';